@@ -0,0 +1,141 @@
+package controllers
+
+import (
+	"auth/initializers"
+	"auth/models"
+	"auth/utils"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+)
+
+// FetchAnalytics retrieves the readership of the articles of the current user.
+// The "days" query parameter sets the number of days up to today (30 by default), and "id" selects a single article.
+// The readers of an article over several days are the sum of its daily unique readers.
+func FetchAnalytics(c *gin.Context) {
+	defer func() {
+		if err := recover(); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err, "request_id": c.GetString(initializers.RequestIDKey)})
+		}
+	}()
+
+	// Get the user off the context
+	user, _ := c.Get("user")
+	userID := user.(models.User).ID
+
+	// Get the number of days and the optional article ID off the query string
+	count, err := strconv.Atoi(c.DefaultQuery("days", "30"))
+	if err != nil || count < 1 || count > 90 {
+		panic("Invalid days: Must be an integer between 1 and 90")
+	}
+	query := initializers.DB.Model(&models.Article{}).Where("author_id = ?", userID)
+	if id, ok := c.GetQuery("id"); ok {
+		query = query.Where("id = ?", utils.StrToUint(id))
+	}
+
+	// Get the articles of the user from the database
+	var articles []map[string]interface{}
+	result := query.Select("id", "title", "status", "likes", "dislikes", "created_at").Order("created_at DESC").Find(&articles)
+	if result.Error != nil {
+		panic("Failed to get the articles from the database")
+	}
+	if _, ok := c.GetQuery("id"); ok && len(articles) == 0 {
+		panic("Failed to find the article")
+	}
+	articleIDs := make([]uint, 0, len(articles))
+	for _, article := range articles {
+		articleIDs = append(articleIDs, article["id"].(uint))
+	}
+
+	// Prepare the days of the window
+	year, month, day := time.Now().Date()
+	today := time.Date(year, month, day, 0, 0, 0, 0, time.Local)
+	from, to := today.AddDate(0, 0, 1-count), today.AddDate(0, 0, 1)
+	trend := make(map[string]map[string]int64, count)
+	for day := from; day.Before(to); day = day.AddDate(0, 0, 1) {
+		trend[day.Format(time.DateOnly)] = map[string]int64{"views": 0, "readers": 0, "likes": 0, "comments": 0}
+	}
+	totals := make(map[uint]map[string]int64, len(articles))
+	for _, articleID := range articleIDs {
+		totals[articleID] = map[string]int64{"views": 0, "readers": 0, "comments": 0}
+	}
+
+	if len(articleIDs) > 0 {
+		// Get the flushed views of the past days from MySQL, and today's views off the Redis counters
+		var stats []models.ArticleViewStat
+		initializers.DB.Where("article_id IN (?) AND day >= ? AND day < ?", articleIDs, from, today).Find(&stats)
+		for articleID, views := range utils.GetLiveArticleViews(articleIDs, today) {
+			stats = append(stats, models.ArticleViewStat{ArticleID: articleID, Day: today, Views: views.Views, Readers: views.Readers})
+		}
+		for _, stat := range stats {
+			if counts, ok := trend[stat.Day.Format(time.DateOnly)]; ok {
+				counts["views"] += stat.Views
+				counts["readers"] += stat.Readers
+			}
+			totals[stat.ArticleID]["views"] += stat.Views
+			totals[stat.ArticleID]["readers"] += stat.Readers
+		}
+
+		// Get the approved comments by day from MySQL
+		for dayKey, n := range countByDay(initializers.DB.Model(&models.Comment{}).
+			Where("article_id IN (?) AND status = ?", articleIDs, models.Approved), from, to) {
+			trend[dayKey]["comments"] += n
+		}
+		var comments []struct {
+			ArticleID uint
+			Count     int64
+		}
+		initializers.DB.Model(&models.Comment{}).
+			Select("article_id", "COUNT(*) AS count").
+			Where("article_id IN (?) AND status = ? AND created_at >= ?", articleIDs, models.Approved, from).
+			Group("article_id").Scan(&comments)
+		for _, comment := range comments {
+			totals[comment.ArticleID]["comments"] = comment.Count
+		}
+
+		// Get the likes by day off the Redis Sorted Sets, whose scores are the times of the likes
+		for _, articleID := range articleIDs {
+			key := utils.RedisConstants.ARTICLE_LIKED_KEY_PREFIX + strconv.Itoa(int(articleID))
+			likes, _ := initializers.RDB.ZRangeByScoreWithScores(initializers.RDB_CTX, key, &redis.ZRangeBy{
+				Min: strconv.FormatInt(from.Unix(), 10),
+				Max: "+inf",
+			}).Result()
+			for _, like := range likes {
+				dayKey := time.Unix(int64(like.Score), 0).Format(time.DateOnly)
+				if counts, ok := trend[dayKey]; ok {
+					counts["likes"]++
+				}
+			}
+		}
+	}
+
+	// Map the totals of the window to the articles
+	for _, article := range articles {
+		for name, total := range totals[article["id"].(uint)] {
+			article[name] = total
+		}
+	}
+
+	// Order the trend by day
+	days := make([]map[string]interface{}, 0, count)
+	for day := from; day.Before(to); day = day.AddDate(0, 0, 1) {
+		dayKey := day.Format(time.DateOnly)
+		point := map[string]interface{}{"day": dayKey}
+		for name, n := range trend[dayKey] {
+			point[name] = n
+		}
+		days = append(days, point)
+	}
+
+	// Return a success response with the analytics
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Analytics retrieved successfully",
+		"from":     from.Format(time.DateOnly),
+		"to":       today.Format(time.DateOnly),
+		"articles": articles,
+		"trend":    days,
+	})
+}
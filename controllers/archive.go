@@ -0,0 +1,455 @@
+package controllers
+
+import (
+	"archive/zip"
+	"auth/initializers"
+	"auth/models"
+	"auth/utils"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Status of an import job.
+const (
+	ImportQueued  = "queued"
+	ImportParsing = "parsing"
+	ImportRunning = "running"
+	ImportDone    = "done"
+	ImportFailed  = "failed"
+)
+
+// maxImportErrors is the number of failed articles reported by an import job.
+const maxImportErrors = 100
+
+// statusNames are the names of the article and comment statuses in the exports.
+var statusNames = map[uint]string{
+	models.Pending:   "pending",
+	models.Approved:  "approved",
+	models.Rejected:  "rejected",
+	models.Draft:     "draft",
+	models.Scheduled: "scheduled",
+}
+
+// ExportArticles exports the articles and comments of the current user as a zip of Markdown files with YAML front matter:
+// the articles under "articles/" and the comments under "comments/". The articles can be imported back (see ImportArticles).
+func ExportArticles(c *gin.Context) {
+	defer func() {
+		if err := recover(); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err, "request_id": c.GetString(initializers.RequestIDKey)})
+		}
+	}()
+
+	// Get the user off the context
+	user, _ := c.Get("user")
+	userID := user.(models.User).ID
+
+	// Get the articles of the user from the database, with their tags and categories
+	var articles []models.Article
+	result := initializers.DB.Where("author_id = ?", userID).Order("id").Find(&articles)
+	if result.Error != nil {
+		panic("Failed to get the articles from the database")
+	}
+	articleIDs := make([]uint, 0, len(articles))
+	for _, article := range articles {
+		articleIDs = append(articleIDs, article.ID)
+	}
+	tags := getArticleTags(articleIDs)
+	var categories []models.Category
+	initializers.DB.Select("id", "name").Find(&categories)
+	categoryNames := make(map[uint]string, len(categories))
+	for _, category := range categories {
+		categoryNames[category.ID] = category.Name
+	}
+
+	// Get the comments of the user from the database
+	// Use JOIN to get the title of the article
+	var comments []struct {
+		models.Comment
+		ArticleTitle string
+	}
+	result = initializers.DB.Model(&models.Comment{}).
+		Joins("JOIN articles ON comments.article_id = articles.id").
+		Select("comments.*", "articles.title as article_title").
+		Where("comments.author_id = ?", userID).
+		Order("comments.id").
+		Find(&comments)
+	if result.Error != nil {
+		panic("Failed to get the comments from the database")
+	}
+
+	// Write the Markdown files into the archive
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	for _, article := range articles {
+		frontMatter := utils.ArticleFrontMatter{
+			Title:     article.Title,
+			Status:    statusNames[article.Status],
+			Tags:      tags[article.ID],
+			Free:      article.Free,
+			Unlisted:  article.Unlisted,
+			PublishAt: article.PublishAt,
+			CreatedAt: article.CreatedAt,
+			UpdatedAt: article.UpdatedAt,
+		}
+		name := "articles/article-" + strconv.Itoa(int(article.ID)) + ".md"
+		if article.Slug != nil {
+			frontMatter.Slug = *article.Slug
+			name = "articles/" + *article.Slug + ".md"
+		}
+		if article.CategoryID != nil {
+			frontMatter.Category = categoryNames[*article.CategoryID]
+		}
+		if err := writeArchiveFile(archive, name, frontMatter, article.Body, article.UpdatedAt); err != nil {
+			panic("Failed to export the article " + strconv.Itoa(int(article.ID)))
+		}
+	}
+	for _, comment := range comments {
+		frontMatter := utils.CommentFrontMatter{
+			ID:        comment.ID,
+			ArticleID: comment.ArticleID,
+			Article:   comment.ArticleTitle,
+			ParentID:  comment.ParentID,
+			Status:    statusNames[comment.Status],
+			CreatedAt: comment.CreatedAt,
+		}
+		name := "comments/comment-" + strconv.Itoa(int(comment.ID)) + ".md"
+		if err := writeArchiveFile(archive, name, frontMatter, comment.Content, comment.UpdatedAt); err != nil {
+			panic("Failed to export the comment " + strconv.Itoa(int(comment.ID)))
+		}
+	}
+	if err := archive.Close(); err != nil {
+		panic("Failed to export the articles")
+	}
+
+	// Return the archive as a response
+	fileName := "articles-" + strconv.Itoa(int(userID)) + "-" + time.Now().Format("20060102") + ".zip"
+	c.Header("Content-Disposition", `attachment; filename="`+fileName+`"`)
+	c.Data(http.StatusOK, "application/zip", buf.Bytes())
+}
+
+// ImportArticles imports articles for the current user off a zip of Markdown files, in the format of the exports,
+// or off a WordPress WXR file. The articles are created in the Pending state by a background job,
+// whose progress is reported by FetchImportJob. A user may run one import at a time.
+func ImportArticles(c *gin.Context) {
+	defer func() {
+		if err := recover(); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err, "request_id": c.GetString(initializers.RequestIDKey)})
+			initializers.LOGGER.ErrorContext(c, "ImportArticles Failed", "error", err, "sub", utils.GetSubInfo(c))
+		}
+	}()
+
+	// Get the user off the context
+	user, _ := c.Get("user")
+	userID := user.(models.User).ID
+
+	// Get the file off the request body
+	file, err := c.FormFile("file")
+	if err != nil {
+		panic("Failed to get the file from the request")
+	}
+	if file.Size > int64(initializers.ImportMaxMB)<<20 {
+		panic("Invalid file: File size exceeds " + strconv.Itoa(initializers.ImportMaxMB) + "MB")
+	}
+
+	// Detect the format of the file off its first bytes
+	src, err := file.Open()
+	if err != nil {
+		panic("Failed to read the file")
+	}
+	head := make([]byte, 512)
+	n, err := io.ReadFull(src, head)
+	src.Close()
+	if err != nil && err != io.ErrUnexpectedEOF {
+		panic("Failed to read the file")
+	}
+	var format string
+	switch {
+	case bytes.HasPrefix(head[:n], []byte("PK\x03\x04")):
+		format = "zip"
+	case bytes.Contains(head[:n], []byte("<rss")):
+		format = "wxr"
+	default:
+		panic("Invalid file: Must be a zip of Markdown files or a WordPress WXR file")
+	}
+
+	// Lock the imports of the user until the job is over
+	lockKey := utils.RedisConstants.MUTEX_IMPORT_KEY_PREFIX + strconv.Itoa(int(userID))
+	if !utils.SimpleTryLock(lockKey, utils.RedisConstants.MUTEX_IMPORT_EXPIRE_TIME) {
+		panic("Another import is in progress, please wait for it to finish")
+	}
+
+	// Save the file for the job
+	tmp, err := os.CreateTemp("", "import-*."+format)
+	if err != nil {
+		utils.SimpleUnlock(lockKey)
+		panic("Failed to save the file")
+	}
+	tmp.Close()
+	if err := c.SaveUploadedFile(file, tmp.Name()); err != nil {
+		os.Remove(tmp.Name())
+		utils.SimpleUnlock(lockKey)
+		panic("Failed to save the file")
+	}
+
+	// Create the job and run it in the background
+	jobID := uuid.New().String()
+	jobKey := utils.RedisConstants.IMPORT_JOB_KEY_PREFIX + jobID
+	initializers.RDB.HSet(initializers.RDB_CTX, jobKey, map[string]interface{}{
+		"owner_id":   userID,
+		"format":     format,
+		"name":       filepath.Base(file.Filename),
+		"status":     ImportQueued,
+		"total":      0,
+		"processed":  0,
+		"imported":   0,
+		"failed":     0,
+		"created_at": time.Now().Format(time.RFC3339),
+	})
+	initializers.RDB.Expire(initializers.RDB_CTX, jobKey, utils.RedisConstants.IMPORT_JOB_EXPIRE_TIME)
+	ctx := initializers.WithRequestID(context.Background(), c.GetString(initializers.RequestIDKey))
+	go runImportJob(ctx, jobID, userID, format, tmp.Name(), lockKey)
+
+	// [Prepare the object and data information for logging]
+	objInfo := utils.ObjInfo{
+		Op:    utils.OpCreate,
+		Table: models.Article{}.TableName(),
+		ID:    0,
+	}
+	dataInfo := utils.DataInfo{
+		OldData: nil,
+		NewData: map[string]interface{}{
+			"job_id": jobID,
+			"format": format,
+			"name":   file.Filename,
+			"size":   file.Size,
+		},
+	}
+
+	// Return a success response with the job
+	message := "Import started successfully"
+	c.JSON(http.StatusOK, gin.H{
+		"message": message,
+		"job_id":  jobID,
+		"status":  ImportQueued,
+	})
+	utils.Audit(c, slog.LevelInfo, message, objInfo, dataInfo)
+}
+
+// FetchImportJob retrieves the progress of an import job of the current user, with the articles that failed.
+func FetchImportJob(c *gin.Context) {
+	defer func() {
+		if err := recover(); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err, "request_id": c.GetString(initializers.RequestIDKey)})
+		}
+	}()
+
+	// Get the user off the context
+	user, _ := c.Get("user")
+	userID := user.(models.User).ID
+
+	// Get the job id off the query string
+	jobID, ok := c.GetQuery("id")
+	if !ok {
+		panic("Failed to get id off the query string")
+	}
+
+	// Get the job off Redis
+	jobKey := utils.RedisConstants.IMPORT_JOB_KEY_PREFIX + jobID
+	fields, err := initializers.RDB.HGetAll(initializers.RDB_CTX, jobKey).Result()
+	if err != nil || len(fields) == 0 || fields["owner_id"] != strconv.Itoa(int(userID)) {
+		panic("Failed to find the import job")
+	}
+	failures, _ := initializers.RDB.LRange(initializers.RDB_CTX, jobKey+":errors", 0, -1).Result()
+
+	job := gin.H{
+		"id":         jobID,
+		"format":     fields["format"],
+		"name":       fields["name"],
+		"status":     fields["status"],
+		"total":      utils.StrToInt(fields["total"]),
+		"processed":  utils.StrToInt(fields["processed"]),
+		"imported":   utils.StrToInt(fields["imported"]),
+		"failed":     utils.StrToInt(fields["failed"]),
+		"errors":     failures,
+		"created_at": fields["created_at"],
+	}
+	if fields["error"] != "" {
+		job["error"] = fields["error"]
+	}
+	if fields["finished_at"] != "" {
+		job["finished_at"] = fields["finished_at"]
+	}
+
+	// Return a success response with the job
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Import job retrieved successfully",
+		"job":     job,
+	})
+}
+
+// runImportJob imports the articles of an uploaded file, reporting its progress in the job.
+// The context carries the ID of the request that uploaded the file, not the request itself.
+func runImportJob(ctx context.Context, jobID string, userID uint, format string, path string, lockKey string) {
+	jobKey := utils.RedisConstants.IMPORT_JOB_KEY_PREFIX + jobID
+	setStatus := func(status string, values ...interface{}) {
+		initializers.RDB.HSet(initializers.RDB_CTX, jobKey, append([]interface{}{"status", status}, values...)...)
+	}
+	defer func() {
+		if err := recover(); err != nil {
+			initializers.LOGGER.ErrorContext(ctx, "Import job failed", "error", err, "job_id", jobID, "user_id", userID)
+			setStatus(ImportFailed, "error", fmt.Sprint(err), "finished_at", time.Now().Format(time.RFC3339))
+		}
+		os.Remove(path)
+		utils.SimpleUnlock(lockKey)
+	}()
+
+	// Read the articles off the file
+	setStatus(ImportParsing)
+	articles, err := readImportFile(format, path)
+	if err != nil {
+		initializers.LOGGER.ErrorContext(ctx, "Import job failed", "error", err.Error(), "job_id", jobID, "user_id", userID)
+		setStatus(ImportFailed, "error", err.Error(), "finished_at", time.Now().Format(time.RFC3339))
+		return
+	}
+
+	// Create the articles one by one, reporting the failed ones
+	setStatus(ImportRunning, "total", len(articles))
+	imported := 0
+	for _, article := range articles {
+		if err := importArticle(ctx, userID, article); err != nil {
+			initializers.RDB.HIncrBy(initializers.RDB_CTX, jobKey, "failed", 1)
+			initializers.RDB.RPush(initializers.RDB_CTX, jobKey+":errors", article.Source+": "+err.Error())
+			initializers.RDB.LTrim(initializers.RDB_CTX, jobKey+":errors", 0, maxImportErrors-1)
+			initializers.RDB.Expire(initializers.RDB_CTX, jobKey+":errors", utils.RedisConstants.IMPORT_JOB_EXPIRE_TIME)
+		} else {
+			initializers.RDB.HIncrBy(initializers.RDB_CTX, jobKey, "imported", 1)
+			imported++
+		}
+		initializers.RDB.HIncrBy(initializers.RDB_CTX, jobKey, "processed", 1)
+	}
+
+	setStatus(ImportDone, "finished_at", time.Now().Format(time.RFC3339))
+	initializers.RDB.Expire(initializers.RDB_CTX, jobKey, utils.RedisConstants.IMPORT_JOB_EXPIRE_TIME)
+	initializers.LOGGER.InfoContext(ctx, "Articles imported", "job_id", jobID, "user_id", userID, "total", len(articles), "imported", imported)
+}
+
+// readImportFile reads the articles off an uploaded file in the format ("zip" or "wxr").
+func readImportFile(format string, path string) ([]utils.ImportedArticle, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, errors.New("failed to read the file")
+	}
+	defer f.Close()
+
+	if format == "wxr" {
+		return utils.ReadWXR(f)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		return nil, errors.New("failed to read the file")
+	}
+	return utils.ReadMarkdownArchive(f, info.Size())
+}
+
+// importArticle creates an imported article of a user in the Pending state, waiting for moderation.
+// Unlike the posted articles, the imported ones earn no credits.
+func importArticle(ctx context.Context, userID uint, imported utils.ImportedArticle) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+
+	if imported.Error != "" {
+		return errors.New(imported.Error)
+	}
+	if strings.TrimSpace(imported.Title) == "" || strings.TrimSpace(imported.Body) == "" {
+		return errors.New("both title and body are required")
+	}
+	tagNames := normalizeTags(imported.Tags)
+
+	// Get the category by its name, if it exists on this site
+	var categoryID *uint
+	if imported.Category != "" {
+		var category models.Category
+		result := initializers.DB.Select("id").Where("name = ?", imported.Category).Limit(1).Find(&category)
+		if result.RowsAffected > 0 {
+			categoryID = &category.ID
+		}
+	}
+
+	// Prepare the article object
+	article := models.Article{
+		Title:      imported.Title,
+		Body:       imported.Body,
+		AuthorID:   userID,
+		Status:     models.Pending,
+		PublishAt:  imported.PublishAt,
+		Unlisted:   imported.Unlisted,
+		Free:       imported.Free,
+		CategoryID: categoryID,
+	}
+
+	// Render the Markdown body into HTML
+	if err := renderArticle(&article); err != nil {
+		return err
+	}
+
+	// Start a transaction to ensure atomicity
+	err = initializers.DB.Transaction(func(tx *gorm.DB) error {
+		// Attach the tags to the article, creating the new ones
+		tags, err := findOrCreateTags(tx, tagNames)
+		if err != nil {
+			return err
+		}
+		article.Tags = tags
+
+		// Create the article in the database
+		if err := tx.Create(&article).Error; err != nil {
+			return errors.New("failed to create the article")
+		}
+
+		// Give the article the unique slug of its title
+		if err := assignSlug(tx, &article); err != nil {
+			return err
+		}
+
+		// Keep the first version of the article
+		return saveArticleRevision(tx, &article, userID)
+	})
+	if err != nil {
+		return err
+	}
+
+	// Update the search index
+	utils.IndexArticle(ctx, article.ID)
+	return nil
+}
+
+// writeArchiveFile writes a Markdown file with its YAML front matter into an archive.
+func writeArchiveFile(archive *zip.Writer, name string, frontMatter interface{}, body string, modified time.Time) error {
+	content, err := utils.MarshalMarkdown(frontMatter, body)
+	if err != nil {
+		return err
+	}
+	w, err := archive.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: modified})
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(content)
+	return err
+}
@@ -4,7 +4,10 @@ import (
 	"auth/initializers"
 	"auth/models"
 	"auth/utils"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"log/slog"
 	"net/http"
 	"strconv"
 	"time"
@@ -12,22 +15,31 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/redis/go-redis/v9"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // FetchArticles retrieves all articles.
+// The articles can be filtered by tag, category, author and date range (see filterArticles).
+// Readers get previews of the premium articles of the authors they have not subscribed to (see gateArticles).
 func FetchArticles(c *gin.Context) {
 	defer func() {
 		if err := recover(); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err})
+			c.JSON(http.StatusBadRequest, gin.H{"error": err, "request_id": c.GetString(initializers.RequestIDKey)})
 		}
 	}()
 
+	// Get the format of the article bodies
+	format := getArticleFormat(c)
+
 	// Get the pagination parameters
 	params := utils.GetPaginationParams(c)
 
 	// Get the total number of articles
+	// Unlisted articles are excluded
 	var total int64
-	result := initializers.DB.Model(&models.Article{}).Where("status = ?", models.Approved).Count(&total)
+	result := filterArticles(c, initializers.DB.Model(&models.Article{})).
+		Where("articles.status = ? AND articles.unlisted = ?", models.Approved, false).
+		Count(&total)
 	if result.Error != nil {
 		panic("Failed to get the total number of articles")
 	}
@@ -35,10 +47,10 @@ func FetchArticles(c *gin.Context) {
 	// Get the articles from the database
 	// Use JOIN to get the author's email
 	var articles []map[string]interface{}
-	result = initializers.DB.Model(&models.Article{}).
+	result = filterArticles(c, initializers.DB.Model(&models.Article{})).
 		Joins("JOIN users ON articles.author_id = users.id").
-		Select("articles.id as id", "articles.title as title", "articles.body as body", "users.email as author").
-		Where("articles.status = ?", models.Approved).
+		Select("articles.id as id", "articles.author_id as author_id", "articles.title as title", "articles.slug as slug", "articles.body as body", "articles.body_html as body_html", "articles.toc as toc", "articles.free as free", "articles.category_id as category_id", "users.email as author").
+		Where("articles.status = ? AND articles.unlisted = ?", models.Approved, false).
 		Order("articles.created_at DESC").
 		Offset(params.Offset).Limit(params.PageSize).Find(&articles)
 	if result.Error != nil {
@@ -52,6 +64,26 @@ func FetchArticles(c *gin.Context) {
 		}
 	}
 
+	// Replace the premium articles by their previews, then set the bodies in the requested format
+	user, _ := c.Get("user")
+	gateArticles(user.(models.User).ID, articles)
+	formatArticles(format, articles)
+
+	// Map the tags and the series navigation to the articles
+	var articleIDs []uint
+	for _, article := range articles {
+		articleIDs = append(articleIDs, article["id"].(uint))
+	}
+	tags := getArticleTags(articleIDs)
+	navigations := getSeriesNavigations(user.(models.User).ID, articleIDs)
+	for i := range articles {
+		articleID := articles[i]["id"].(uint)
+		articles[i]["tags"] = tags[articleID]
+		if series, ok := navigations[articleID]; ok {
+			articles[i]["series"] = series
+		}
+	}
+
 	// Get the pagination result
 	pagination := utils.GetPaginationResult(params, len(articles), total)
 
@@ -127,10 +159,11 @@ func FetchArticles(c *gin.Context) {
 // }
 
 // FetchUserArticles retrieves all articles of a user.
+// Readers who have not subscribed to the user get previews of the premium articles (see gateArticles).
 func FetchUserArticles(c *gin.Context) {
 	defer func() {
 		if err := recover(); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err})
+			c.JSON(http.StatusBadRequest, gin.H{"error": err, "request_id": c.GetString(initializers.RequestIDKey)})
 		}
 	}()
 
@@ -148,12 +181,14 @@ func FetchUserArticles(c *gin.Context) {
 		panic("Invalid ID: Type error")
 	}
 
-	// First check if the user is allowed to view the articles
-	// (1) Check if the user has subscribed to the author
-	// (2) Check if the user is the author himself/herself
-	result := initializers.DB.Where("reader_id = ? AND author_id = ?", curUserID, userID).First(&models.Subscribe{})
-	if result.RowsAffected == 0 && curUserID != uint(userID) {
-		panic("Not allowed to view the user's articles")
+	// Get the format of the article bodies
+	format := getArticleFormat(c)
+
+	// Get the status filter off the query string
+	// Only the author can view the articles that are not approved (e.g. drafts)
+	status := uint(models.Approved)
+	if s, ok := c.GetQuery("status"); ok && curUserID == uint(userID) {
+		status = parseArticleStatus(s)
 	}
 
 	// Get the pagination parameters
@@ -161,41 +196,42 @@ func FetchUserArticles(c *gin.Context) {
 
 	// Get the total number of articles of the user
 	var total int64
-	result = initializers.DB.Model(&models.Article{}).Where("author_id = ? AND status = ?", uint(userID), models.Approved).Count(&total)
+	result := filterArticles(c, initializers.DB.Model(&models.Article{})).
+		Where("articles.author_id = ? AND articles.status = ?", uint(userID), status).
+		Count(&total)
 	if result.Error != nil {
 		panic("Failed to get the total number of articles")
 	}
 
 	// Get the articles from the database
 	var articles []map[string]interface{}
-	result = initializers.DB.Model(&models.Article{}).
-		Select("id", "title", "body", "likes", "dislikes").
-		Where("author_id = ? AND status = ?", uint(userID), models.Approved).
+	result = filterArticles(c, initializers.DB.Model(&models.Article{})).
+		Select("id", "author_id", "title", "slug", "body", "body_html", "toc", "likes", "dislikes", "status", "publish_at", "unlisted", "free", "category_id").
+		Where("articles.author_id = ? AND articles.status = ?", uint(userID), status).
 		Order("created_at DESC").
 		Offset(params.Offset).Limit(params.PageSize).Find(&articles)
 	if result.Error != nil {
 		panic("Failed to get the articles from the database")
 	}
 
+	// Replace the premium articles by their previews, then set the bodies in the requested format
+	gateArticles(curUserID, articles)
+	formatArticles(format, articles)
+
 	// For each article
 	// (1) we need to check if the current user has liked or disliked it
 	// (2) we need to generate a top5 leaderboard for both likes and dislikes
+	// (3) we need to record the view of the other readers, once it is read in full
 	for i := range articles {
 		// check if the current user has liked or disliked the article
-		articleID := articles[i]["id"].(uint)
-		key_liked := utils.RedisConstants.ARTICLE_LIKED_KEY_PREFIX + strconv.Itoa(int(articleID))
-		key_disliked := utils.RedisConstants.ARTICLE_DISLIKED_KEY_PREFIX + strconv.Itoa(int(articleID))
-		liked := initializers.RDB.ZScore(initializers.RDB_CTX, key_liked, strconv.Itoa(int(curUserID))).Err() == nil
-		disliked := initializers.RDB.ZScore(initializers.RDB_CTX, key_disliked, strconv.Itoa(int(curUserID))).Err() == nil
-		articles[i]["liked"] = liked
-		articles[i]["disliked"] = disliked
+		// and generate a top5 leaderboard for likes and dislikes
+		setLikeState(c, curUserID, articles[i])
 
-		// generate a top5 leaderboard for likes and dislikes
-		var top5_likes, top5_dislikes []uint
-		initializers.RDB.ZRange(initializers.RDB_CTX, key_liked, 0, 4).ScanSlice(&top5_likes)
-		initializers.RDB.ZRange(initializers.RDB_CTX, key_disliked, 0, 4).ScanSlice(&top5_dislikes)
-		articles[i]["top5_likes"] = top5_likes
-		articles[i]["top5_dislikes"] = top5_dislikes
+		// record the view of the article
+		articleID := articles[i]["id"].(uint)
+		if curUserID != uint(userID) && status == models.Approved && !articles[i]["locked"].(bool) {
+			utils.RecordArticleView(c, articleID, curUserID)
+		}
 	}
 
 	// We need to avoid K+1 select problem
@@ -208,9 +244,9 @@ func FetchUserArticles(c *gin.Context) {
 	var comments []map[string]interface{}
 	result = initializers.DB.Model(&models.Comment{}).
 		Joins("JOIN users ON comments.author_id = users.id").
-		Select("comments.id as id", "comments.content as content", "users.email as author", "comments.article_id as article_id").
+		Select("comments.id as id", "comments.content as content", "users.email as author", "comments.article_id as article_id", "comments.parent_id as parent_id").
 		Where("comments.article_id IN (?) AND comments.status = ?", articleIDs, models.Approved).
-		Order("comments.created_at DESC").
+		Order("comments.created_at ASC").
 		Find(&comments)
 	if result.Error != nil {
 		panic("Failed to get the comments from the database")
@@ -227,10 +263,19 @@ func FetchUserArticles(c *gin.Context) {
 		articleID := comment["article_id"].(uint)
 		commentsByArticleID[articleID] = append(commentsByArticleID[articleID], comment)
 	}
-	// Map the comments to the articles
+	// Map the comment threads, the tags and the series navigation to the articles
+	// The comments of the previews are gated along with the bodies
+	tags := getArticleTags(articleIDs)
+	navigations := getSeriesNavigations(curUserID, articleIDs)
 	for i := range articles {
 		articleID := articles[i]["id"].(uint)
-		articles[i]["comments"] = commentsByArticleID[articleID]
+		if !articles[i]["locked"].(bool) {
+			articles[i]["comments"] = buildCommentTree(commentsByArticleID[articleID])
+		}
+		articles[i]["tags"] = tags[articleID]
+		if series, ok := navigations[articleID]; ok {
+			articles[i]["series"] = series
+		}
 	}
 
 	// Get the pagination result
@@ -244,12 +289,16 @@ func FetchUserArticles(c *gin.Context) {
 	})
 }
 
+// postReward is the number of credits earned by posting an article, shared with its co-authors (see inviteCoauthor).
+const postReward = 10
+
 // PostArticle posts an article of the current user.
+// The co-authors are invited with their shares of the post reward, and have to accept the invitations.
 func PostArticle(c *gin.Context) {
 	defer func() {
 		if err := recover(); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err})
-			initializers.LOGGER.Error("PostArticle Failed", "error", err, "sub", utils.GetSubInfo(c), "params", c.MustGet("params"))
+			c.JSON(http.StatusBadRequest, gin.H{"error": err, "request_id": c.GetString(initializers.RequestIDKey)})
+			initializers.LOGGER.ErrorContext(c, "PostArticle Failed", "error", err, "sub", utils.GetSubInfo(c), "params", c.MustGet("params"))
 		}
 	}()
 
@@ -270,43 +319,112 @@ func PostArticle(c *gin.Context) {
 		panic("Failed to post article")
 	}
 
-	// Check if the user is an admin
-	var status uint
-	if ok, _ := initializers.E.HasGroupingPolicy(email, "admin"); ok {
-		status = models.Approved
-	} else {
-		status = models.Pending
-	}
-
 	// Get the title and body off the request
+	// (1) Drafts stay private to the author until they are submitted
+	// (2) The publish time schedules the article to go live once approved
+	// (3) Unlisted articles are excluded from the public listing
+	// (4) Free articles can be read without subscribing, the others are premium
 	var body struct {
-		Title string `json:"title" binding:"required"`
-		Body  string `json:"body" binding:"required"`
+		Title      string     `json:"title" binding:"required"`
+		Body       string     `json:"body" binding:"required"`
+		Draft      bool       `json:"draft"`
+		PublishAt  *time.Time `json:"publish_at"`
+		Unlisted   bool       `json:"unlisted"`
+		Free       bool       `json:"free"`
+		Tags       []string   `json:"tags"`
+		CategoryID *uint      `json:"category_id"`
+		Coauthors  []struct {
+			Email string `json:"email"`
+			Share uint   `json:"share"`
+		} `json:"coauthors"`
 	}
 	if err := c.ShouldBindJSON(&body); err != nil {
 		panic("Both title and body are required")
 	}
+	tagNames := normalizeTags(body.Tags)
+
+	// Check if the category exists
+	if body.CategoryID != nil {
+		result = initializers.DB.First(&models.Category{}, *body.CategoryID)
+		if result.Error != nil {
+			panic("Failed to find the category")
+		}
+	}
+
+	// Check if the article is a draft or the user is an admin, otherwise run the moderation rules
+	var status uint
+	var verdict *utils.ModerationResult
+	moderation := utils.ModerationInput{TargetType: models.ReportArticle, AuthorID: userID, Text: body.Title + "\n" + body.Body}
+	if body.Draft {
+		status = models.Draft
+	} else if ok, _ := initializers.E.HasGroupingPolicy(email, "admin"); ok {
+		status = approvedStatus(body.PublishAt)
+	} else {
+		result := utils.Moderate(moderation)
+		verdict = &result
+		status = moderatedArticleStatus(result.Verdict, body.PublishAt)
+	}
 
 	// Prepare the article object
 	article := models.Article{
-		Title:    body.Title,
-		Body:     body.Body,
-		AuthorID: userID,
-		Status:   status,
+		Title:      body.Title,
+		Body:       body.Body,
+		AuthorID:   userID,
+		Status:     status,
+		PublishAt:  body.PublishAt,
+		Unlisted:   body.Unlisted,
+		Free:       body.Free,
+		CategoryID: body.CategoryID,
+	}
+
+	// Render the Markdown body into HTML
+	if err := renderArticle(&article); err != nil {
+		panic(err.Error())
 	}
 
 	// Start a transaction to ensure atomicity
 	err := initializers.DB.Transaction(func(tx *gorm.DB) error {
+		// Attach the tags to the article, creating the new ones
+		tags, err := findOrCreateTags(tx, tagNames)
+		if err != nil {
+			return err
+		}
+		article.Tags = tags
+
 		// Create the article in the database
 		result = tx.Create(&article)
 		if result.Error != nil {
 			return errors.New("failed to post article")
 		}
 
-		// Add 10 credits to the user
-		result = tx.Model(&models.User{}).Where("id = ?", userID).Update("credits", gorm.Expr("credits + 10"))
-		if result.Error != nil {
-			return errors.New("failed to add credits to the user")
+		// Give the article the unique slug of its title
+		if err := assignSlug(tx, &article); err != nil {
+			return err
+		}
+
+		// Bind the uploaded attachments the article links
+		if err := bindAttachments(tx, &article); err != nil {
+			return err
+		}
+
+		// Keep the first version of the article
+		if err := saveArticleRevision(tx, &article, userID); err != nil {
+			return err
+		}
+
+		// Add the post reward to the user, unless the article is rejected by the moderation rules
+		if article.Status != models.Rejected {
+			result = tx.Model(&models.User{}).Where("id = ?", userID).Update("credits", gorm.Expr("credits + ?", postReward))
+			if result.Error != nil {
+				return errors.New("failed to add credits to the user")
+			}
+		}
+
+		// Invite the co-authors, holding their shares of the reward
+		for _, coauthor := range body.Coauthors {
+			if _, err := inviteCoauthor(tx, article, coauthor.Email, coauthor.Share); err != nil {
+				return err
+			}
 		}
 
 		return nil
@@ -315,6 +433,19 @@ func PostArticle(c *gin.Context) {
 		panic(err.Error())
 	}
 
+	// Count the credits for the statistics
+	if article.Status != models.Rejected {
+		utils.IncrStat(utils.StatCreditsMoved, postReward)
+	}
+
+	// Record the verdict of the moderation rules
+	if verdict != nil {
+		utils.RecordVerdict(c, moderation, article.ID, *verdict)
+	}
+
+	// Update the search index
+	utils.IndexArticle(c, article.ID)
+
 	// [Prepare the object and data information for logging]
 	objInfo := utils.ObjInfo{
 		Op:    utils.OpCreate,
@@ -324,25 +455,207 @@ func PostArticle(c *gin.Context) {
 	dataInfo := utils.DataInfo{
 		OldData: nil,
 		NewData: map[string]interface{}{
-			"title": article.Title,
-			"body":  article.Body,
+			"title":       article.Title,
+			"body":        article.Body,
+			"tags":        tagNames,
+			"category_id": article.CategoryID,
+			"status":      article.Status,
+			"coauthors":   body.Coauthors,
 		},
 	}
 
-	// Return a success response
+	// Return a success response with the status of the article
 	message := "Article posted successfully"
 	c.JSON(http.StatusOK, gin.H{
 		"message": message,
+		"id":      article.ID,
+		"slug":    article.Slug,
+		"status":  article.Status,
+	})
+	utils.Audit(c, slog.LevelInfo, message, objInfo, dataInfo)
+}
+
+// EditArticle edits an article of the current user, or an article the user co-authors.
+// The new version is kept as a revision, and the article re-enters moderation unless the user is an admin.
+func EditArticle(c *gin.Context) {
+	defer func() {
+		if err := recover(); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err, "request_id": c.GetString(initializers.RequestIDKey)})
+			initializers.LOGGER.ErrorContext(c, "EditArticle Failed", "error", err, "sub", utils.GetSubInfo(c), "params", c.MustGet("params"))
+		}
+	}()
+
+	// [Get the filtered parsed body and save it to the context]
+	rawBody := utils.GetRawBody(c)
+	parsedBody := utils.GetParsedBody(rawBody)
+	utils.BlurMap(parsedBody, "password")
+	c.Set("params", parsedBody)
+
+	// Get the user off the context
+	user, _ := c.Get("user")
+	userID := user.(models.User).ID
+
+	// Get the article ID, title and body off the request
+	var body struct {
+		ID    uint   `json:"id" binding:"required"`
+		Title string `json:"title" binding:"required"`
+		Body  string `json:"body" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		panic("The id, title and body are all required")
+	}
+
+	// Get the article of the user from the database
+	// The co-authors may edit the article as well
+	var article models.Article
+	result := initializers.DB.Where("id = ?", body.ID).First(&article)
+	if result.Error != nil || (article.AuthorID != userID && !isCoauthor(userID, article.ID)) {
+		panic("Failed to find the article")
+	}
+	oldArticle := article
+
+	// Edit the article and keep the new version
+	err := editArticle(c, &article, body.Title, body.Body, userID)
+	if err != nil {
+		panic(err.Error())
+	}
+
+	// [Prepare the object and data information for logging]
+	objInfo := utils.ObjInfo{
+		Op:    utils.OpUpdate,
+		Table: models.Article{}.TableName(),
+		ID:    article.ID,
+	}
+	dataInfo := utils.DataInfo{
+		OldData: map[string]interface{}{
+			"title":  oldArticle.Title,
+			"body":   oldArticle.Body,
+			"status": oldArticle.Status,
+		},
+		NewData: map[string]interface{}{
+			"title":  article.Title,
+			"body":   article.Body,
+			"status": article.Status,
+		},
+	}
+
+	// Return a success response
+	message := "Article edited successfully"
+	c.JSON(http.StatusOK, gin.H{
+		"message": message,
+		"status":  article.Status,
+	})
+	utils.Audit(c, slog.LevelInfo, message, objInfo, dataInfo)
+}
+
+// ScheduleArticle sets the draft, publish time, unlisted and free state of an article of the current user.
+// The state is replaced as a whole. Submitting a draft sends it to moderation, unless the user is an admin.
+func ScheduleArticle(c *gin.Context) {
+	defer func() {
+		if err := recover(); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err, "request_id": c.GetString(initializers.RequestIDKey)})
+			initializers.LOGGER.ErrorContext(c, "ScheduleArticle Failed", "error", err, "sub", utils.GetSubInfo(c), "params", c.MustGet("params"))
+		}
+	}()
+
+	// [Get the filtered parsed body and save it to the context]
+	rawBody := utils.GetRawBody(c)
+	parsedBody := utils.GetParsedBody(rawBody)
+	utils.BlurMap(parsedBody, "password")
+	c.Set("params", parsedBody)
+
+	// Get the user off the context
+	user, _ := c.Get("user")
+	userID := user.(models.User).ID
+
+	// Get the article ID and its new state off the request
+	var body struct {
+		ID        uint       `json:"id" binding:"required"`
+		Draft     bool       `json:"draft"`
+		PublishAt *time.Time `json:"publish_at"`
+		Unlisted  bool       `json:"unlisted"`
+		Free      bool       `json:"free"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		panic("ID is required")
+	}
+
+	// Get the article of the user from the database
+	var article models.Article
+	result := initializers.DB.Where("id = ? AND author_id = ?", body.ID, userID).First(&article)
+	if result.Error != nil {
+		panic("Failed to find the article")
+	}
+
+	// Get the new status of the article
+	// (1) A draft stays private to the author
+	// (2) A submitted draft goes to moderation, unless the user is an admin
+	// (3) A live or scheduled article follows its new publish time
+	// (4) A pending or rejected article keeps its status
+	status := article.Status
+	if body.Draft {
+		status = models.Draft
+	} else if article.Status == models.Draft {
+		if ok, _ := initializers.E.HasGroupingPolicy(utils.GetSubEmail(c), "admin"); ok {
+			status = approvedStatus(body.PublishAt)
+		} else {
+			status = models.Pending
+		}
+	} else if article.Status == models.Approved || article.Status == models.Scheduled {
+		status = approvedStatus(body.PublishAt)
+	}
+
+	// Update the article in the database
+	result = initializers.DB.Model(&models.Article{}).Where("id = ?", article.ID).Updates(map[string]interface{}{
+		"status":     status,
+		"publish_at": body.PublishAt,
+		"unlisted":   body.Unlisted,
+		"free":       body.Free,
+	})
+	if result.Error != nil {
+		panic("Failed to schedule the article")
+	}
+
+	// Update the search index
+	utils.IndexArticle(c, article.ID)
+
+	// [Prepare the object and data information for logging]
+	objInfo := utils.ObjInfo{
+		Op:    utils.OpUpdate,
+		Table: models.Article{}.TableName(),
+		ID:    article.ID,
+	}
+	dataInfo := utils.DataInfo{
+		OldData: map[string]interface{}{
+			"status":     article.Status,
+			"publish_at": article.PublishAt,
+			"unlisted":   article.Unlisted,
+			"free":       article.Free,
+		},
+		NewData: map[string]interface{}{
+			"status":     status,
+			"publish_at": body.PublishAt,
+			"unlisted":   body.Unlisted,
+			"free":       body.Free,
+		},
+	}
+
+	// Return a success response
+	message := "Article scheduled successfully"
+	c.JSON(http.StatusOK, gin.H{
+		"message": message,
+		"status":  status,
 	})
-	initializers.LOGGER.Info(message, "sub", utils.GetSubInfo(c), "obj", objInfo, "data", dataInfo)
+	utils.Audit(c, slog.LevelInfo, message, objInfo, dataInfo)
 }
 
-// RemoveArticle removes an article of the current user.
+// RemoveArticle removes an article of the current user, along with the comments on it.
+// Only the author may remove a co-authored article, a co-author leaves it instead (see leaveCoauthoredArticle).
 func RemoveArticle(c *gin.Context) {
 	defer func() {
 		if err := recover(); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err})
-			initializers.LOGGER.Error("RemoveArticle Failed", "error", err, "sub", utils.GetSubInfo(c), "params", utils.GetParsedQuery(c))
+			c.JSON(http.StatusBadRequest, gin.H{"error": err, "request_id": c.GetString(initializers.RequestIDKey)})
+			initializers.LOGGER.ErrorContext(c, "RemoveArticle Failed", "error", err, "sub", utils.GetSubInfo(c), "params", utils.GetParsedQuery(c))
 		}
 	}()
 
@@ -370,6 +683,12 @@ func RemoveArticle(c *gin.Context) {
 	var article models.Article
 	initializers.DB.First(&article, uint(articleID))
 
+	// A co-author leaves the article, which stays with its author
+	if article.ID != 0 && article.AuthorID != userID {
+		leaveCoauthoredArticle(c, article, userID)
+		return
+	}
+
 	// Delete the article from the database
 	result = initializers.DB.Where("id = ? AND author_id = ?", uint(articleID), userID).Delete(&models.Article{})
 	if result.Error != nil || result.RowsAffected == 0 {
@@ -382,6 +701,9 @@ func RemoveArticle(c *gin.Context) {
 		panic("Failed to remove comments on the article")
 	}
 
+	// Update the search index
+	utils.IndexArticle(c, uint(articleID))
+
 	// [Prepare the object and data information for logging]
 	objInfo := utils.ObjInfo{
 		Op:    utils.OpDelete,
@@ -401,17 +723,24 @@ func RemoveArticle(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{
 		"message": message,
 	})
-	initializers.LOGGER.Info(message, "sub", utils.GetSubInfo(c), "obj", objInfo, "data", dataInfo)
+	utils.Audit(c, slog.LevelInfo, message, objInfo, dataInfo)
 }
 
 // LikeArticle likes or dislikes an article.
 func LikeArticle(c *gin.Context) {
 	defer func() {
 		if err := recover(); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err})
+			c.JSON(http.StatusBadRequest, gin.H{"error": err, "request_id": c.GetString(initializers.RequestIDKey)})
+			initializers.LOGGER.ErrorContext(c, "LikeArticle Failed", "error", err, "sub", utils.GetSubInfo(c), "params", c.MustGet("params"))
 		}
 	}()
 
+	// [Get the filtered parsed body and save it to the context]
+	rawBody := utils.GetRawBody(c)
+	parsedBody := utils.GetParsedBody(rawBody)
+	utils.BlurMap(parsedBody, "password")
+	c.Set("params", parsedBody)
+
 	// Get the userID off the context
 	user, _ := c.Get("user")
 	userID := user.(models.User).ID
@@ -425,98 +754,133 @@ func LikeArticle(c *gin.Context) {
 		panic("Both id and like status are required")
 	}
 
+	// [Get the article likes and dislikes from the database]
+	var oldArticle models.Article
+	initializers.DB.Select("likes", "dislikes").Where("id = ?", body.ID).Find(&oldArticle)
+
 	// Update the article in the database
-	switch body.LikeCode {
-	case models.Like:
-		err := likeArticle(userID, body.ID)
-		if err != nil {
-			panic(err.Error())
-		}
-	case models.Dislike:
-		err := dislikeArticle(userID, body.ID)
-		if err != nil {
-			panic(err.Error())
-		}
-	default:
+	if body.LikeCode != models.Like && body.LikeCode != models.Dislike {
 		panic("Invalid like code: Must be either Like(1) or Dislike(2)")
 	}
+	if err := reactArticle(c, userID, body.ID, body.LikeCode); err != nil {
+		panic(err.Error())
+	}
+
+	// [Get the updated article likes and dislikes from the database]
+	var newArticle models.Article
+	initializers.DB.Select("likes", "dislikes").Where("id = ?", body.ID).Find(&newArticle)
+
+	// [Prepare the object and data information for logging]
+	objInfo := utils.ObjInfo{
+		Op:    utils.OpUpdate,
+		Table: models.Article{}.TableName(),
+		ID:    body.ID,
+	}
+	dataInfo := utils.DataInfo{
+		OldData: map[string]interface{}{
+			"likes":    oldArticle.Likes,
+			"dislikes": oldArticle.Dislikes,
+		},
+		NewData: map[string]interface{}{
+			"likes":    newArticle.Likes,
+			"dislikes": newArticle.Dislikes,
+		},
+	}
 
 	// Return a success response
+	message := "Article liked/disliked successfully"
 	c.JSON(http.StatusOK, gin.H{
-		"message": "Article liked/disliked successfully",
+		"message": message,
 	})
+	utils.Audit(c, slog.LevelInfo, message, objInfo, dataInfo)
 }
 
-func likeArticle(userID, articleID uint) error {
-	// Check if the user has already liked the article in Redis Sorted Set
+// reactArticle likes or dislikes (likeCode) an article, or cancels the reaction if the user has already reacted so.
+// The reaction is toggled in the Redis sets atomically by LikeScript, then recorded in the likes table along with
+// the counter of the article, and the sets are rolled back if the database fails.
+func reactArticle(c *gin.Context, userID, articleID uint, likeCode uint) error {
+	name, opposite := "like", "dislike"
+	column, hot := "likes", utils.HotLike
 	key := utils.RedisConstants.ARTICLE_LIKED_KEY_PREFIX + strconv.Itoa(int(articleID))
-	err := initializers.RDB.ZScore(initializers.RDB_CTX, key, strconv.Itoa(int(userID))).Err()
-	if err == redis.Nil {
-		// If not, add the user to the Redis Sorted Set and increment the likes in the database
-		// Before doing that, we need to check if the user has already disliked the article
-		tmp_key := utils.RedisConstants.ARTICLE_DISLIKED_KEY_PREFIX + strconv.Itoa(int(articleID))
-		if initializers.RDB.ZScore(initializers.RDB_CTX, tmp_key, strconv.Itoa(int(userID))).Err() == nil {
-			return errors.New("already disliked the article, please cancel the dislike first")
-		}
-		result := initializers.DB.Model(&models.Article{}).Where("id = ?", articleID).Update("likes", gorm.Expr("likes + 1"))
-		if result.Error != nil {
-			return errors.New("failed to like the article")
-		}
-		initializers.RDB.ZAdd(initializers.RDB_CTX, key, redis.Z{
-			Score:  float64(time.Now().Unix()),
-			Member: strconv.Itoa(int(userID)),
-		})
-	} else if err == nil {
-		// If so, remove the user from the Redis Sorted Set and decrement the likes in the database
-		result := initializers.DB.Model(&models.Article{}).Where("id = ?", articleID).Update("likes", gorm.Expr("likes - 1"))
-		if result.Error != nil {
-			return errors.New("failed to cancel like the article")
-		}
-		initializers.RDB.ZRem(initializers.RDB_CTX, key, strconv.Itoa(int(userID)))
-	} else {
-		return errors.New("internal redis error: " + err.Error())
+	tmp_key := utils.RedisConstants.ARTICLE_DISLIKED_KEY_PREFIX + strconv.Itoa(int(articleID))
+	if likeCode == models.Dislike {
+		name, opposite = "dislike", "like"
+		column, hot = "dislikes", utils.HotDislike
+		key, tmp_key = tmp_key, key
 	}
-	return nil
-}
 
-func dislikeArticle(userID, articleID uint) error {
-	// Check if the user has already disliked the article in Redis Sorted Set
-	key := utils.RedisConstants.ARTICLE_DISLIKED_KEY_PREFIX + strconv.Itoa(int(articleID))
-	err := initializers.RDB.ZScore(initializers.RDB_CTX, key, strconv.Itoa(int(userID))).Err()
-	if err == redis.Nil {
-		// If not, add the user to the Redis Sorted Set and increment the dislikes in the database
-		// Before doing that, we need to check if the user has already liked the article
-		tmp_key := utils.RedisConstants.ARTICLE_LIKED_KEY_PREFIX + strconv.Itoa(int(articleID))
-		if initializers.RDB.ZScore(initializers.RDB_CTX, tmp_key, strconv.Itoa(int(userID))).Err() == nil {
-			return errors.New("already liked the article, please cancel the like first")
-		}
-		result := initializers.DB.Model(&models.Article{}).Where("id = ?", articleID).Update("dislikes", gorm.Expr("dislikes + 1"))
-		if result.Error != nil {
-			return errors.New("failed to dislike the article")
+	// Check if the article exists
+	var article models.Article
+	result := initializers.DB.Select("id").Where("id = ?", articleID).Limit(1).Find(&article)
+	if result.RowsAffected == 0 {
+		return errors.New("failed to find the article")
+	}
+
+	// Toggle the reaction in the Redis Sorted Sets
+	member := strconv.Itoa(int(userID))
+	now := time.Now()
+	reply, err := utils.LikeScript.Run(initializers.RDB_CTX, initializers.RDB, []string{key, tmp_key}, member, now.Unix()).Slice()
+	if err != nil || len(reply) == 0 {
+		return errors.New("internal redis error: failed to run the like script")
+	}
+	ret, _ := reply[0].(int64)
+
+	switch ret {
+	case utils.LikeAdded:
+		// Record the reaction and increment the counter in the database
+		err = initializers.DB.Transaction(func(tx *gorm.DB) error {
+			like := models.ArticleLike{ArticleID: articleID, UserID: userID, Code: likeCode, CreatedAt: now}
+			if err := tx.Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "article_id"}, {Name: "user_id"}},
+				DoUpdates: clause.AssignmentColumns([]string{"code", "created_at"}),
+			}).Create(&like).Error; err != nil {
+				return err
+			}
+			return tx.Model(&models.Article{}).Where("id = ?", articleID).Update(column, gorm.Expr(column+" + 1")).Error
+		})
+		if err != nil {
+			initializers.RDB.ZRem(initializers.RDB_CTX, key, member)
+			return errors.New("failed to " + name + " the article")
 		}
-		initializers.RDB.ZAdd(initializers.RDB_CTX, key, redis.Z{
-			Score:  float64(time.Now().Unix()),
-			Member: strconv.Itoa(int(userID)),
+		utils.RecordHotEvent(c, articleID, hot)
+	case utils.LikeCancelled:
+		// Forget the reaction and decrement the counter in the database
+		err = initializers.DB.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Where("article_id = ? AND user_id = ?", articleID, userID).Delete(&models.ArticleLike{}).Error; err != nil {
+				return err
+			}
+			return tx.Model(&models.Article{}).Where("id = ? AND "+column+" > 0", articleID).Update(column, gorm.Expr(column+" - 1")).Error
 		})
-	} else if err == nil {
-		// If so, remove the user from the Redis Sorted Set and decrement the dislikes in the database
-		result := initializers.DB.Model(&models.Article{}).Where("id = ?", articleID).Update("dislikes", gorm.Expr("dislikes - 1"))
-		if result.Error != nil {
-			return errors.New("failed to cancel dislike the article")
+		if err != nil {
+			// Restore the reaction with its former time, which orders the leaderboards and the analytics
+			score := float64(now.Unix())
+			if len(reply) > 1 {
+				if former, err := strconv.ParseFloat(fmt.Sprint(reply[1]), 64); err == nil {
+					score = former
+				}
+			}
+			initializers.RDB.ZAdd(initializers.RDB_CTX, key, redis.Z{
+				Score:  score,
+				Member: member,
+			})
+			return errors.New("failed to cancel " + name + " the article")
 		}
-		initializers.RDB.ZRem(initializers.RDB_CTX, key, strconv.Itoa(int(userID)))
-	} else {
-		return errors.New("internal redis error: " + err.Error())
+		utils.RecordHotEvent(c, articleID, -hot)
+	case utils.LikeConflict:
+		return errors.New("already " + opposite + "d the article, please cancel the " + opposite + " first")
+	default:
+		return errors.New("internal redis error: unexpected result of the like script")
 	}
 	return nil
 }
 
-// PostComment posts a comment on an article.
+// PostComment posts a comment on an article, or a reply to another comment.
+// The users mentioned by "@email" in the content are notified.
 func PostComment(c *gin.Context) {
 	defer func() {
 		if err := recover(); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err})
-			initializers.LOGGER.Error("PostComment Failed", "error", err, "sub", utils.GetSubInfo(c), "params", c.MustGet("params"))
+			c.JSON(http.StatusBadRequest, gin.H{"error": err, "request_id": c.GetString(initializers.RequestIDKey)})
+			initializers.LOGGER.ErrorContext(c, "PostComment Failed", "error", err, "sub", utils.GetSubInfo(c), "params", c.MustGet("params"))
 		}
 	}()
 
@@ -536,31 +900,80 @@ func PostComment(c *gin.Context) {
 		panic("Failed to post comment")
 	}
 
-	// Get the article ID and content off the request
+	// Get the article ID, content and optional parent comment ID off the request
 	var body struct {
-		ID      uint   `json:"id" binding:"required"`
-		Content string `json:"content" binding:"required"`
+		ID       uint   `json:"id" binding:"required"`
+		Content  string `json:"content" binding:"required"`
+		ParentID *uint  `json:"parent_id"`
 	}
 	if err := c.ShouldBindJSON(&body); err != nil {
 		panic("Both id and content are required")
 	}
 
+	// Check if the user is allowed to read the article
+	var article models.Article
+	result = initializers.DB.First(&article, body.ID)
+	if result.Error != nil {
+		panic("Failed to find the article")
+	}
+	if !canReadArticle(userID, article) {
+		panic("Not allowed to comment on the article")
+	}
+
+	// Check if the parent comment is an approved comment on the same article, within the depth limit
+	var depth uint
+	if body.ParentID != nil {
+		var parent models.Comment
+		result = initializers.DB.Where("id = ? AND article_id = ? AND status = ?", *body.ParentID, body.ID, models.Approved).First(&parent)
+		if result.Error != nil {
+			panic("Failed to find the parent comment")
+		}
+		depth = parent.Depth + 1
+		if depth > models.MaxCommentDepth {
+			panic("Failed to reply: Replies are nested too deeply")
+		}
+	}
+
+	// Run the moderation rules unless the user is an admin
+	status := uint(models.Approved)
+	var verdict *utils.ModerationResult
+	moderation := utils.ModerationInput{TargetType: models.ReportComment, AuthorID: userID, Text: body.Content}
+	if ok, _ := initializers.E.HasGroupingPolicy(utils.GetSubEmail(c), "admin"); !ok {
+		result := utils.Moderate(moderation)
+		verdict = &result
+		status = moderatedCommentStatus(result.Verdict)
+	}
+
 	// Prepare the comment object
 	comment := models.Comment{
 		Content:   body.Content,
 		AuthorID:  userID,
+		Status:    status,
 		ArticleID: body.ID,
+		ParentID:  body.ParentID,
+		Depth:     depth,
 	}
 
 	// Start a transaction to ensure atomicity
 	err := initializers.DB.Transaction(func(tx *gorm.DB) error {
 		// Create the comment in the database
+		// (Pending is the zero value, which would be replaced with the default status Approved)
 		result = tx.Create(&comment)
 		if result.Error != nil {
 			return errors.New("failed to post comment")
 		}
+		if status == models.Pending {
+			comment.Status = status
+			result = tx.Model(&comment).Update("status", status)
+			if result.Error != nil {
+				return errors.New("failed to post comment")
+			}
+		}
 
-		// Add 5 credits to the user
+		// Add 5 credits to the user, unless the comment is rejected by the moderation rules
+		if comment.Status == models.Rejected {
+			return nil
+		}
 		result = tx.Model(&models.User{}).Where("id = ?", userID).Update("credits", gorm.Expr("credits + 5"))
 		if result.Error != nil {
 			return errors.New("failed to add credits to the user")
@@ -572,6 +985,26 @@ func PostComment(c *gin.Context) {
 		panic(err.Error())
 	}
 
+	// Count the credits for the statistics
+	if comment.Status != models.Rejected {
+		utils.IncrStat(utils.StatCreditsMoved, 5)
+	}
+
+	// Record the verdict of the moderation rules
+	if verdict != nil {
+		utils.RecordVerdict(c, moderation, comment.ID, *verdict)
+	}
+
+	// Update the search index
+	utils.IndexComment(c, comment.ID)
+
+	// Notify the mentioned users and heat up the article once the comment is visible
+	var mentioned []string
+	if comment.Status == models.Approved {
+		mentioned = notifyMentions(c, comment)
+		utils.RecordHotEvent(c, comment.ArticleID, utils.HotComment)
+	}
+
 	// [Prepare the object and data information for logging]
 	objInfo := utils.ObjInfo{
 		Op:    utils.OpCreate,
@@ -581,24 +1014,29 @@ func PostComment(c *gin.Context) {
 	dataInfo := utils.DataInfo{
 		OldData: nil,
 		NewData: map[string]interface{}{
-			"content": comment.Content,
+			"content":   comment.Content,
+			"parent_id": comment.ParentID,
+			"mentioned": mentioned,
+			"status":    comment.Status,
 		},
 	}
 
-	// Return a success response
+	// Return a success response with the status of the comment
 	message := "Comment posted successfully"
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Comment posted successfully",
+		"id":      comment.ID,
+		"status":  comment.Status,
 	})
-	initializers.LOGGER.Info(message, "sub", utils.GetSubInfo(c), "obj", objInfo, "data", dataInfo)
+	utils.Audit(c, slog.LevelInfo, message, objInfo, dataInfo)
 }
 
-// RemoveComment removes a comment on an article.
+// RemoveComment removes a comment on an article along with its nested replies.
 func RemoveComment(c *gin.Context) {
 	defer func() {
 		if err := recover(); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err})
-			initializers.LOGGER.Error("RemoveComment Failed", "error", err, "sub", utils.GetSubInfo(c), "params", utils.GetParsedQuery(c))
+			c.JSON(http.StatusBadRequest, gin.H{"error": err, "request_id": c.GetString(initializers.RequestIDKey)})
+			initializers.LOGGER.ErrorContext(c, "RemoveComment Failed", "error", err, "sub", utils.GetSubInfo(c), "params", utils.GetParsedQuery(c))
 		}
 	}()
 
@@ -632,6 +1070,21 @@ func RemoveComment(c *gin.Context) {
 		panic("Failed to remove comment")
 	}
 
+	// Delete the nested replies of the comment from the database
+	replyIDs := getCommentDescendants(uint(commentID))
+	if len(replyIDs) > 0 {
+		result = initializers.DB.Where("id IN (?)", replyIDs).Delete(&models.Comment{})
+		if result.Error != nil {
+			panic("Failed to remove the replies of the comment")
+		}
+	}
+
+	// Update the search index
+	utils.IndexComment(c, uint(commentID))
+	for _, replyID := range replyIDs {
+		utils.IndexComment(c, replyID)
+	}
+
 	// [Prepare the object and data information for logging]
 	objInfo := utils.ObjInfo{
 		Op:    utils.OpDelete,
@@ -641,6 +1094,7 @@ func RemoveComment(c *gin.Context) {
 	dataInfo := utils.DataInfo{
 		OldData: map[string]interface{}{
 			"content": comment.Content,
+			"replies": replyIDs,
 		},
 		NewData: nil,
 	}
@@ -650,30 +1104,36 @@ func RemoveComment(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{
 		"message": message,
 	})
-	initializers.LOGGER.Info(message, "sub", utils.GetSubInfo(c), "obj", objInfo, "data", dataInfo)
+	utils.Audit(c, slog.LevelInfo, message, objInfo, dataInfo)
 }
 
 // GetArticles is an Admin API Endpoint that retrieves all articles.
 func GetArticles(c *gin.Context) {
 	defer func() {
 		if err := recover(); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err})
+			c.JSON(http.StatusBadRequest, gin.H{"error": err, "request_id": c.GetString(initializers.RequestIDKey)})
 		}
 	}()
 
+	// Get the status filter off the query string
+	query := initializers.DB.Model(&models.Article{})
+	if s, ok := c.GetQuery("status"); ok {
+		query = query.Where("status = ?", parseArticleStatus(s))
+	}
+
 	// Get the pagination parameters
 	params := utils.GetPaginationParams(c)
 
 	// Get the total number of articles
 	var total int64
-	result := initializers.DB.Model(&models.Article{}).Count(&total)
+	result := query.Session(&gorm.Session{}).Count(&total)
 	if result.Error != nil {
 		panic("Failed to get the total number of articles")
 	}
 
 	// Get the articles from the database
 	var articles []map[string]interface{}
-	result = initializers.DB.Model(&models.Article{}).Offset(params.Offset).Limit(params.PageSize).Find(&articles)
+	result = query.Session(&gorm.Session{}).Offset(params.Offset).Limit(params.PageSize).Find(&articles)
 	if result.Error != nil {
 		panic("Failed to get the articles from the database")
 	}
@@ -693,8 +1153,8 @@ func GetArticles(c *gin.Context) {
 func SetArticleStatus(c *gin.Context) {
 	defer func() {
 		if err := recover(); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err})
-			initializers.LOGGER.Error("SetArticleStatus Failed", "error", err, "sub", utils.GetSubInfo(c), "params", c.MustGet("params"))
+			c.JSON(http.StatusBadRequest, gin.H{"error": err, "request_id": c.GetString(initializers.RequestIDKey)})
+			initializers.LOGGER.ErrorContext(c, "SetArticleStatus Failed", "error", err, "sub", utils.GetSubInfo(c), "params", c.MustGet("params"))
 		}
 	}()
 
@@ -704,10 +1164,15 @@ func SetArticleStatus(c *gin.Context) {
 	utils.BlurMap(parsedBody, "password")
 	c.Set("params", parsedBody)
 
-	// Get the article ID and status off the request
+	// Get the user off the context
+	user, _ := c.Get("user")
+	userID := user.(models.User).ID
+
+	// Get the article ID, status and optional reason of the decision off the request
 	var body struct {
-		ID     uint `json:"id" binding:"required"`
-		Status uint `json:"status" binding:"required"`
+		ID     uint   `json:"id" binding:"required"`
+		Status uint   `json:"status" binding:"required"`
+		Reason string `json:"reason"`
 	}
 	if err := c.ShouldBindJSON(&body); err != nil {
 		panic("Both id and status are required")
@@ -719,8 +1184,19 @@ func SetArticleStatus(c *gin.Context) {
 	}
 
 	// [Get the article status from the database]
-	var status uint
-	initializers.DB.Model(&models.Article{}).Select("status").Where("id = ?", body.ID).Find(&status)
+	var article models.Article
+	initializers.DB.Select("status", "publish_at").Where("id = ?", body.ID).Find(&article)
+	status := article.Status
+
+	// Drafts are not submitted for moderation yet
+	if status == models.Draft {
+		panic("Failed to set the status of the article: It is still a draft")
+	}
+
+	// Approved articles with a future publish time are scheduled
+	if body.Status == models.Approved {
+		body.Status = approvedStatus(article.PublishAt)
+	}
 
 	// Set the status of the article in the database
 	result := initializers.DB.Model(&models.Article{}).Where("id = ?", body.ID).Update("status", body.Status)
@@ -728,6 +1204,12 @@ func SetArticleStatus(c *gin.Context) {
 		panic("Failed to set the status of the article")
 	}
 
+	// Update the search index
+	utils.IndexArticle(c, body.ID)
+
+	// Close the reports on the article with the decision
+	reports := decideReports(c, models.ReportArticle, body.ID, userID, body.Status, body.Reason)
+
 	// [Prepare the object and data information for logging]
 	objInfo := utils.ObjInfo{
 		Op:    utils.OpUpdate,
@@ -739,7 +1221,9 @@ func SetArticleStatus(c *gin.Context) {
 			"status": status,
 		},
 		NewData: map[string]interface{}{
-			"status": body.Status,
+			"status":  body.Status,
+			"reason":  body.Reason,
+			"reports": reports,
 		},
 	}
 
@@ -748,14 +1232,14 @@ func SetArticleStatus(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{
 		"message": message,
 	})
-	initializers.LOGGER.Info(message, "sub", utils.GetSubInfo(c), "obj", objInfo, "data", dataInfo)
+	utils.Audit(c, slog.LevelInfo, message, objInfo, dataInfo)
 }
 
 // GetComments is an Admin API Endpoint that retrieves all comments.
 func GetComments(c *gin.Context) {
 	defer func() {
 		if err := recover(); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err})
+			c.JSON(http.StatusBadRequest, gin.H{"error": err, "request_id": c.GetString(initializers.RequestIDKey)})
 		}
 	}()
 
@@ -791,8 +1275,8 @@ func GetComments(c *gin.Context) {
 func SetCommentStatus(c *gin.Context) {
 	defer func() {
 		if err := recover(); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err})
-			initializers.LOGGER.Error("SetCommentStatus Failed", "error", err, "sub", utils.GetSubInfo(c), "params", c.MustGet("params"))
+			c.JSON(http.StatusBadRequest, gin.H{"error": err, "request_id": c.GetString(initializers.RequestIDKey)})
+			initializers.LOGGER.ErrorContext(c, "SetCommentStatus Failed", "error", err, "sub", utils.GetSubInfo(c), "params", c.MustGet("params"))
 		}
 	}()
 
@@ -802,10 +1286,15 @@ func SetCommentStatus(c *gin.Context) {
 	utils.BlurMap(parsedBody, "password")
 	c.Set("params", parsedBody)
 
-	// Get the comment ID and status off the request
+	// Get the user off the context
+	user, _ := c.Get("user")
+	userID := user.(models.User).ID
+
+	// Get the comment ID, status and optional reason of the decision off the request
 	var body struct {
-		ID     uint `json:"id" binding:"required"`
-		Status uint `json:"status" binding:"required"`
+		ID     uint   `json:"id" binding:"required"`
+		Status uint   `json:"status" binding:"required"`
+		Reason string `json:"reason"`
 	}
 	if err := c.ShouldBindJSON(&body); err != nil {
 		panic("Both id and status are required")
@@ -816,9 +1305,10 @@ func SetCommentStatus(c *gin.Context) {
 		panic("Invalid status: Must be Approved(1) or Rejected(2)")
 	}
 
-	// [Get the comment status from the database]
-	var status uint
-	initializers.DB.Model(&models.Comment{}).Select("status").Where("id = ?", body.ID).Find(&status)
+	// [Get the comment from the database]
+	var comment models.Comment
+	initializers.DB.Where("id = ?", body.ID).Limit(1).Find(&comment)
+	status := comment.Status
 
 	// Set the status of the comment in the database
 	result := initializers.DB.Model(&models.Comment{}).Where("id = ?", body.ID).Update("status", body.Status)
@@ -826,6 +1316,19 @@ func SetCommentStatus(c *gin.Context) {
 		panic("Failed to set the status of the comment")
 	}
 
+	// Update the search index
+	utils.IndexComment(c, body.ID)
+
+	// Notify the mentioned users and heat up the article once a held comment is approved
+	var mentioned []string
+	if status == models.Pending && body.Status == models.Approved {
+		mentioned = notifyMentions(c, comment)
+		utils.RecordHotEvent(c, comment.ArticleID, utils.HotComment)
+	}
+
+	// Close the reports on the comment with the decision
+	reports := decideReports(c, models.ReportComment, body.ID, userID, body.Status, body.Reason)
+
 	// [Prepare the object and data information for logging]
 	objInfo := utils.ObjInfo{
 		Op:    utils.OpUpdate,
@@ -837,7 +1340,10 @@ func SetCommentStatus(c *gin.Context) {
 			"status": status,
 		},
 		NewData: map[string]interface{}{
-			"status": body.Status,
+			"status":    body.Status,
+			"reason":    body.Reason,
+			"reports":   reports,
+			"mentioned": mentioned,
 		},
 	}
 
@@ -846,5 +1352,180 @@ func SetCommentStatus(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{
 		"message": message,
 	})
-	initializers.LOGGER.Info(message, "sub", utils.GetSubInfo(c), "obj", objInfo, "data", dataInfo)
+	utils.Audit(c, slog.LevelInfo, message, objInfo, dataInfo)
+}
+
+// approvedStatus returns the status of an approved article, which stays Scheduled until its publish time.
+func approvedStatus(publishAt *time.Time) uint {
+	if publishAt != nil && publishAt.After(time.Now()) {
+		return models.Scheduled
+	}
+	return models.Approved
+}
+
+// parseArticleStatus parses the status filter of the article listings.
+func parseArticleStatus(s string) uint {
+	status, err := strconv.Atoi(s)
+	if err != nil || status < models.Pending || status > models.Scheduled {
+		panic("Invalid status: Must be one of Pending(0), Approved(1), Rejected(2), Draft(3), or Scheduled(4)")
+	}
+	return uint(status)
+}
+
+// getArticleFormat returns the format of the article bodies in the listings, "markdown" (source) by default.
+func getArticleFormat(c *gin.Context) string {
+	format := c.DefaultQuery("format", "markdown")
+	if format != "markdown" && format != "html" {
+		panic("Invalid format: Must be either html or markdown")
+	}
+	return format
+}
+
+// renderArticle renders the Markdown body of an article into its cached HTML and table of contents.
+func renderArticle(article *models.Article) error {
+	bodyHTML, toc, err := utils.RenderMarkdown(article.Body)
+	if err != nil {
+		return errors.New("failed to render the article")
+	}
+	content, err := json.Marshal(toc)
+	if err != nil {
+		return errors.New("failed to render the article")
+	}
+	article.BodyHTML = bodyHTML
+	article.TOC = string(content)
+	return nil
+}
+
+// formatArticles sets the bodies of the listed articles in the format.
+// For "html", the body is replaced by its cached rendering and the table of contents is added.
+// The articles posted before the rendering was cached are rendered and cached on the fly.
+func formatArticles(format string, articles []map[string]interface{}) {
+	for i := range articles {
+		bodyHTML, _ := articles[i]["body_html"].(string)
+		toc, _ := articles[i]["toc"].(string)
+		delete(articles[i], "body_html")
+		delete(articles[i], "toc")
+		body, ok := articles[i]["body"].(string)
+		if format != "html" || !ok {
+			continue
+		}
+
+		if toc == "" {
+			article := models.Article{Body: body}
+			article.ID = articles[i]["id"].(uint)
+			if err := renderArticle(&article); err != nil {
+				panic(err.Error())
+			}
+			initializers.DB.Model(&models.Article{}).Where("id = ?", article.ID).Updates(map[string]interface{}{
+				"body_html": article.BodyHTML,
+				"toc":       article.TOC,
+			})
+			bodyHTML, toc = article.BodyHTML, article.TOC
+		}
+		articles[i]["body"] = bodyHTML
+		articles[i]["toc"] = json.RawMessage(toc)
+	}
+}
+
+// gateArticles replaces the premium articles that the reader may not read in full by their previews.
+// The reader may read the free articles, and the premium ones written by the reader or by an author or co-author
+// the reader has subscribed to. A preview keeps the title and like counts, with an excerpt of the body and a call-to-action to subscribe.
+func gateArticles(readerID uint, articles []map[string]interface{}) {
+	// Get the authors of the premium articles, along with their co-authors
+	var authorIDs, premiumIDs []uint
+	for _, article := range articles {
+		article["locked"] = false
+		if !article["free"].(bool) {
+			authorIDs = append(authorIDs, article["author_id"].(uint))
+			premiumIDs = append(premiumIDs, article["id"].(uint))
+		}
+	}
+	if len(authorIDs) == 0 {
+		return
+	}
+	coauthorIDs := getCoauthorIDs(premiumIDs)
+	subscribable := authorIDs
+	for _, ids := range coauthorIDs {
+		subscribable = append(subscribable, ids...)
+	}
+
+	// Get the authors and co-authors the reader has subscribed to
+	var subscribed []uint
+	initializers.DB.Model(&models.Subscribe{}).Where("reader_id = ? AND author_id IN (?)", readerID, subscribable).Pluck("author_id", &subscribed)
+	allowed := map[uint]bool{readerID: true}
+	for _, authorID := range subscribed {
+		allowed[authorID] = true
+	}
+
+	// Get the subscription fees and preview lengths of the authors
+	var authors []models.User
+	initializers.DB.Select("id", "subfee", "preview_length").Where("id IN (?)", authorIDs).Find(&authors)
+	authorsByID := make(map[uint]models.User, len(authors))
+	for _, author := range authors {
+		authorsByID[author.ID] = author
+	}
+
+	for i := range articles {
+		authorID := articles[i]["author_id"].(uint)
+		if articles[i]["free"].(bool) || allowed[authorID] || anyAllowed(allowed, coauthorIDs[articles[i]["id"].(uint)]) {
+			continue
+		}
+
+		// Get the text of the body off the cached rendering, or render it for the articles posted before
+		bodyHTML, _ := articles[i]["body_html"].(string)
+		if bodyHTML == "" {
+			article := models.Article{Body: articles[i]["body"].(string)}
+			if err := renderArticle(&article); err != nil {
+				panic(err.Error())
+			}
+			bodyHTML = article.BodyHTML
+		}
+
+		author := authorsByID[authorID]
+		articles[i]["excerpt"] = utils.Excerpt(bodyHTML, int(author.PreviewLength))
+		articles[i]["locked"] = true
+		articles[i]["subscribe"] = gin.H{
+			"author_id": authorID,
+			"subfee":    author.Subfee,
+			"endpoint":  "/api/ui/subscribe",
+		}
+		delete(articles[i], "body")
+		delete(articles[i], "body_html")
+		delete(articles[i], "toc")
+	}
+}
+
+// anyAllowed reports whether any of the users is allowed.
+func anyAllowed(allowed map[uint]bool, userIDs []uint) bool {
+	for _, userID := range userIDs {
+		if allowed[userID] {
+			return true
+		}
+	}
+	return false
+}
+
+// moderatedArticleStatus returns the status of a new article from the verdict of the moderation rules.
+// The articles passing the rules are approved only if auto-approval is enabled, otherwise they wait for a moderator.
+func moderatedArticleStatus(verdict uint, publishAt *time.Time) uint {
+	switch {
+	case verdict == models.VerdictReject:
+		return models.Rejected
+	case verdict == models.VerdictApprove && initializers.ModerationAutoApprove:
+		return approvedStatus(publishAt)
+	default:
+		return models.Pending
+	}
+}
+
+// moderatedCommentStatus returns the status of a new comment from the verdict of the moderation rules.
+func moderatedCommentStatus(verdict uint) uint {
+	switch verdict {
+	case models.VerdictReject:
+		return models.Rejected
+	case models.VerdictHold:
+		return models.Pending
+	default:
+		return models.Approved
+	}
 }
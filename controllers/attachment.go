@@ -0,0 +1,431 @@
+package controllers
+
+import (
+	"auth/initializers"
+	"auth/models"
+	"auth/utils"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// UploadAttachment uploads a file of the current user for an article, optionally bound to it by "article_id".
+// The type of the file is sniffed off its content, and the upload is refused once the user's storage quota is used up.
+// The response gives the Markdown linking the attachment, an unbound attachment being bound once the article links it.
+func UploadAttachment(c *gin.Context) {
+	defer func() {
+		if err := recover(); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err, "request_id": c.GetString(initializers.RequestIDKey)})
+			initializers.LOGGER.ErrorContext(c, "UploadAttachment Failed", "error", err, "sub", utils.GetSubInfo(c))
+		}
+	}()
+
+	// Get the user off the context
+	user, _ := c.Get("user")
+	userID := user.(models.User).ID
+
+	// Get the article off the request body, if any
+	var articleID *uint
+	if id := c.PostForm("article_id"); id != "" {
+		var article models.Article
+		result := initializers.DB.Select("id").Where("id = ? AND author_id = ?", utils.StrToUint(id), userID).Limit(1).Find(&article)
+		if result.RowsAffected == 0 {
+			panic("Failed to find the article")
+		}
+		articleID = &article.ID
+	}
+
+	// Get the file off the request body
+	file, err := c.FormFile("file")
+	if err != nil {
+		panic("Failed to get the file from the request")
+	}
+	if file.Size > int64(initializers.AttachmentMaxMB)<<20 {
+		panic("Invalid attachment: File size exceeds " + strconv.Itoa(initializers.AttachmentMaxMB) + "MB")
+	}
+
+	// Sniff the type of the file off its first bytes, regardless of its extension
+	src, err := file.Open()
+	if err != nil {
+		panic("Failed to read the file")
+	}
+	head := make([]byte, 512)
+	n, err := io.ReadFull(src, head)
+	src.Close()
+	if err != nil && err != io.ErrUnexpectedEOF {
+		panic("Failed to read the file")
+	}
+	mime, ext, ok := utils.SniffAttachment(head[:n])
+	if !ok {
+		panic("Invalid attachment: Only PNG, JPEG, GIF, WebP, PDF, ZIP and plain text files are allowed")
+	}
+
+	// Lock the storage of the user, so that concurrent uploads cannot exceed the quota
+	lockKey := utils.RedisConstants.MUTEX_ATTACHMENT_KEY_PREFIX + strconv.Itoa(int(userID))
+	if !utils.SimpleTryLock(lockKey, utils.RedisConstants.MUTEX_ATTACHMENT_EXPIRE_TIME) {
+		panic("Another upload is in progress, please retry")
+	}
+	defer utils.SimpleUnlock(lockKey)
+
+	// Check the storage quota of the user
+	used, quota := getStorageUsage(userID)
+	if used+file.Size > quota {
+		panic("Storage quota exceeded: " + strconv.FormatInt(used>>20, 10) + "MB of " + strconv.FormatInt(quota>>20, 10) + "MB used")
+	}
+
+	// Save the file to the server under a unique file name
+	if err := os.MkdirAll(initializers.AttachmentDir, 0755); err != nil {
+		panic("Failed to save the file")
+	}
+	fileName := uuid.New().String() + ext
+	filePath := filepath.Join(initializers.AttachmentDir, fileName)
+	if err := c.SaveUploadedFile(file, filePath); err != nil {
+		panic("Failed to save the file")
+	}
+
+	// Create the attachment in the database
+	attachment := models.Attachment{
+		OwnerID:   userID,
+		ArticleID: articleID,
+		FileName:  fileName,
+		Name:      filepath.Base(file.Filename),
+		MIME:      mime,
+		Size:      file.Size,
+	}
+	result := initializers.DB.Create(&attachment)
+	if result.Error != nil {
+		os.Remove(filePath)
+		panic("Failed to create the attachment in the database")
+	}
+
+	// [Prepare the object and data information for logging]
+	objInfo := utils.ObjInfo{
+		Op:    utils.OpCreate,
+		Table: models.Attachment{}.TableName(),
+		ID:    attachment.ID,
+	}
+	dataInfo := utils.DataInfo{
+		OldData: nil,
+		NewData: map[string]interface{}{
+			"article_id": attachment.ArticleID,
+			"file_name":  attachment.FileName,
+			"name":       attachment.Name,
+			"mime":       attachment.MIME,
+			"size":       attachment.Size,
+		},
+	}
+
+	// Return a success response with the link of the attachment
+	url := attachmentURL(attachment.ID)
+	message := "Attachment uploaded successfully"
+	c.JSON(http.StatusOK, gin.H{
+		"message":  message,
+		"id":       attachment.ID,
+		"url":      url,
+		"markdown": attachmentMarkdown(attachment),
+		"mime":     attachment.MIME,
+		"size":     attachment.Size,
+		"storage":  gin.H{"used": used + file.Size, "quota": quota},
+	})
+	utils.Audit(c, slog.LevelInfo, message, objInfo, dataInfo)
+}
+
+// FetchAttachments retrieves the attachments of the current user, optionally those of an article, with the storage usage.
+func FetchAttachments(c *gin.Context) {
+	defer func() {
+		if err := recover(); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err, "request_id": c.GetString(initializers.RequestIDKey)})
+		}
+	}()
+
+	// Get the user off the context
+	user, _ := c.Get("user")
+	userID := user.(models.User).ID
+
+	// Get the pagination parameters
+	params := utils.GetPaginationParams(c)
+
+	// Get the storage usage of the user
+	used, quota := getStorageUsage(userID)
+	storage := gin.H{"used": used, "quota": quota}
+
+	// Get the total number of attachments
+	query := initializers.DB.Model(&models.Attachment{}).Where("owner_id = ?", userID)
+	if id, ok := c.GetQuery("article_id"); ok {
+		query = query.Where("article_id = ?", utils.StrToUint(id))
+	}
+	var total int64
+	result := query.Session(&gorm.Session{}).Count(&total)
+	if result.Error != nil {
+		panic("Failed to get the total number of attachments")
+	}
+	if total == 0 {
+		c.JSON(http.StatusOK, gin.H{
+			"message":     "Attachments retrieved successfully",
+			"attachments": []map[string]interface{}{},
+			"storage":     storage,
+		})
+		return
+	}
+
+	// Get the attachments from the database
+	var attachments []map[string]interface{}
+	result = query.Session(&gorm.Session{}).Select("id", "article_id", "name", "mime", "size", "created_at").
+		Order("created_at DESC").Offset(params.Offset).Limit(params.PageSize).Find(&attachments)
+	if result.Error != nil {
+		panic("Failed to get the attachments from the database")
+	}
+	for i := range attachments {
+		attachments[i]["url"] = attachmentURL(attachments[i]["id"].(uint))
+	}
+
+	// Get the pagination result
+	pagination := utils.GetPaginationResult(params, len(attachments), total)
+
+	// Return a success response with the attachments
+	c.JSON(http.StatusOK, gin.H{
+		"message":     "Attachments retrieved successfully",
+		"attachments": attachments,
+		"storage":     storage,
+		"pagination":  pagination,
+	})
+}
+
+// GetAttachment serves an attachment with the access rules of its article:
+// the readers who may read the article in full may get its attachments (see canReadArticle).
+// The unbound attachments are only served to their owner.
+func GetAttachment(c *gin.Context) {
+	defer func() {
+		if err := recover(); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err, "request_id": c.GetString(initializers.RequestIDKey)})
+		}
+	}()
+
+	// Get the user off the context
+	user, _ := c.Get("user")
+	userID := user.(models.User).ID
+
+	// Get the id off the path parameter
+	id, ok := c.Params.Get("id")
+	if !ok {
+		panic("ID is required")
+	}
+	attachmentID, err := strconv.Atoi(id)
+	if err != nil {
+		panic("Invalid ID: Type error")
+	}
+
+	// Get the attachment from the database
+	var attachment models.Attachment
+	result := initializers.DB.Where("id = ?", uint(attachmentID)).Limit(1).Find(&attachment)
+	if result.RowsAffected == 0 {
+		panic("Failed to find the attachment")
+	}
+
+	// Check if the user is allowed to get the attachment
+	if attachment.OwnerID != userID {
+		if attachment.ArticleID == nil {
+			panic("Failed to find the attachment")
+		}
+		var article models.Article
+		result := initializers.DB.Select("id", "author_id", "status", "free").Where("id = ?", *attachment.ArticleID).Limit(1).Find(&article)
+		// The co-authors may get the attachments of the articles that are not approved, as they may edit them
+		if result.RowsAffected == 0 || (article.AuthorID != userID && article.Status != models.Approved && !isCoauthor(userID, article.ID)) {
+			panic("Failed to find the attachment")
+		}
+		if !canReadArticle(userID, article) {
+			panic("Subscribe to the author to access the attachment")
+		}
+	}
+
+	// Check if the file exists
+	filePath := filepath.Join(initializers.AttachmentDir, attachment.FileName)
+	if _, err := os.Stat(filePath); os.IsNotExist(err) {
+		panic("Attachment file not found")
+	}
+
+	// Return the file as a response, with the sniffed content type
+	// The images are displayed in the articles, the other files are downloaded
+	c.Header("Content-Type", attachment.MIME)
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.Header("Cache-Control", "private, max-age=3600")
+	if utils.IsInlineAttachment(attachment.MIME) {
+		c.File(filePath)
+	} else {
+		c.FileAttachment(filePath, attachment.Name)
+	}
+}
+
+// DelAttachment deletes an attachment of the current user, freeing its storage.
+func DelAttachment(c *gin.Context) {
+	defer func() {
+		if err := recover(); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err, "request_id": c.GetString(initializers.RequestIDKey)})
+			initializers.LOGGER.ErrorContext(c, "DelAttachment Failed", "error", err, "sub", utils.GetSubInfo(c), "params", utils.GetParsedQuery(c))
+		}
+	}()
+
+	// Get the user off the context
+	user, _ := c.Get("user")
+	userID := user.(models.User).ID
+
+	// Get the id off the query string
+	id, ok := c.GetQuery("id")
+	if !ok {
+		panic("Failed to get id off the query string")
+	}
+	attachmentID, err := strconv.Atoi(id)
+	if err != nil {
+		panic("Invalid id")
+	}
+
+	// Get the attachment of the user from the database
+	var attachment models.Attachment
+	result := initializers.DB.Where("id = ? AND owner_id = ?", uint(attachmentID), userID).Limit(1).Find(&attachment)
+	if result.RowsAffected == 0 {
+		panic("Failed to find the attachment")
+	}
+
+	// Delete the attachment from the database and its file from the server
+	if err := utils.RemoveAttachments(c, []models.Attachment{attachment}); err != nil {
+		panic(err.Error())
+	}
+
+	// [Prepare the object and data information for logging]
+	objInfo := utils.ObjInfo{
+		Op:    utils.OpDelete,
+		Table: models.Attachment{}.TableName(),
+		ID:    attachment.ID,
+	}
+	dataInfo := utils.DataInfo{
+		OldData: map[string]interface{}{
+			"article_id": attachment.ArticleID,
+			"file_name":  attachment.FileName,
+			"name":       attachment.Name,
+			"size":       attachment.Size,
+		},
+		NewData: nil,
+	}
+
+	// Return a success response
+	message := "Attachment deleted successfully"
+	c.JSON(http.StatusOK, gin.H{
+		"message": message,
+	})
+	utils.Audit(c, slog.LevelWarn, message, objInfo, dataInfo)
+}
+
+// SetUserQuota is an Admin API Endpoint that sets the storage quota of a user's attachments in MB.
+// A quota of 0 restores the default quota.
+func SetUserQuota(c *gin.Context) {
+	defer func() {
+		if err := recover(); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err, "request_id": c.GetString(initializers.RequestIDKey)})
+			initializers.LOGGER.ErrorContext(c, "SetUserQuota Failed", "error", err, "sub", utils.GetSubInfo(c), "params", c.MustGet("params"))
+		}
+	}()
+
+	// [Get the filtered parsed body and save it to the context]
+	rawBody := utils.GetRawBody(c)
+	parsedBody := utils.GetParsedBody(rawBody)
+	utils.BlurMap(parsedBody, "password")
+	c.Set("params", parsedBody)
+
+	// Get the user ID and the quota off the request
+	var body struct {
+		ID    uint  `json:"id" binding:"required"`
+		Quota *uint `json:"quota" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		panic("Both id and quota are required")
+	}
+
+	// Get the user from the database
+	var user models.User
+	result := initializers.DB.Select("id", "storage_quota").Where("id = ?", body.ID).Limit(1).Find(&user)
+	if result.RowsAffected == 0 {
+		panic("Failed to find the user")
+	}
+
+	// Update the quota in the database
+	result = initializers.DB.Model(&models.User{}).Where("id = ?", user.ID).Update("storage_quota", *body.Quota)
+	if result.Error != nil {
+		panic("Failed to update the quota in the database")
+	}
+
+	// [Prepare the object and data information for logging]
+	objInfo := utils.ObjInfo{
+		Op:    utils.OpUpdate,
+		Table: models.User{}.TableName(),
+		ID:    user.ID,
+	}
+	dataInfo := utils.DataInfo{
+		OldData: map[string]interface{}{
+			"storage_quota": user.StorageQuota,
+		},
+		NewData: map[string]interface{}{
+			"storage_quota": *body.Quota,
+		},
+	}
+
+	// Return a success response
+	message := "Storage quota updated successfully"
+	c.JSON(http.StatusOK, gin.H{
+		"message": message,
+	})
+	utils.Audit(c, slog.LevelInfo, message, objInfo, dataInfo)
+}
+
+// getStorageUsage returns the bytes used by the attachments of a user and the user's quota in bytes.
+func getStorageUsage(userID uint) (int64, int64) {
+	var user models.User
+	initializers.DB.Select("id", "storage_quota").Where("id = ?", userID).Limit(1).Find(&user)
+	quota := int64(initializers.AttachmentQuotaMB) << 20
+	if user.StorageQuota > 0 {
+		quota = int64(user.StorageQuota) << 20
+	}
+
+	var used int64
+	initializers.DB.Model(&models.Attachment{}).Select("COALESCE(SUM(size), 0)").Where("owner_id = ?", userID).Scan(&used)
+	return used, quota
+}
+
+// bindAttachments binds to an article the unbound attachments of its author and co-authors that its body links.
+func bindAttachments(tx *gorm.DB, article *models.Article) error {
+	ids := utils.ParseAttachmentLinks(article.Body)
+	if len(ids) == 0 {
+		return nil
+	}
+	ownerIDs := append([]uint{article.AuthorID}, getCoauthorIDs([]uint{article.ID})[article.ID]...)
+	result := tx.Model(&models.Attachment{}).
+		Where("id IN (?) AND owner_id IN (?) AND article_id IS NULL", ids, ownerIDs).
+		Update("article_id", article.ID)
+	if result.Error != nil {
+		return errors.New("failed to bind the attachments to the article")
+	}
+	return nil
+}
+
+// attachmentURL returns the link of an attachment, which binds it to the article whose body contains it.
+func attachmentURL(attachmentID uint) string {
+	return "/api/ui/attachments/" + strconv.Itoa(int(attachmentID))
+}
+
+// attachmentMarkdown returns the Markdown of an attachment: an inline image, or a link to download the file.
+func attachmentMarkdown(attachment models.Attachment) string {
+	name := strings.NewReplacer("[", "", "]", "", "\n", " ").Replace(attachment.Name)
+	link := "[" + name + "](" + attachmentURL(attachment.ID) + ")"
+	if utils.IsInlineAttachment(attachment.MIME) {
+		return "!" + link
+	}
+	return link
+}
@@ -4,12 +4,14 @@ import (
 	"auth/initializers"
 	"auth/models"
 	"auth/utils"
+	"log/slog"
 	"net/http"
 	"os"
 	"path/filepath"
 	"slices"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
@@ -18,7 +20,7 @@ import (
 func GetUsers(c *gin.Context) {
 	defer func() {
 		if err := recover(); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err})
+			c.JSON(http.StatusBadRequest, gin.H{"error": err, "request_id": c.GetString(initializers.RequestIDKey)})
 		}
 	}()
 
@@ -54,8 +56,8 @@ func GetUsers(c *gin.Context) {
 func DelUser(c *gin.Context) {
 	defer func() {
 		if err := recover(); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err})
-			initializers.LOGGER.Error("DelUser Failed", "error", err, "sub", utils.GetSubInfo(c), "params", utils.GetParsedQuery(c))
+			c.JSON(http.StatusBadRequest, gin.H{"error": err, "request_id": c.GetString(initializers.RequestIDKey)})
+			initializers.LOGGER.ErrorContext(c, "DelUser Failed", "error", err, "sub", utils.GetSubInfo(c), "params", utils.GetParsedQuery(c))
 		}
 	}()
 
@@ -88,18 +90,36 @@ func DelUser(c *gin.Context) {
 		panic("Failed to remove user from Redis cache")
 	}
 
-	// Delete the user's articles from the database
-	result = initializers.DB.Where("user_id = ?", uint(userID)).Delete(&models.Article{})
+	// Delete the user's articles from the database, along with the comments on them
+	var articleIDs []uint
+	initializers.DB.Model(&models.Article{}).Where("author_id = ?", uint(userID)).Pluck("id", &articleIDs)
+	result = initializers.DB.Where("author_id = ?", uint(userID)).Delete(&models.Article{})
 	if result.Error != nil {
 		panic("Failed to delete user's articles from the database")
 	}
+	if len(articleIDs) > 0 {
+		result = initializers.DB.Where("article_id IN (?)", articleIDs).Delete(&models.Comment{})
+		if result.Error != nil {
+			panic("Failed to delete the comments on user's articles from the database")
+		}
+	}
 
 	// Delete the user's comments from the database
-	result = initializers.DB.Where("user_id = ?", uint(userID)).Delete(&models.Comment{})
+	var commentIDs []uint
+	initializers.DB.Model(&models.Comment{}).Where("author_id = ?", uint(userID)).Pluck("id", &commentIDs)
+	result = initializers.DB.Where("author_id = ?", uint(userID)).Delete(&models.Comment{})
 	if result.Error != nil {
 		panic("Failed to delete user's comments from the database")
 	}
 
+	// Update the search index
+	for _, articleID := range articleIDs {
+		utils.IndexArticle(c, articleID)
+	}
+	for _, commentID := range commentIDs {
+		utils.IndexComment(c, commentID)
+	}
+
 	// Delete the user from Casbin
 	initializers.E.RemoveFilteredPolicy(0, tmp["email"].(string))
 	initializers.E.RemoveFilteredGroupingPolicy(0, tmp["email"].(string))
@@ -131,14 +151,14 @@ func DelUser(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{
 		"message": message,
 	})
-	initializers.LOGGER.Warn(message, "sub", utils.GetSubInfo(c), "obj", objInfo, "data", dataInfo)
+	utils.Audit(c, slog.LevelWarn, message, objInfo, dataInfo)
 }
 
 // GetDenials is an Admin API Endpoint that retrieves all denials in Casbin.
 func GetDenials(c *gin.Context) {
 	defer func() {
 		if err := recover(); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err})
+			c.JSON(http.StatusBadRequest, gin.H{"error": err, "request_id": c.GetString(initializers.RequestIDKey)})
 		}
 	}()
 
@@ -172,8 +192,8 @@ func GetDenials(c *gin.Context) {
 func AddDenial(c *gin.Context) {
 	defer func() {
 		if err := recover(); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err})
-			initializers.LOGGER.Error("AddDenial Failed", "error", err, "sub", utils.GetSubInfo(c), "params", c.MustGet("params"))
+			c.JSON(http.StatusBadRequest, gin.H{"error": err, "request_id": c.GetString(initializers.RequestIDKey)})
+			initializers.LOGGER.ErrorContext(c, "AddDenial Failed", "error", err, "sub", utils.GetSubInfo(c), "params", c.MustGet("params"))
 		}
 	}()
 
@@ -215,20 +235,34 @@ func AddDenial(c *gin.Context) {
 		panic("Failed to add denial in Casbin, which may already exist")
 	}
 
+	// [Prepare the object and data information for logging]
+	objInfo := utils.ObjInfo{
+		Op:    utils.OpCreate,
+		Table: "casbin_rule",
+	}
+	dataInfo := utils.DataInfo{
+		OldData: nil,
+		NewData: map[string]interface{}{
+			"email":  body.Email,
+			"path":   path,
+			"method": body.Method,
+		},
+	}
+
 	// Return a success response
 	message := "Denial added successfully"
 	c.JSON(http.StatusOK, gin.H{
 		"message": message,
 	})
-	initializers.LOGGER.Warn(message, "sub", utils.GetSubInfo(c), "rule", body)
+	utils.Audit(c, slog.LevelWarn, message, objInfo, dataInfo)
 }
 
 // DelDenial is an Admin API Endpoint that deletes a denial in Casbin.
 func DelDenial(c *gin.Context) {
 	defer func() {
 		if err := recover(); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err})
-			initializers.LOGGER.Error("DelDenial Failed", "error", err, "sub", utils.GetSubInfo(c), "params", utils.GetParsedQuery(c))
+			c.JSON(http.StatusBadRequest, gin.H{"error": err, "request_id": c.GetString(initializers.RequestIDKey)})
+			initializers.LOGGER.ErrorContext(c, "DelDenial Failed", "error", err, "sub", utils.GetSubInfo(c), "params", utils.GetParsedQuery(c))
 		}
 	}()
 
@@ -246,23 +280,300 @@ func DelDenial(c *gin.Context) {
 		panic("Failed to delete denial in Casbin, which may not exist")
 	}
 
+	// [Prepare the object and data information for logging]
+	objInfo := utils.ObjInfo{
+		Op:    utils.OpDelete,
+		Table: "casbin_rule",
+	}
+	dataInfo := utils.DataInfo{
+		OldData: map[string]interface{}{
+			"email":  email,
+			"path":   path,
+			"method": method,
+		},
+		NewData: nil,
+	}
+
 	// Return a success response
 	message := "Denial deleted successfully"
 	c.JSON(http.StatusOK, gin.H{
 		"message": message,
 	})
-	initializers.LOGGER.Warn(message, "sub", utils.GetSubInfo(c), "rule", map[string]interface{}{
-		"email":  email,
-		"path":   path,
-		"method": method,
+	utils.Audit(c, slog.LevelWarn, message, objInfo, dataInfo)
+}
+
+// GetAuditRoutes is an Admin API Endpoint that retrieves all audited read routes.
+func GetAuditRoutes(c *gin.Context) {
+	defer func() {
+		if err := recover(); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err, "request_id": c.GetString(initializers.RequestIDKey)})
+		}
+	}()
+
+	// Get the pagination parameters
+	params := utils.GetPaginationParams(c)
+
+	// Get the total number of audit routes
+	var total int64
+	result := initializers.DB.Model(&models.AuditRoute{}).Count(&total)
+	if result.Error != nil {
+		panic("Failed to get the total number of audit routes")
+	}
+
+	// Get the audit routes from the database
+	var routes []models.AuditRoute
+	result = initializers.DB.Order("path").Offset(params.Offset).Limit(params.PageSize).Find(&routes)
+	if result.Error != nil {
+		panic("Failed to get the audit routes from the database")
+	}
+
+	// Get the pagination result
+	pagination := utils.GetPaginationResult(params, len(routes), total)
+
+	// Return a success response with the audit routes
+	c.JSON(http.StatusOK, gin.H{
+		"message":    "Audit routes retrieved successfully",
+		"routes":     routes,
+		"pagination": pagination,
+	})
+}
+
+// AddAuditRoute is an Admin API Endpoint that adds a read route to be audited.
+// Mutating routes are always audited, so only GET routes can be added.
+func AddAuditRoute(c *gin.Context) {
+	defer func() {
+		if err := recover(); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err, "request_id": c.GetString(initializers.RequestIDKey)})
+			initializers.LOGGER.ErrorContext(c, "AddAuditRoute Failed", "error", err, "sub", utils.GetSubInfo(c), "params", c.MustGet("params"))
+		}
+	}()
+
+	// [Get the filtered parsed body and save it to the context]
+	rawBody := utils.GetRawBody(c)
+	parsedBody := utils.GetParsedBody(rawBody)
+	utils.BlurMap(parsedBody, "password")
+	c.Set("params", parsedBody)
+
+	// Get the method and path off the request body
+	var body struct {
+		Method string `json:"method" binding:"required"`
+		Path   string `json:"path" binding:"required"`
+	}
+	if err := c.ShouldBind(&body); err != nil {
+		panic("Failed to get method and path off the request body")
+	}
+
+	// Check if the method and path are valid
+	if body.Method != http.MethodGet {
+		panic("Invalid method: Only GET routes can be configured")
+	}
+	if !strings.HasPrefix(body.Path, "/api/ui/") && !strings.HasPrefix(body.Path, "/api/bg/") {
+		panic("Invalid path")
+	}
+
+	// Add the audit route in the database
+	route := models.AuditRoute{Method: body.Method, Path: body.Path}
+	result := initializers.DB.Create(&route)
+	if result.Error != nil {
+		panic("Failed to add audit route, which may already exist")
+	}
+	initializers.LoadAuditRoutes()
+
+	// [Prepare the object and data information for logging]
+	objInfo := utils.ObjInfo{
+		Op:    utils.OpCreate,
+		Table: models.AuditRoute{}.TableName(),
+		ID:    route.ID,
+	}
+	dataInfo := utils.DataInfo{
+		OldData: nil,
+		NewData: map[string]interface{}{
+			"method": route.Method,
+			"path":   route.Path,
+		},
+	}
+
+	// Return a success response
+	message := "Audit route added successfully"
+	c.JSON(http.StatusOK, gin.H{
+		"message": message,
+	})
+	utils.Audit(c, slog.LevelWarn, message, objInfo, dataInfo)
+}
+
+// DelAuditRoute is an Admin API Endpoint that stops auditing a read route.
+func DelAuditRoute(c *gin.Context) {
+	defer func() {
+		if err := recover(); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err, "request_id": c.GetString(initializers.RequestIDKey)})
+			initializers.LOGGER.ErrorContext(c, "DelAuditRoute Failed", "error", err, "sub", utils.GetSubInfo(c), "params", utils.GetParsedQuery(c))
+		}
+	}()
+
+	// Get the method and path off the query string
+	method, ok1 := c.GetQuery("method")
+	path, ok2 := c.GetQuery("path")
+	if !(ok1 && ok2) {
+		panic("Failed to get method and path off the query string")
+	}
+
+	// [Get the audit route from the database]
+	var route models.AuditRoute
+	initializers.DB.Where("method = ? AND path = ?", method, path).First(&route)
+
+	// Delete the audit route in the database
+	result := initializers.DB.Where("method = ? AND path = ?", method, path).Delete(&models.AuditRoute{})
+	if result.Error != nil || result.RowsAffected == 0 {
+		panic("Failed to delete audit route, which may not exist")
+	}
+	initializers.LoadAuditRoutes()
+
+	// [Prepare the object and data information for logging]
+	objInfo := utils.ObjInfo{
+		Op:    utils.OpDelete,
+		Table: models.AuditRoute{}.TableName(),
+		ID:    route.ID,
+	}
+	dataInfo := utils.DataInfo{
+		OldData: map[string]interface{}{
+			"method": method,
+			"path":   path,
+		},
+		NewData: nil,
+	}
+
+	// Return a success response
+	message := "Audit route deleted successfully"
+	c.JSON(http.StatusOK, gin.H{
+		"message": message,
+	})
+	utils.Audit(c, slog.LevelWarn, message, objInfo, dataInfo)
+}
+
+// GetAlerts is an Admin API Endpoint that retrieves the security alerts raised by the anomaly detector.
+// The alerts can be filtered by status with the "status" query parameter.
+func GetAlerts(c *gin.Context) {
+	defer func() {
+		if err := recover(); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err, "request_id": c.GetString(initializers.RequestIDKey)})
+		}
+	}()
+
+	// Get the pagination parameters
+	params := utils.GetPaginationParams(c)
+
+	// Prepare the query with the optional status filter
+	query := initializers.DB.Model(&models.Alert{})
+	if status, ok := c.GetQuery("status"); ok {
+		query = query.Where("status = ?", utils.StrToUint(status))
+	}
+
+	// Get the total number of alerts
+	var total int64
+	result := query.Count(&total)
+	if result.Error != nil {
+		panic("Failed to get the total number of alerts")
+	}
+
+	// Get the alerts from the database
+	var alerts []models.Alert
+	result = query.Order("created_at DESC").Offset(params.Offset).Limit(params.PageSize).Find(&alerts)
+	if result.Error != nil {
+		panic("Failed to get the alerts from the database")
+	}
+
+	// Get the pagination result
+	pagination := utils.GetPaginationResult(params, len(alerts), total)
+
+	// Return a success response with the alerts
+	c.JSON(http.StatusOK, gin.H{
+		"message":    "Alerts retrieved successfully",
+		"alerts":     alerts,
+		"pagination": pagination,
+	})
+}
+
+// SetAlertStatus is an Admin API Endpoint that sets the status of a security alert.
+// Resolving an alert also lifts the temporary denial applied with it.
+func SetAlertStatus(c *gin.Context) {
+	defer func() {
+		if err := recover(); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err, "request_id": c.GetString(initializers.RequestIDKey)})
+			initializers.LOGGER.ErrorContext(c, "SetAlertStatus Failed", "error", err, "sub", utils.GetSubInfo(c), "params", c.MustGet("params"))
+		}
+	}()
+
+	// [Get the filtered parsed body and save it to the context]
+	rawBody := utils.GetRawBody(c)
+	parsedBody := utils.GetParsedBody(rawBody)
+	utils.BlurMap(parsedBody, "password")
+	c.Set("params", parsedBody)
+
+	// Get the alert ID and status off the request
+	var body struct {
+		ID     uint `json:"id" binding:"required"`
+		Status uint `json:"status" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		panic("Both id and status are required")
+	}
+
+	// Validate the status
+	if body.Status != models.AlertAcknowledged && body.Status != models.AlertResolved {
+		panic("Invalid status: Must be Acknowledged(1) or Resolved(2)")
+	}
+
+	// Get the alert from the database
+	var alert models.Alert
+	result := initializers.DB.First(&alert, body.ID)
+	if result.Error != nil {
+		panic("Failed to find the alert")
+	}
+	status := alert.Status
+
+	// Set the status of the alert in the database
+	result = initializers.DB.Model(&alert).Update("status", body.Status)
+	if result.Error != nil {
+		panic("Failed to set the status of the alert")
+	}
+
+	// Lift the temporary denial if it is still in effect
+	if body.Status == models.AlertResolved && alert.DeniedUntil != nil && alert.DeniedUntil.After(time.Now()) {
+		for _, rule := range []utils.AnomalyRule{utils.AnomalyRules.FORBIDDEN_BURST, utils.AnomalyRules.LOGIN_MANY_IPS, utils.AnomalyRules.MASS_DELETION} {
+			if rule.Name == alert.Rule {
+				utils.LiftDenial(utils.DenialMember(alert.Subject, rule.DenyPath, rule.DenyMethod))
+			}
+		}
+	}
+
+	// [Prepare the object and data information for logging]
+	objInfo := utils.ObjInfo{
+		Op:    utils.OpUpdate,
+		Table: models.Alert{}.TableName(),
+		ID:    alert.ID,
+	}
+	dataInfo := utils.DataInfo{
+		OldData: map[string]interface{}{
+			"status": status,
+		},
+		NewData: map[string]interface{}{
+			"status": body.Status,
+		},
+	}
+
+	// Return a success response
+	message := "Alert Status set successfully"
+	c.JSON(http.StatusOK, gin.H{
+		"message": message,
 	})
+	utils.Audit(c, slog.LevelInfo, message, objInfo, dataInfo)
 }
 
 // DownloadLogFile is an Admin API Endpoint that downloads the log file.
 func DownloadLogFile(c *gin.Context) {
 	defer func() {
 		if err := recover(); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err})
+			c.JSON(http.StatusBadRequest, gin.H{"error": err, "request_id": c.GetString(initializers.RequestIDKey)})
 		}
 	}()
 
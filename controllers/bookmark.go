@@ -0,0 +1,698 @@
+package controllers
+
+import (
+	"auth/initializers"
+	"auth/models"
+	"auth/utils"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// maxReadingLists is the maximum number of reading lists of a user.
+const maxReadingLists = 50
+
+// maxReadingListItems is the maximum number of articles in a reading list.
+const maxReadingListItems = 500
+
+// FetchBookmarks retrieves the articles bookmarked by the current user, the latest bookmark first.
+func FetchBookmarks(c *gin.Context) {
+	defer func() {
+		if err := recover(); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err, "request_id": c.GetString(initializers.RequestIDKey)})
+		}
+	}()
+
+	// Get the user off the context
+	user, _ := c.Get("user")
+	userID := user.(models.User).ID
+
+	// Get the bookmarked articles the user may still see
+	query := initializers.DB.Model(&models.Bookmark{}).
+		Joins("JOIN articles ON bookmarks.article_id = articles.id AND articles.deleted_at IS NULL").
+		Where("bookmarks.user_id = ?", userID)
+	articles, pagination := listSavedArticles(c, userID, query, "bookmarks.created_at")
+
+	// Return a success response with the bookmarked articles
+	c.JSON(http.StatusOK, gin.H{
+		"message":    "Bookmarks retrieved successfully",
+		"articles":   articles,
+		"pagination": pagination,
+	})
+}
+
+// PostBookmark bookmarks an article for the current user.
+func PostBookmark(c *gin.Context) {
+	defer func() {
+		if err := recover(); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err, "request_id": c.GetString(initializers.RequestIDKey)})
+			initializers.LOGGER.ErrorContext(c, "PostBookmark Failed", "error", err, "sub", utils.GetSubInfo(c), "params", c.MustGet("params"))
+		}
+	}()
+
+	// [Get the filtered parsed body and save it to the context]
+	rawBody := utils.GetRawBody(c)
+	parsedBody := utils.GetParsedBody(rawBody)
+	utils.BlurMap(parsedBody, "password")
+	c.Set("params", parsedBody)
+
+	// Get the user off the context
+	user, _ := c.Get("user")
+	userID := user.(models.User).ID
+
+	// Get the article ID off the request
+	var body struct {
+		ArticleID uint `json:"article_id" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		panic("Article ID is required")
+	}
+	if !canSaveArticle(userID, body.ArticleID) {
+		panic("Failed to find the article")
+	}
+
+	// Create the bookmark in the database, bookmarking an article twice is a no-op
+	bookmark := models.Bookmark{UserID: userID, ArticleID: body.ArticleID}
+	result := initializers.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&bookmark)
+	if result.Error != nil {
+		panic("Failed to bookmark the article")
+	}
+	utils.AddBookmarks(c, userID, body.ArticleID)
+
+	// [Prepare the object and data information for logging]
+	objInfo := utils.ObjInfo{
+		Op:    utils.OpCreate,
+		Table: models.Bookmark{}.TableName(),
+		ID:    bookmark.ID,
+	}
+	dataInfo := utils.DataInfo{
+		OldData: nil,
+		NewData: map[string]interface{}{
+			"article_id": body.ArticleID,
+		},
+	}
+
+	// Return a success response
+	message := "Article bookmarked successfully"
+	c.JSON(http.StatusOK, gin.H{
+		"message": message,
+	})
+	utils.Audit(c, slog.LevelInfo, message, objInfo, dataInfo)
+}
+
+// DelBookmark removes the bookmark of an article for the current user.
+func DelBookmark(c *gin.Context) {
+	defer func() {
+		if err := recover(); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err, "request_id": c.GetString(initializers.RequestIDKey)})
+			initializers.LOGGER.ErrorContext(c, "DelBookmark Failed", "error", err, "sub", utils.GetSubInfo(c), "params", utils.GetParsedQuery(c))
+		}
+	}()
+
+	// Get the user off the context
+	user, _ := c.Get("user")
+	userID := user.(models.User).ID
+
+	// Get the article ID off the query string
+	id, ok := c.GetQuery("article_id")
+	if !ok {
+		panic("Failed to get article_id off the query string")
+	}
+	articleID, err := strconv.Atoi(id)
+	if err != nil {
+		panic("Invalid article_id")
+	}
+
+	// Delete the bookmark from the database
+	var bookmark models.Bookmark
+	result := initializers.DB.Where("user_id = ? AND article_id = ?", userID, uint(articleID)).Limit(1).Find(&bookmark)
+	if result.RowsAffected == 0 {
+		panic("Failed to find the bookmark")
+	}
+	result = initializers.DB.Delete(&bookmark)
+	if result.Error != nil {
+		panic("Failed to delete the bookmark")
+	}
+	utils.RemoveBookmarks(c, userID, uint(articleID))
+
+	// [Prepare the object and data information for logging]
+	objInfo := utils.ObjInfo{
+		Op:    utils.OpDelete,
+		Table: models.Bookmark{}.TableName(),
+		ID:    bookmark.ID,
+	}
+	dataInfo := utils.DataInfo{
+		OldData: map[string]interface{}{
+			"article_id": bookmark.ArticleID,
+		},
+		NewData: nil,
+	}
+
+	// Return a success response
+	message := "Bookmark removed successfully"
+	c.JSON(http.StatusOK, gin.H{
+		"message": message,
+	})
+	utils.Audit(c, slog.LevelInfo, message, objInfo, dataInfo)
+}
+
+// FetchReadingLists retrieves the reading lists of a user ("user_id", the current user by default), with their numbers of articles.
+// Only the public lists of the other users are retrieved.
+func FetchReadingLists(c *gin.Context) {
+	defer func() {
+		if err := recover(); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err, "request_id": c.GetString(initializers.RequestIDKey)})
+		}
+	}()
+
+	// Get the user off the context
+	user, _ := c.Get("user")
+	userID := user.(models.User).ID
+
+	// Get the owner of the lists off the query string
+	ownerID := userID
+	if id, ok := c.GetQuery("user_id"); ok {
+		ownerID = utils.StrToUint(id)
+	}
+
+	// Get the reading lists from the database
+	query := initializers.DB.Model(&models.ReadingList{}).Where("user_id = ?", ownerID)
+	if ownerID != userID {
+		query = query.Where("public = ?", true)
+	}
+	lists := []map[string]interface{}{}
+	result := query.Select("id", "user_id", "name", "description", "public", "created_at", "updated_at").Order("created_at DESC").Find(&lists)
+	if result.Error != nil {
+		panic("Failed to get the reading lists from the database")
+	}
+
+	// Count the articles of the lists
+	var listIDs []uint
+	for _, list := range lists {
+		listIDs = append(listIDs, list["id"].(uint))
+	}
+	var counts []struct {
+		ListID uint
+		Count  int64
+	}
+	if len(listIDs) > 0 {
+		initializers.DB.Model(&models.ReadingListItem{}).
+			Select("list_id", "COUNT(*) AS count").
+			Where("list_id IN (?)", listIDs).
+			Group("list_id").Scan(&counts)
+	}
+	countsByListID := make(map[uint]int64, len(counts))
+	for _, count := range counts {
+		countsByListID[count.ListID] = count.Count
+	}
+	for i := range lists {
+		lists[i]["articles"] = countsByListID[lists[i]["id"].(uint)]
+	}
+
+	// Return a success response with the reading lists
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Reading lists retrieved successfully",
+		"lists":   lists,
+	})
+}
+
+// FetchReadingList retrieves a reading list with its articles, the latest added first.
+// A private list is only retrieved by its owner.
+func FetchReadingList(c *gin.Context) {
+	defer func() {
+		if err := recover(); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err, "request_id": c.GetString(initializers.RequestIDKey)})
+		}
+	}()
+
+	// Get the user off the context
+	user, _ := c.Get("user")
+	userID := user.(models.User).ID
+
+	// Get the id off the path parameter
+	id, ok := c.Params.Get("id")
+	if !ok {
+		panic("ID is required")
+	}
+	listID, err := strconv.Atoi(id)
+	if err != nil {
+		panic("Invalid ID: Type error")
+	}
+
+	// Get the reading list from the database
+	var list models.ReadingList
+	result := initializers.DB.Where("id = ?", uint(listID)).Limit(1).Find(&list)
+	if result.RowsAffected == 0 || (list.UserID != userID && !list.Public) {
+		panic("Failed to find the reading list")
+	}
+
+	// Get the articles of the list the user may see
+	query := initializers.DB.Model(&models.ReadingListItem{}).
+		Joins("JOIN articles ON reading_list_items.article_id = articles.id AND articles.deleted_at IS NULL").
+		Where("reading_list_items.list_id = ?", list.ID)
+	articles, pagination := listSavedArticles(c, userID, query, "reading_list_items.created_at")
+
+	// Return a success response with the reading list
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Reading list retrieved successfully",
+		"list": gin.H{
+			"id":          list.ID,
+			"user_id":     list.UserID,
+			"name":        list.Name,
+			"description": list.Description,
+			"public":      list.Public,
+			"created_at":  list.CreatedAt,
+			"updated_at":  list.UpdatedAt,
+		},
+		"articles":   articles,
+		"pagination": pagination,
+	})
+}
+
+// PostReadingList creates a reading list of the current user, private by default.
+func PostReadingList(c *gin.Context) {
+	defer func() {
+		if err := recover(); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err, "request_id": c.GetString(initializers.RequestIDKey)})
+			initializers.LOGGER.ErrorContext(c, "PostReadingList Failed", "error", err, "sub", utils.GetSubInfo(c), "params", c.MustGet("params"))
+		}
+	}()
+
+	// [Get the filtered parsed body and save it to the context]
+	rawBody := utils.GetRawBody(c)
+	parsedBody := utils.GetParsedBody(rawBody)
+	utils.BlurMap(parsedBody, "password")
+	c.Set("params", parsedBody)
+
+	// Get the user off the context
+	user, _ := c.Get("user")
+	userID := user.(models.User).ID
+
+	// Get the name, description and visibility off the request
+	var body struct {
+		Name        string `json:"name" binding:"required"`
+		Description string `json:"description"`
+		Public      bool   `json:"public"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		panic("Name is required")
+	}
+	name := normalizeListName(body.Name)
+
+	// Check the number of lists of the user
+	var count int64
+	initializers.DB.Model(&models.ReadingList{}).Where("user_id = ?", userID).Count(&count)
+	if count >= maxReadingLists {
+		panic("Too many reading lists: Must be at most " + strconv.Itoa(maxReadingLists))
+	}
+
+	// Create the reading list in the database
+	list := models.ReadingList{
+		UserID:      userID,
+		Name:        name,
+		Description: body.Description,
+		Public:      body.Public,
+	}
+	result := initializers.DB.Create(&list)
+	if result.Error != nil {
+		panic("Failed to create the reading list")
+	}
+
+	// [Prepare the object and data information for logging]
+	objInfo := utils.ObjInfo{
+		Op:    utils.OpCreate,
+		Table: models.ReadingList{}.TableName(),
+		ID:    list.ID,
+	}
+	dataInfo := utils.DataInfo{
+		OldData: nil,
+		NewData: map[string]interface{}{
+			"name":        list.Name,
+			"description": list.Description,
+			"public":      list.Public,
+		},
+	}
+
+	// Return a success response with the reading list
+	message := "Reading list created successfully"
+	c.JSON(http.StatusOK, gin.H{
+		"message": message,
+		"id":      list.ID,
+	})
+	utils.Audit(c, slog.LevelInfo, message, objInfo, dataInfo)
+}
+
+// EditReadingList edits the name, description and visibility of a reading list of the current user.
+func EditReadingList(c *gin.Context) {
+	defer func() {
+		if err := recover(); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err, "request_id": c.GetString(initializers.RequestIDKey)})
+			initializers.LOGGER.ErrorContext(c, "EditReadingList Failed", "error", err, "sub", utils.GetSubInfo(c), "params", c.MustGet("params"))
+		}
+	}()
+
+	// [Get the filtered parsed body and save it to the context]
+	rawBody := utils.GetRawBody(c)
+	parsedBody := utils.GetParsedBody(rawBody)
+	utils.BlurMap(parsedBody, "password")
+	c.Set("params", parsedBody)
+
+	// Get the user off the context
+	user, _ := c.Get("user")
+	userID := user.(models.User).ID
+
+	// Get the list ID, name, description and visibility off the request
+	var body struct {
+		ID          uint   `json:"id" binding:"required"`
+		Name        string `json:"name" binding:"required"`
+		Description string `json:"description"`
+		Public      bool   `json:"public"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		panic("Both id and name are required")
+	}
+	name := normalizeListName(body.Name)
+
+	// Get the reading list of the user from the database
+	var list models.ReadingList
+	result := initializers.DB.Where("id = ? AND user_id = ?", body.ID, userID).Limit(1).Find(&list)
+	if result.RowsAffected == 0 {
+		panic("Failed to find the reading list")
+	}
+	oldList := list
+
+	// Update the reading list in the database
+	result = initializers.DB.Model(&models.ReadingList{}).Where("id = ?", list.ID).Updates(map[string]interface{}{
+		"name":        name,
+		"description": body.Description,
+		"public":      body.Public,
+	})
+	if result.Error != nil {
+		panic("Failed to update the reading list")
+	}
+
+	// [Prepare the object and data information for logging]
+	objInfo := utils.ObjInfo{
+		Op:    utils.OpUpdate,
+		Table: models.ReadingList{}.TableName(),
+		ID:    list.ID,
+	}
+	dataInfo := utils.DataInfo{
+		OldData: map[string]interface{}{
+			"name":        oldList.Name,
+			"description": oldList.Description,
+			"public":      oldList.Public,
+		},
+		NewData: map[string]interface{}{
+			"name":        name,
+			"description": body.Description,
+			"public":      body.Public,
+		},
+	}
+
+	// Return a success response
+	message := "Reading list edited successfully"
+	c.JSON(http.StatusOK, gin.H{
+		"message": message,
+	})
+	utils.Audit(c, slog.LevelInfo, message, objInfo, dataInfo)
+}
+
+// DelReadingList deletes a reading list of the current user, along with its items.
+func DelReadingList(c *gin.Context) {
+	defer func() {
+		if err := recover(); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err, "request_id": c.GetString(initializers.RequestIDKey)})
+			initializers.LOGGER.ErrorContext(c, "DelReadingList Failed", "error", err, "sub", utils.GetSubInfo(c), "params", utils.GetParsedQuery(c))
+		}
+	}()
+
+	// Get the user off the context
+	user, _ := c.Get("user")
+	userID := user.(models.User).ID
+
+	// Get the id off the query string
+	id, ok := c.GetQuery("id")
+	if !ok {
+		panic("Failed to get id off the query string")
+	}
+	listID, err := strconv.Atoi(id)
+	if err != nil {
+		panic("Invalid id")
+	}
+
+	// Get the reading list of the user from the database
+	var list models.ReadingList
+	result := initializers.DB.Where("id = ? AND user_id = ?", uint(listID), userID).Limit(1).Find(&list)
+	if result.RowsAffected == 0 {
+		panic("Failed to find the reading list")
+	}
+
+	// Delete the reading list and its items
+	err = initializers.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("list_id = ?", list.ID).Delete(&models.ReadingListItem{}).Error; err != nil {
+			return errors.New("failed to delete the items of the reading list")
+		}
+		if err := tx.Delete(&list).Error; err != nil {
+			return errors.New("failed to delete the reading list")
+		}
+		return nil
+	})
+	if err != nil {
+		panic(err.Error())
+	}
+
+	// [Prepare the object and data information for logging]
+	objInfo := utils.ObjInfo{
+		Op:    utils.OpDelete,
+		Table: models.ReadingList{}.TableName(),
+		ID:    list.ID,
+	}
+	dataInfo := utils.DataInfo{
+		OldData: map[string]interface{}{
+			"name":        list.Name,
+			"description": list.Description,
+			"public":      list.Public,
+		},
+		NewData: nil,
+	}
+
+	// Return a success response
+	message := "Reading list deleted successfully"
+	c.JSON(http.StatusOK, gin.H{
+		"message": message,
+	})
+	utils.Audit(c, slog.LevelWarn, message, objInfo, dataInfo)
+}
+
+// PostReadingListItem adds an article to a reading list of the current user.
+func PostReadingListItem(c *gin.Context) {
+	defer func() {
+		if err := recover(); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err, "request_id": c.GetString(initializers.RequestIDKey)})
+			initializers.LOGGER.ErrorContext(c, "PostReadingListItem Failed", "error", err, "sub", utils.GetSubInfo(c), "params", c.MustGet("params"))
+		}
+	}()
+
+	// [Get the filtered parsed body and save it to the context]
+	rawBody := utils.GetRawBody(c)
+	parsedBody := utils.GetParsedBody(rawBody)
+	utils.BlurMap(parsedBody, "password")
+	c.Set("params", parsedBody)
+
+	// Get the user off the context
+	user, _ := c.Get("user")
+	userID := user.(models.User).ID
+
+	// Get the list ID and the article ID off the request
+	var body struct {
+		ListID    uint `json:"list_id" binding:"required"`
+		ArticleID uint `json:"article_id" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		panic("Both list_id and article_id are required")
+	}
+
+	// Get the reading list of the user from the database
+	var list models.ReadingList
+	result := initializers.DB.Where("id = ? AND user_id = ?", body.ListID, userID).Limit(1).Find(&list)
+	if result.RowsAffected == 0 {
+		panic("Failed to find the reading list")
+	}
+	if !canSaveArticle(userID, body.ArticleID) {
+		panic("Failed to find the article")
+	}
+
+	// Check the number of articles of the list
+	var count int64
+	initializers.DB.Model(&models.ReadingListItem{}).Where("list_id = ?", list.ID).Count(&count)
+	if count >= maxReadingListItems {
+		panic("Too many articles in the reading list: Must be at most " + strconv.Itoa(maxReadingListItems))
+	}
+
+	// Add the article to the list, adding it twice is a no-op
+	item := models.ReadingListItem{ListID: list.ID, ArticleID: body.ArticleID}
+	result = initializers.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&item)
+	if result.Error != nil {
+		panic("Failed to add the article to the reading list")
+	}
+
+	// [Prepare the object and data information for logging]
+	objInfo := utils.ObjInfo{
+		Op:    utils.OpCreate,
+		Table: models.ReadingListItem{}.TableName(),
+		ID:    item.ID,
+	}
+	dataInfo := utils.DataInfo{
+		OldData: nil,
+		NewData: map[string]interface{}{
+			"list_id":    list.ID,
+			"article_id": body.ArticleID,
+		},
+	}
+
+	// Return a success response
+	message := "Article added to the reading list successfully"
+	c.JSON(http.StatusOK, gin.H{
+		"message": message,
+	})
+	utils.Audit(c, slog.LevelInfo, message, objInfo, dataInfo)
+}
+
+// DelReadingListItem removes an article from a reading list of the current user.
+func DelReadingListItem(c *gin.Context) {
+	defer func() {
+		if err := recover(); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err, "request_id": c.GetString(initializers.RequestIDKey)})
+			initializers.LOGGER.ErrorContext(c, "DelReadingListItem Failed", "error", err, "sub", utils.GetSubInfo(c), "params", utils.GetParsedQuery(c))
+		}
+	}()
+
+	// Get the user off the context
+	user, _ := c.Get("user")
+	userID := user.(models.User).ID
+
+	// Get the list ID and the article ID off the query string
+	listID := utils.StrToUint(c.Query("list_id"))
+	articleID := utils.StrToUint(c.Query("article_id"))
+	if listID == 0 || articleID == 0 {
+		panic("Both list_id and article_id are required")
+	}
+
+	// Get the reading list of the user from the database
+	var list models.ReadingList
+	result := initializers.DB.Where("id = ? AND user_id = ?", listID, userID).Limit(1).Find(&list)
+	if result.RowsAffected == 0 {
+		panic("Failed to find the reading list")
+	}
+
+	// Remove the article from the list
+	var item models.ReadingListItem
+	result = initializers.DB.Where("list_id = ? AND article_id = ?", list.ID, articleID).Limit(1).Find(&item)
+	if result.RowsAffected == 0 {
+		panic("Failed to find the article in the reading list")
+	}
+	result = initializers.DB.Delete(&item)
+	if result.Error != nil {
+		panic("Failed to remove the article from the reading list")
+	}
+
+	// [Prepare the object and data information for logging]
+	objInfo := utils.ObjInfo{
+		Op:    utils.OpDelete,
+		Table: models.ReadingListItem{}.TableName(),
+		ID:    item.ID,
+	}
+	dataInfo := utils.DataInfo{
+		OldData: map[string]interface{}{
+			"list_id":    list.ID,
+			"article_id": articleID,
+		},
+		NewData: nil,
+	}
+
+	// Return a success response
+	message := "Article removed from the reading list successfully"
+	c.JSON(http.StatusOK, gin.H{
+		"message": message,
+	})
+	utils.Audit(c, slog.LevelInfo, message, objInfo, dataInfo)
+}
+
+// savedArticle is an article in a list of bookmarks or a reading list, without its body.
+type savedArticle struct {
+	ID         uint      `json:"id"`
+	AuthorID   uint      `json:"author_id"`
+	Author     string    `json:"author"`
+	Title      string    `json:"title"`
+	Slug       *string   `json:"slug"`
+	Likes      uint      `json:"likes"`
+	Dislikes   uint      `json:"dislikes"`
+	Free       bool      `json:"free"`
+	Status     uint      `json:"status"`
+	SavedAt    time.Time `json:"saved_at"`
+	Bookmarked bool      `json:"bookmarked" gorm:"-"`
+}
+
+// listSavedArticles returns a page of the saved articles of the query the reader may see, the latest saved first.
+// The articles are listed without their bodies, which are fetched with FetchArticle.
+func listSavedArticles(c *gin.Context, readerID uint, query *gorm.DB, savedAt string) ([]savedArticle, *utils.PaginationResult) {
+	// Get the pagination parameters
+	params := utils.GetPaginationParams(c)
+
+	// Only the approved articles and the reader's own articles are listed
+	query = query.Where("articles.status = ? OR articles.author_id = ?", models.Approved, readerID)
+
+	// Get the total number of articles
+	var total int64
+	result := query.Session(&gorm.Session{}).Count(&total)
+	if result.Error != nil {
+		panic("Failed to get the total number of articles")
+	}
+	if total == 0 {
+		return []savedArticle{}, nil
+	}
+
+	// Get the articles from the database
+	// Use JOIN to get the author's email
+	var articles []savedArticle
+	result = query.Session(&gorm.Session{}).
+		Joins("JOIN users ON articles.author_id = users.id").
+		Select("articles.id AS id", "articles.author_id AS author_id", "users.email AS author", "articles.title AS title", "articles.slug AS slug",
+			"articles.likes AS likes", "articles.dislikes AS dislikes", "articles.free AS free", "articles.status AS status", savedAt+" AS saved_at").
+		Order(savedAt + " DESC").
+		Offset(params.Offset).Limit(params.PageSize).Scan(&articles)
+	if result.Error != nil {
+		panic("Failed to get the articles from the database")
+	}
+	for i := range articles {
+		articles[i].Bookmarked = utils.IsBookmarked(c, readerID, articles[i].ID)
+	}
+
+	// Get the pagination result
+	return articles, utils.GetPaginationResult(params, len(articles), total)
+}
+
+// canSaveArticle reports whether a reader may bookmark an article or add it to a reading list:
+// the approved articles and the reader's own articles.
+func canSaveArticle(readerID uint, articleID uint) bool {
+	var article models.Article
+	result := initializers.DB.Select("id", "author_id", "status").Where("id = ?", articleID).Limit(1).Find(&article)
+	return result.RowsAffected > 0 && (article.Status == models.Approved || article.AuthorID == readerID)
+}
+
+// normalizeListName trims the name of a reading list and checks its length.
+func normalizeListName(name string) string {
+	name = strings.TrimSpace(name)
+	if name == "" || len(name) > 64 {
+		panic("Invalid name: Must be 1 to 64 bytes")
+	}
+	return name
+}
@@ -0,0 +1,508 @@
+package controllers
+
+import (
+	"auth/initializers"
+	"auth/models"
+	"auth/utils"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// maxCoauthors is the maximum number of co-authors of an article, invited or accepted.
+const maxCoauthors = 10
+
+// maxCoauthorShares is the maximum total share of the co-authors in percent, the author keeping the rest of the post reward.
+const maxCoauthorShares = 90
+
+// InviteCoauthor invites a user to co-author an article of the current user, with a share of the post reward in percent.
+// The share of the reward is taken off the author's credits and held until the invitee responds (see RespondInvitation).
+func InviteCoauthor(c *gin.Context) {
+	defer func() {
+		if err := recover(); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err, "request_id": c.GetString(initializers.RequestIDKey)})
+			initializers.LOGGER.ErrorContext(c, "InviteCoauthor Failed", "error", err, "sub", utils.GetSubInfo(c), "params", c.MustGet("params"))
+		}
+	}()
+
+	// [Get the filtered parsed body and save it to the context]
+	rawBody := utils.GetRawBody(c)
+	parsedBody := utils.GetParsedBody(rawBody)
+	utils.BlurMap(parsedBody, "password")
+	c.Set("params", parsedBody)
+
+	// Get the user off the context
+	user, _ := c.Get("user")
+	userID := user.(models.User).ID
+
+	// Get the article ID, the invitee's email and the share off the request
+	var body struct {
+		ArticleID uint   `json:"article_id" binding:"required"`
+		Email     string `json:"email" binding:"required"`
+		Share     uint   `json:"share" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		panic("The article_id, email and share are all required")
+	}
+
+	// Get the article of the user from the database
+	var article models.Article
+	result := initializers.DB.Where("id = ? AND author_id = ?", body.ArticleID, userID).Limit(1).Find(&article)
+	if result.RowsAffected == 0 {
+		panic("Failed to find the article")
+	}
+
+	// Invite the co-author, holding the share of the reward
+	var coauthor models.ArticleAuthor
+	err := initializers.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		coauthor, err = inviteCoauthor(tx, article, body.Email, body.Share)
+		return err
+	})
+	if err != nil {
+		panic(err.Error())
+	}
+
+	// [Prepare the object and data information for logging]
+	objInfo := utils.ObjInfo{
+		Op:    utils.OpCreate,
+		Table: models.ArticleAuthor{}.TableName(),
+		ID:    coauthor.ID,
+	}
+	dataInfo := utils.DataInfo{
+		OldData: nil,
+		NewData: map[string]interface{}{
+			"article_id": coauthor.ArticleID,
+			"user_id":    coauthor.UserID,
+			"share":      coauthor.Share,
+			"reward":     coauthor.Reward,
+		},
+	}
+
+	// Return a success response with the invitation
+	message := "Co-author invited successfully"
+	c.JSON(http.StatusOK, gin.H{
+		"message": message,
+		"id":      coauthor.ID,
+		"reward":  coauthor.Reward,
+	})
+	utils.Audit(c, slog.LevelInfo, message, objInfo, dataInfo)
+}
+
+// FetchCoauthors retrieves the co-authors of an article.
+// The author and the co-authors also get the pending and declined invitations.
+func FetchCoauthors(c *gin.Context) {
+	defer func() {
+		if err := recover(); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err, "request_id": c.GetString(initializers.RequestIDKey)})
+		}
+	}()
+
+	// Get the user off the context
+	user, _ := c.Get("user")
+	userID := user.(models.User).ID
+
+	// Get the article ID off the query string
+	id, ok := c.GetQuery("article_id")
+	if !ok {
+		panic("Failed to get article_id off the query string")
+	}
+	articleID, err := strconv.Atoi(id)
+	if err != nil {
+		panic("Invalid article_id")
+	}
+
+	// Get the article from the database
+	var article models.Article
+	result := initializers.DB.Select("id", "author_id", "status").Where("id = ?", uint(articleID)).Limit(1).Find(&article)
+	if result.RowsAffected == 0 {
+		panic("Failed to find the article")
+	}
+	member := article.AuthorID == userID || isCoauthor(userID, article.ID)
+	if !member && article.Status != models.Approved {
+		panic("Failed to find the article")
+	}
+
+	// Get the co-authors from the database
+	// Use JOIN to get the co-authors' emails
+	query := initializers.DB.Model(&models.ArticleAuthor{}).
+		Joins("JOIN users ON article_authors.user_id = users.id").
+		Select("article_authors.id as id", "article_authors.user_id as user_id", "users.email as email", "article_authors.share as share",
+			"article_authors.status as status", "article_authors.created_at as created_at").
+		Where("article_authors.article_id = ?", article.ID).
+		Order("article_authors.id")
+	if !member {
+		query = query.Where("article_authors.status = ?", models.CoauthorAccepted)
+	}
+	coauthors := []map[string]interface{}{}
+	result = query.Find(&coauthors)
+	if result.Error != nil {
+		panic("Failed to get the co-authors from the database")
+	}
+	for i := range coauthors {
+		if email, ok := coauthors[i]["email"].([]byte); ok {
+			coauthors[i]["email"] = string(email)
+		}
+	}
+
+	// Return a success response with the co-authors
+	c.JSON(http.StatusOK, gin.H{
+		"message":   "Co-authors retrieved successfully",
+		"author_id": article.AuthorID,
+		"coauthors": coauthors,
+	})
+}
+
+// RemoveCoauthor cancels an invitation to co-author an article of the current user, or removes a co-author.
+// The share held for a pending invitation is refunded, whereas a co-author keeps the reward received.
+func RemoveCoauthor(c *gin.Context) {
+	defer func() {
+		if err := recover(); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err, "request_id": c.GetString(initializers.RequestIDKey)})
+			initializers.LOGGER.ErrorContext(c, "RemoveCoauthor Failed", "error", err, "sub", utils.GetSubInfo(c), "params", utils.GetParsedQuery(c))
+		}
+	}()
+
+	// Get the user off the context
+	user, _ := c.Get("user")
+	userID := user.(models.User).ID
+
+	// Get the article ID and the co-author ID off the query string
+	articleID := utils.StrToUint(c.Query("article_id"))
+	coauthorID := utils.StrToUint(c.Query("user_id"))
+	if articleID == 0 || coauthorID == 0 {
+		panic("Both article_id and user_id are required")
+	}
+
+	// Get the article of the user from the database
+	var article models.Article
+	result := initializers.DB.Select("id", "author_id").Where("id = ? AND author_id = ?", articleID, userID).Limit(1).Find(&article)
+	if result.RowsAffected == 0 {
+		panic("Failed to find the article")
+	}
+
+	// Get the co-author from the database
+	var coauthor models.ArticleAuthor
+	result = initializers.DB.Where("article_id = ? AND user_id = ? AND status <> ?", article.ID, coauthorID, models.CoauthorDeclined).Limit(1).Find(&coauthor)
+	if result.RowsAffected == 0 {
+		panic("Failed to find the co-author")
+	}
+	oldStatus := coauthor.Status
+
+	// Remove the co-author, refunding the share of a pending invitation
+	err := initializers.DB.Transaction(func(tx *gorm.DB) error {
+		return leaveArticle(tx, &coauthor, article.AuthorID)
+	})
+	if err != nil {
+		panic(err.Error())
+	}
+
+	// [Prepare the object and data information for logging]
+	objInfo := utils.ObjInfo{
+		Op:    utils.OpDelete,
+		Table: models.ArticleAuthor{}.TableName(),
+		ID:    coauthor.ID,
+	}
+	dataInfo := utils.DataInfo{
+		OldData: map[string]interface{}{
+			"article_id": coauthor.ArticleID,
+			"user_id":    coauthor.UserID,
+			"share":      coauthor.Share,
+			"status":     oldStatus,
+		},
+		NewData: nil,
+	}
+
+	// Return a success response
+	message := "Co-author removed successfully"
+	c.JSON(http.StatusOK, gin.H{
+		"message": message,
+	})
+	utils.Audit(c, slog.LevelInfo, message, objInfo, dataInfo)
+}
+
+// FetchInvitations retrieves the pending invitations of the current user to co-author articles.
+func FetchInvitations(c *gin.Context) {
+	defer func() {
+		if err := recover(); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err, "request_id": c.GetString(initializers.RequestIDKey)})
+		}
+	}()
+
+	// Get the user off the context
+	user, _ := c.Get("user")
+	userID := user.(models.User).ID
+
+	// Get the invitations from the database
+	// Use JOIN to get the articles' titles and the authors' emails
+	invitations := []map[string]interface{}{}
+	result := initializers.DB.Model(&models.ArticleAuthor{}).
+		Joins("JOIN articles ON article_authors.article_id = articles.id AND articles.deleted_at IS NULL").
+		Joins("JOIN users ON articles.author_id = users.id").
+		Select("article_authors.id as id", "article_authors.article_id as article_id", "articles.title as title", "users.email as author",
+			"article_authors.share as share", "article_authors.reward as reward", "article_authors.created_at as created_at").
+		Where("article_authors.user_id = ? AND article_authors.status = ?", userID, models.CoauthorInvited).
+		Order("article_authors.created_at DESC").
+		Find(&invitations)
+	if result.Error != nil {
+		panic("Failed to get the invitations from the database")
+	}
+	for i := range invitations {
+		if email, ok := invitations[i]["author"].([]byte); ok {
+			invitations[i]["author"] = string(email)
+		}
+	}
+
+	// Return a success response with the invitations
+	c.JSON(http.StatusOK, gin.H{
+		"message":     "Invitations retrieved successfully",
+		"invitations": invitations,
+	})
+}
+
+// RespondInvitation accepts or declines an invitation of the current user to co-author an article.
+// Accepting it credits the share of the post reward to the user, declining it refunds the author.
+func RespondInvitation(c *gin.Context) {
+	defer func() {
+		if err := recover(); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err, "request_id": c.GetString(initializers.RequestIDKey)})
+			initializers.LOGGER.ErrorContext(c, "RespondInvitation Failed", "error", err, "sub", utils.GetSubInfo(c), "params", c.MustGet("params"))
+		}
+	}()
+
+	// [Get the filtered parsed body and save it to the context]
+	rawBody := utils.GetRawBody(c)
+	parsedBody := utils.GetParsedBody(rawBody)
+	utils.BlurMap(parsedBody, "password")
+	c.Set("params", parsedBody)
+
+	// Get the user off the context
+	user, _ := c.Get("user")
+	userID := user.(models.User).ID
+
+	// Get the invitation ID and the response off the request
+	var body struct {
+		ID     uint  `json:"id" binding:"required"`
+		Accept *bool `json:"accept" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		panic("Both id and accept are required")
+	}
+
+	// Get the invitation of the user from the database
+	var coauthor models.ArticleAuthor
+	result := initializers.DB.Where("id = ? AND user_id = ? AND status = ?", body.ID, userID, models.CoauthorInvited).Limit(1).Find(&coauthor)
+	if result.RowsAffected == 0 {
+		panic("Failed to find the invitation")
+	}
+	var article models.Article
+	result = initializers.DB.Select("id", "author_id").Where("id = ?", coauthor.ArticleID).Limit(1).Find(&article)
+	if result.RowsAffected == 0 {
+		panic("Failed to find the article")
+	}
+
+	// Accept the invitation and credit the reward, or decline it and refund the author
+	err := initializers.DB.Transaction(func(tx *gorm.DB) error {
+		if !*body.Accept {
+			return leaveArticle(tx, &coauthor, article.AuthorID)
+		}
+		result := tx.Model(&models.ArticleAuthor{}).Where("id = ? AND status = ?", coauthor.ID, models.CoauthorInvited).Update("status", models.CoauthorAccepted)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return errors.New("failed to accept the invitation")
+		}
+		coauthor.Status = models.CoauthorAccepted
+		if coauthor.Reward == 0 {
+			return nil
+		}
+		result = tx.Model(&models.User{}).Where("id = ?", userID).Update("credits", gorm.Expr("credits + ?", coauthor.Reward))
+		if result.Error != nil {
+			return errors.New("failed to credit the reward")
+		}
+		return nil
+	})
+	if err != nil {
+		panic(err.Error())
+	}
+
+	// Count the credits for the statistics
+	if *body.Accept && coauthor.Reward > 0 {
+		utils.IncrStat(utils.StatCreditsMoved, int64(coauthor.Reward))
+	}
+
+	// [Prepare the object and data information for logging]
+	objInfo := utils.ObjInfo{
+		Op:    utils.OpUpdate,
+		Table: models.ArticleAuthor{}.TableName(),
+		ID:    coauthor.ID,
+	}
+	dataInfo := utils.DataInfo{
+		OldData: map[string]interface{}{
+			"status": models.CoauthorInvited,
+		},
+		NewData: map[string]interface{}{
+			"status": coauthor.Status,
+			"reward": coauthor.Reward,
+		},
+	}
+
+	// Return a success response
+	message := "Invitation declined successfully"
+	if *body.Accept {
+		message = "Invitation accepted successfully"
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message": message,
+	})
+	utils.Audit(c, slog.LevelInfo, message, objInfo, dataInfo)
+}
+
+// leaveCoauthoredArticle removes the current user from the co-authors of an article, on behalf of RemoveArticle.
+// The reward received is kept, and the article stays with its author.
+func leaveCoauthoredArticle(c *gin.Context, article models.Article, userID uint) {
+	// Get the co-author from the database
+	var coauthor models.ArticleAuthor
+	result := initializers.DB.Where("article_id = ? AND user_id = ? AND status = ?", article.ID, userID, models.CoauthorAccepted).Limit(1).Find(&coauthor)
+	if result.RowsAffected == 0 {
+		panic("Failed to remove article")
+	}
+
+	// Remove the user from the co-authors
+	err := initializers.DB.Transaction(func(tx *gorm.DB) error {
+		return leaveArticle(tx, &coauthor, article.AuthorID)
+	})
+	if err != nil {
+		panic(err.Error())
+	}
+
+	// [Prepare the object and data information for logging]
+	objInfo := utils.ObjInfo{
+		Op:    utils.OpDelete,
+		Table: models.ArticleAuthor{}.TableName(),
+		ID:    coauthor.ID,
+	}
+	dataInfo := utils.DataInfo{
+		OldData: map[string]interface{}{
+			"article_id": coauthor.ArticleID,
+			"user_id":    coauthor.UserID,
+			"share":      coauthor.Share,
+			"status":     models.CoauthorAccepted,
+		},
+		NewData: nil,
+	}
+
+	// Return a success response
+	message := "Co-authored article left successfully"
+	c.JSON(http.StatusOK, gin.H{
+		"message": message,
+	})
+	utils.Audit(c, slog.LevelInfo, message, objInfo, dataInfo)
+}
+
+// inviteCoauthor invites a user by email to co-author an article, taking the share of the post reward off the author's credits.
+// A rejected article earned no reward, so there is nothing to share.
+func inviteCoauthor(tx *gorm.DB, article models.Article, email string, share uint) (models.ArticleAuthor, error) {
+	if share == 0 || share > maxCoauthorShares {
+		return models.ArticleAuthor{}, errors.New("invalid share: must be 1 to " + strconv.Itoa(maxCoauthorShares) + " percent")
+	}
+
+	// Get the invitee from the database
+	var invitee models.User
+	result := tx.Select("id").Where("email = ?", email).Limit(1).Find(&invitee)
+	if result.RowsAffected == 0 {
+		return models.ArticleAuthor{}, errors.New("failed to find the user: " + email)
+	}
+	if invitee.ID == article.AuthorID {
+		return models.ArticleAuthor{}, errors.New("the author cannot co-author the article")
+	}
+
+	// Check the co-authors of the article and their shares
+	var coauthors []models.ArticleAuthor
+	tx.Where("article_id = ? AND status <> ?", article.ID, models.CoauthorDeclined).Find(&coauthors)
+	total := share
+	for _, coauthor := range coauthors {
+		if coauthor.UserID == invitee.ID {
+			return models.ArticleAuthor{}, errors.New("the user is already invited: " + email)
+		}
+		total += coauthor.Share
+	}
+	if len(coauthors) >= maxCoauthors {
+		return models.ArticleAuthor{}, errors.New("too many co-authors: must be at most " + strconv.Itoa(maxCoauthors))
+	}
+	if total > maxCoauthorShares {
+		return models.ArticleAuthor{}, errors.New("the shares of the co-authors exceed " + strconv.Itoa(maxCoauthorShares) + " percent")
+	}
+
+	// Hold the share of the reward
+	var reward uint
+	if article.Status != models.Rejected {
+		reward = postReward * share / 100
+	}
+	if reward > 0 {
+		result = tx.Model(&models.User{}).Where("id = ? AND credits >= ?", article.AuthorID, reward).Update("credits", gorm.Expr("credits - ?", reward))
+		if result.Error != nil || result.RowsAffected == 0 {
+			return models.ArticleAuthor{}, errors.New("not enough credits to share the reward")
+		}
+	}
+
+	// Create the invitation, or renew a declined one
+	coauthor := models.ArticleAuthor{ArticleID: article.ID, UserID: invitee.ID}
+	tx.Where("article_id = ? AND user_id = ?", article.ID, invitee.ID).Limit(1).Find(&coauthor)
+	coauthor.Share = share
+	coauthor.Reward = reward
+	coauthor.Status = models.CoauthorInvited
+	if err := tx.Save(&coauthor).Error; err != nil {
+		return models.ArticleAuthor{}, errors.New("failed to invite the co-author")
+	}
+
+	// Notify the invitee
+	notification := models.Notification{
+		UserID:    invitee.ID,
+		Kind:      models.NotifyCoauthor,
+		ActorID:   article.AuthorID,
+		ArticleID: article.ID,
+	}
+	if err := tx.Create(&notification).Error; err != nil {
+		return models.ArticleAuthor{}, errors.New("failed to notify the co-author")
+	}
+	return coauthor, nil
+}
+
+// leaveArticle declines an invitation or removes a co-author, refunding the author the share held for a pending invitation.
+func leaveArticle(tx *gorm.DB, coauthor *models.ArticleAuthor, authorID uint) error {
+	result := tx.Model(&models.ArticleAuthor{}).Where("id = ? AND status = ?", coauthor.ID, coauthor.Status).Update("status", models.CoauthorDeclined)
+	if result.Error != nil || result.RowsAffected == 0 {
+		return errors.New("failed to remove the co-author")
+	}
+	if coauthor.Status == models.CoauthorInvited && coauthor.Reward > 0 {
+		result = tx.Model(&models.User{}).Where("id = ?", authorID).Update("credits", gorm.Expr("credits + ?", coauthor.Reward))
+		if result.Error != nil {
+			return errors.New("failed to refund the reward")
+		}
+	}
+	coauthor.Status = models.CoauthorDeclined
+	return nil
+}
+
+// isCoauthor reports whether a user has accepted to co-author an article.
+func isCoauthor(userID uint, articleID uint) bool {
+	result := initializers.DB.Where("article_id = ? AND user_id = ? AND status = ?", articleID, userID, models.CoauthorAccepted).Limit(1).Find(&models.ArticleAuthor{})
+	return result.RowsAffected > 0
+}
+
+// getCoauthorIDs returns the IDs of the users who have accepted to co-author the articles, by article ID.
+func getCoauthorIDs(articleIDs []uint) map[uint][]uint {
+	coauthorIDs := make(map[uint][]uint)
+	if len(articleIDs) == 0 {
+		return coauthorIDs
+	}
+	var coauthors []models.ArticleAuthor
+	initializers.DB.Select("article_id", "user_id").Where("article_id IN (?) AND status = ?", articleIDs, models.CoauthorAccepted).Find(&coauthors)
+	for _, coauthor := range coauthors {
+		coauthorIDs[coauthor.ArticleID] = append(coauthorIDs[coauthor.ArticleID], coauthor.UserID)
+	}
+	return coauthorIDs
+}
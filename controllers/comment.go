@@ -0,0 +1,206 @@
+package controllers
+
+import (
+	"auth/initializers"
+	"auth/models"
+	"auth/utils"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// maxMentions is the maximum number of users notified by the mentions of a comment.
+const maxMentions = 10
+
+// FetchArticleComments retrieves the comment threads of an article.
+// The top-level comments are paginated, newest first, each with all its nested replies.
+func FetchArticleComments(c *gin.Context) {
+	defer func() {
+		if err := recover(); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err, "request_id": c.GetString(initializers.RequestIDKey)})
+		}
+	}()
+
+	// Get the user off the context
+	user, _ := c.Get("user")
+	userID := user.(models.User).ID
+
+	// Get the article ID off the query string
+	id, ok := c.GetQuery("id")
+	if !ok {
+		panic("ID is required")
+	}
+	articleID, err := strconv.Atoi(id)
+	if err != nil {
+		panic("Invalid ID: Type error")
+	}
+
+	// Check if the user is allowed to read the article
+	var article models.Article
+	result := initializers.DB.First(&article, uint(articleID))
+	if result.Error != nil {
+		panic("Failed to find the article")
+	}
+	if !canReadArticle(userID, article) {
+		panic("Not allowed to view the comments of the article")
+	}
+
+	// Get the pagination parameters
+	params := utils.GetPaginationParams(c)
+
+	// Get the total number of top-level comments
+	var total int64
+	result = initializers.DB.Model(&models.Comment{}).
+		Where("article_id = ? AND status = ? AND parent_id IS NULL", article.ID, models.Approved).
+		Count(&total)
+	if result.Error != nil {
+		panic("Failed to get the total number of comments")
+	}
+
+	// Get the top-level comments from the database
+	roots := getComments(initializers.DB.
+		Where("comments.article_id = ? AND comments.status = ? AND comments.parent_id IS NULL", article.ID, models.Approved).
+		Order("comments.created_at DESC").
+		Offset(params.Offset).Limit(params.PageSize))
+
+	// Get the replies level by level, which is bounded by the depth limit
+	comments := roots
+	parentIDs := getCommentIDs(roots)
+	for depth := 1; depth <= models.MaxCommentDepth && len(parentIDs) > 0; depth++ {
+		replies := getComments(initializers.DB.
+			Where("comments.parent_id IN (?) AND comments.status = ?", parentIDs, models.Approved).
+			Order("comments.created_at ASC"))
+		comments = append(comments, replies...)
+		parentIDs = getCommentIDs(replies)
+	}
+
+	// Get the pagination result
+	pagination := utils.GetPaginationResult(params, len(roots), total)
+
+	// Return a success response with the comment threads
+	c.JSON(http.StatusOK, gin.H{
+		"message":    "Comments retrieved successfully",
+		"comments":   buildCommentTree(comments),
+		"pagination": pagination,
+	})
+}
+
+// getComments retrieves the comments of a query with the emails of their authors.
+func getComments(query *gorm.DB) []map[string]interface{} {
+	var comments []map[string]interface{}
+	result := query.Model(&models.Comment{}).
+		Joins("JOIN users ON comments.author_id = users.id").
+		Select("comments.id as id", "comments.content as content", "users.email as author", "comments.article_id as article_id", "comments.parent_id as parent_id").
+		Find(&comments)
+	if result.Error != nil {
+		panic("Failed to get the comments from the database")
+	}
+	// Convert the author's email to a string
+	for i := range comments {
+		if email, ok := comments[i]["author"].([]byte); ok {
+			comments[i]["author"] = string(email)
+		}
+	}
+	return comments
+}
+
+func getCommentIDs(comments []map[string]interface{}) []uint {
+	ids := make([]uint, 0, len(comments))
+	for _, comment := range comments {
+		ids = append(ids, comment["id"].(uint))
+	}
+	return ids
+}
+
+// buildCommentTree nests the replies of the comments under their parents.
+// The order of the comments is kept at every level, and the replies whose parent is missing
+// (e.g. rejected by the moderation) are dropped along with their own replies.
+func buildCommentTree(comments []map[string]interface{}) []map[string]interface{} {
+	byID := make(map[uint]map[string]interface{}, len(comments))
+	for _, comment := range comments {
+		comment["replies"] = []map[string]interface{}{}
+		byID[comment["id"].(uint)] = comment
+	}
+
+	roots := []map[string]interface{}{}
+	for _, comment := range comments {
+		// The nullable parent_id is scanned as a *uint
+		parentID, ok := comment["parent_id"].(*uint)
+		if !ok || parentID == nil {
+			roots = append(roots, comment)
+		} else if parent, ok := byID[*parentID]; ok {
+			parent["replies"] = append(parent["replies"].([]map[string]interface{}), comment)
+		}
+		delete(comment, "parent_id")
+	}
+	return roots
+}
+
+// getCommentDescendants returns the IDs of all the nested replies of a comment.
+func getCommentDescendants(commentID uint) []uint {
+	var descendants []uint
+	parentIDs := []uint{commentID}
+	for depth := 1; depth <= models.MaxCommentDepth && len(parentIDs) > 0; depth++ {
+		var replyIDs []uint
+		initializers.DB.Model(&models.Comment{}).Where("parent_id IN (?)", parentIDs).Pluck("id", &replyIDs)
+		descendants = append(descendants, replyIDs...)
+		parentIDs = replyIDs
+	}
+	return descendants
+}
+
+// canReadArticle reports whether the reader may read an article and its comments in full.
+// Readers may read the approved articles that are free or whose author or a co-author they have subscribed to,
+// and the articles they have written or co-authored.
+func canReadArticle(readerID uint, article models.Article) bool {
+	if article.AuthorID == readerID || isCoauthor(readerID, article.ID) {
+		return true
+	}
+	if article.Status != models.Approved {
+		return false
+	}
+	if article.Free {
+		return true
+	}
+	authorIDs := append([]uint{article.AuthorID}, getCoauthorIDs([]uint{article.ID})[article.ID]...)
+	result := initializers.DB.Where("reader_id = ? AND author_id IN (?)", readerID, authorIDs).Limit(1).Find(&models.Subscribe{})
+	return result.RowsAffected > 0
+}
+
+// notifyMentions notifies the users mentioned by "@email" in a comment, except its author
+// and the users already notified of it (e.g. before the comment was hidden by reports and approved again).
+// It returns the emails of the notified users.
+func notifyMentions(c *gin.Context, comment models.Comment) []string {
+	emails := utils.ParseMentions(comment.Content, maxMentions)
+	if len(emails) == 0 {
+		return nil
+	}
+
+	var users []models.User
+	notifiedIDs := initializers.DB.Model(&models.Notification{}).Select("user_id").
+		Where("kind = ? AND comment_id = ?", models.NotifyMention, comment.ID)
+	initializers.DB.Select("id", "email").Where("email IN (?) AND id <> ? AND id NOT IN (?)", emails, comment.AuthorID, notifiedIDs).Find(&users)
+	if len(users) == 0 {
+		return nil
+	}
+
+	notified := make([]string, 0, len(users))
+	notifications := make([]models.Notification, 0, len(users))
+	for _, user := range users {
+		notified = append(notified, user.Email)
+		notifications = append(notifications, models.Notification{
+			UserID:    user.ID,
+			Kind:      models.NotifyMention,
+			ActorID:   comment.AuthorID,
+			ArticleID: comment.ArticleID,
+			CommentID: comment.ID,
+		})
+	}
+	if result := initializers.DB.Create(&notifications); result.Error != nil {
+		initializers.LOGGER.ErrorContext(c, "Failed to notify the mentioned users", "error", result.Error.Error(), "comment_id", comment.ID)
+		return nil
+	}
+	return notified
+}
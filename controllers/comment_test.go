@@ -0,0 +1,37 @@
+package controllers
+
+import "testing"
+
+func TestBuildCommentTree(t *testing.T) {
+	// The comments are scanned as maps, with the nullable parent_id as a *uint
+	parentID := func(id uint) *uint { return &id }
+	comments := []map[string]interface{}{
+		{"id": uint(1), "parent_id": (*uint)(nil)},
+		{"id": uint(2), "parent_id": (*uint)(nil)},
+		{"id": uint(3), "parent_id": parentID(1)},
+		{"id": uint(4), "parent_id": parentID(3)},
+		{"id": uint(5), "parent_id": parentID(1)},
+		{"id": uint(6), "parent_id": parentID(99)},
+	}
+
+	roots := buildCommentTree(comments)
+	if len(roots) != 2 || roots[0]["id"] != uint(1) || roots[1]["id"] != uint(2) {
+		t.Fatalf("roots = %v, want the comments 1 and 2", roots)
+	}
+	replies := roots[0]["replies"].([]map[string]interface{})
+	if len(replies) != 2 || replies[0]["id"] != uint(3) || replies[1]["id"] != uint(5) {
+		t.Fatalf("replies of 1 = %v, want the comments 3 and 5", replies)
+	}
+	nested := replies[0]["replies"].([]map[string]interface{})
+	if len(nested) != 1 || nested[0]["id"] != uint(4) {
+		t.Fatalf("replies of 3 = %v, want the comment 4", nested)
+	}
+	if len(roots[1]["replies"].([]map[string]interface{})) != 0 {
+		t.Fatalf("replies of 2 = %v, want none", roots[1]["replies"])
+	}
+	for _, comment := range comments {
+		if _, ok := comment["parent_id"]; ok {
+			t.Fatalf("comment %v keeps its parent_id", comment["id"])
+		}
+	}
+}
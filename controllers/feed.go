@@ -0,0 +1,231 @@
+package controllers
+
+import (
+	"auth/initializers"
+	"auth/models"
+	"auth/utils"
+	"crypto/sha256"
+	"encoding/hex"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// feedSize is the number of the latest articles in a feed.
+const feedSize = 20
+
+// FetchPublicFeed retrieves the feed of the latest approved and listed articles.
+// The "format" query parameter selects RSS (default), Atom or JSON Feed.
+// Feed readers may pass the reader's feed token in the "token" query parameter to get the premium articles in full.
+func FetchPublicFeed(c *gin.Context) {
+	defer func() {
+		if err := recover(); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err, "request_id": c.GetString(initializers.RequestIDKey)})
+		}
+	}()
+
+	feed := utils.Feed{
+		Title:       "Latest articles",
+		Description: "The latest articles of all authors",
+		Link:        initializers.PublicURL + "/api/ui/articles",
+		FeedURL:     initializers.PublicURL + "/api/feeds/articles",
+	}
+	serveFeed(c, feed, initializers.DB.Model(&models.Article{}))
+}
+
+// FetchAuthorFeed retrieves the feed of the latest approved and listed articles of an author.
+// The "format" query parameter selects RSS (default), Atom or JSON Feed.
+// Subscribers' feed readers pass their feed token in the "token" query parameter to get the premium articles in full.
+func FetchAuthorFeed(c *gin.Context) {
+	defer func() {
+		if err := recover(); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err, "request_id": c.GetString(initializers.RequestIDKey)})
+		}
+	}()
+
+	// Get the id off the path parameter
+	id, ok := c.Params.Get("id")
+	if !ok {
+		panic("ID is required")
+	}
+	authorID, err := strconv.Atoi(id)
+	if err != nil {
+		panic("Invalid ID: Type error")
+	}
+
+	// Get the author from the database
+	var author models.User
+	result := initializers.DB.Select("id").Where("id = ?", uint(authorID)).Limit(1).Find(&author)
+	if result.RowsAffected == 0 {
+		panic("Failed to find the author")
+	}
+
+	feed := utils.Feed{
+		Title:       "Articles by " + feedAuthorName(author.ID),
+		Description: "The latest articles of " + feedAuthorName(author.ID),
+		Link:        initializers.PublicURL + "/api/ui/articles/" + id,
+		FeedURL:     initializers.PublicURL + "/api/feeds/authors/" + id,
+	}
+	serveFeed(c, feed, initializers.DB.Model(&models.Article{}).Where("articles.author_id = ?", author.ID))
+}
+
+// RotateFeedToken generates a new feed token for the current user, revoking the former one.
+// The token is only returned once, along with the feed URLs to give to feed readers.
+func RotateFeedToken(c *gin.Context) {
+	defer func() {
+		if err := recover(); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err, "request_id": c.GetString(initializers.RequestIDKey)})
+			initializers.LOGGER.ErrorContext(c, "RotateFeedToken Failed", "error", err, "sub", utils.GetSubInfo(c))
+		}
+	}()
+
+	// Get the user off the context
+	user, _ := c.Get("user")
+	userID := user.(models.User).ID
+
+	// Generate the token and save its hash in the database
+	token, hash, err := utils.NewFeedToken()
+	if err != nil {
+		panic("Failed to generate the feed token")
+	}
+	result := initializers.DB.Model(&models.User{}).Where("id = ?", userID).Update("feed_token", hash)
+	if result.Error != nil || result.RowsAffected == 0 {
+		panic("Failed to save the feed token")
+	}
+
+	// [Prepare the object and data information for logging]
+	objInfo := utils.ObjInfo{
+		Op:    utils.OpUpdate,
+		Table: models.User{}.TableName(),
+		ID:    userID,
+	}
+	dataInfo := utils.DataInfo{
+		OldData: nil,
+		NewData: map[string]interface{}{
+			"feed_token": "rotated",
+		},
+	}
+
+	// Return a success response with the token
+	message := "Feed token rotated successfully"
+	c.JSON(http.StatusOK, gin.H{
+		"message": message,
+		"token":   token,
+		"feeds": gin.H{
+			"public": initializers.PublicURL + "/api/feeds/articles?token=" + token,
+			"author": initializers.PublicURL + "/api/feeds/authors/{id}?token=" + token,
+		},
+	})
+	utils.Audit(c, slog.LevelInfo, message, objInfo, dataInfo)
+}
+
+// serveFeed renders the latest approved and listed articles of the query as a feed.
+// The feed is revalidated with its ETag or its Last-Modified time, answering 304 Not Modified if it has not changed.
+func serveFeed(c *gin.Context, feed utils.Feed, query *gorm.DB) {
+	// Get the format off the query string
+	format := c.DefaultQuery("format", "rss")
+	contentType, ok := utils.FeedFormats[format]
+	if !ok {
+		panic("Invalid format: Must be one of rss, atom, or json")
+	}
+
+	// Get the reader off the feed token, if any
+	var readerID uint
+	if token := c.Query("token"); token != "" {
+		var reader models.User
+		result := initializers.DB.Select("id").Where("feed_token = ?", utils.HashFeedToken(token)).Limit(1).Find(&reader)
+		if result.RowsAffected == 0 {
+			panic("Invalid feed token")
+		}
+		readerID = reader.ID
+	}
+
+	// Get the latest articles from the database
+	var articles []map[string]interface{}
+	result := query.
+		Select("articles.id as id", "articles.author_id as author_id", "articles.title as title", "articles.slug as slug", "articles.body as body", "articles.body_html as body_html", "articles.toc as toc",
+			"articles.free as free", "articles.created_at as created_at", "articles.updated_at as updated_at").
+		Where("articles.status = ? AND articles.unlisted = ?", models.Approved, false).
+		Order("articles.created_at DESC").
+		Limit(feedSize).Find(&articles)
+	if result.Error != nil {
+		panic("Failed to get the articles from the database")
+	}
+
+	// Replace the premium articles by their previews, then render the bodies into HTML
+	gateArticles(readerID, articles)
+	formatArticles("html", articles)
+
+	// Convert the articles to the items of the feed
+	var articleIDs []uint
+	for _, article := range articles {
+		articleIDs = append(articleIDs, article["id"].(uint))
+	}
+	tags := getArticleTags(articleIDs)
+	for _, article := range articles {
+		articleID := article["id"].(uint)
+		item := utils.FeedItem{
+			ID:        initializers.PublicURL + "/api/ui/article/" + strconv.Itoa(int(articleID)),
+			Title:     article["title"].(string),
+			Link:      initializers.PublicURL + "/api/ui/article/" + strconv.Itoa(int(articleID)),
+			Tags:      tags[articleID],
+			Published: article["created_at"].(time.Time),
+			Updated:   article["updated_at"].(time.Time),
+		}
+		// The nullable slug is scanned as a *string
+		if slug, ok := article["slug"].(*string); ok && slug != nil {
+			item.Link = initializers.PublicURL + "/api/ui/article/slug/" + *slug
+		}
+		item.Author = feedAuthorName(article["author_id"].(uint))
+		if article["locked"].(bool) {
+			item.Summary, _ = article["excerpt"].(string)
+		} else {
+			item.Content, _ = article["body"].(string)
+		}
+		if item.Updated.After(feed.Updated) {
+			feed.Updated = item.Updated
+		}
+		feed.Items = append(feed.Items, item)
+	}
+	if feed.Updated.IsZero() {
+		feed.Updated = time.Unix(0, 0)
+	}
+
+	// Render the feed
+	content, err := utils.RenderFeed(feed, format)
+	if err != nil {
+		panic("Failed to render the feed")
+	}
+
+	// Answer the conditional GET of the feed readers
+	sum := sha256.Sum256(content)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+	lastModified := feed.Updated.UTC().Truncate(time.Second)
+	c.Header("ETag", etag)
+	c.Header("Last-Modified", lastModified.Format(http.TimeFormat))
+	if readerID != 0 {
+		c.Header("Cache-Control", "private, max-age=300")
+	} else {
+		c.Header("Cache-Control", "public, max-age=300")
+	}
+	if match := c.GetHeader("If-None-Match"); match != "" {
+		if match == etag || match == "*" {
+			c.Status(http.StatusNotModified)
+			return
+		}
+	} else if since, err := http.ParseTime(c.GetHeader("If-Modified-Since")); err == nil && !lastModified.After(since) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	c.Data(http.StatusOK, contentType, content)
+}
+
+// feedAuthorName returns the name of an author in the public feeds, which must not disclose the emails.
+func feedAuthorName(authorID uint) string {
+	return "Author #" + strconv.Itoa(int(authorID))
+}
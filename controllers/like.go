@@ -0,0 +1,174 @@
+package controllers
+
+import (
+	"auth/initializers"
+	"auth/models"
+	"auth/utils"
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// Status of a reconciliation job of the likes.
+const (
+	ReconcileQueued  = "queued"
+	ReconcileRunning = "running"
+	ReconcileDone    = "done"
+	ReconcileFailed  = "failed"
+)
+
+// ReconcileLikes is an Admin API Endpoint that starts a background job rebuilding the like and dislike counters
+// of the articles and either the likes table from the Redis sets ("sets") or the Redis sets from the likes table ("table").
+// Its progress is retrieved with FetchReconcileJob.
+func ReconcileLikes(c *gin.Context) {
+	defer func() {
+		if err := recover(); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err, "request_id": c.GetString(initializers.RequestIDKey)})
+			initializers.LOGGER.ErrorContext(c, "ReconcileLikes Failed", "error", err, "sub", utils.GetSubInfo(c), "params", c.MustGet("params"))
+		}
+	}()
+
+	// [Get the filtered parsed body and save it to the context]
+	rawBody := utils.GetRawBody(c)
+	parsedBody := utils.GetParsedBody(rawBody)
+	utils.BlurMap(parsedBody, "password")
+	c.Set("params", parsedBody)
+
+	// Get the source of truth off the request
+	var body struct {
+		Source string `json:"source" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		panic("Source is required")
+	}
+	if !utils.IsLikeSource(body.Source) {
+		panic("Invalid source: Must be either sets or table")
+	}
+	if utils.IsReconcilingLikes() {
+		panic("A reconciliation of the likes is already running")
+	}
+
+	// Create the job and run it in the background
+	jobID := uuid.New().String()
+	jobKey := utils.RedisConstants.LIKE_RECONCILE_JOB_KEY_PREFIX + jobID
+	initializers.RDB.HSet(initializers.RDB_CTX, jobKey, map[string]interface{}{
+		"source":     body.Source,
+		"status":     ReconcileQueued,
+		"total":      0,
+		"articles":   0,
+		"counters":   0,
+		"sets":       0,
+		"rows":       0,
+		"created_at": time.Now().Format(time.RFC3339),
+	})
+	initializers.RDB.Expire(initializers.RDB_CTX, jobKey, utils.RedisConstants.LIKE_RECONCILE_JOB_EXPIRE_TIME)
+	ctx := initializers.WithRequestID(context.Background(), c.GetString(initializers.RequestIDKey))
+	go runReconcileJob(ctx, jobID, body.Source)
+
+	// [Prepare the object and data information for logging]
+	objInfo := utils.ObjInfo{
+		Op:    utils.OpUpdate,
+		Table: models.ArticleLike{}.TableName(),
+		ID:    0,
+	}
+	dataInfo := utils.DataInfo{
+		OldData: nil,
+		NewData: map[string]interface{}{
+			"job_id": jobID,
+			"source": body.Source,
+		},
+	}
+
+	// Return a success response with the job
+	message := "Reconciliation of the likes started successfully"
+	c.JSON(http.StatusOK, gin.H{
+		"message": message,
+		"job_id":  jobID,
+		"status":  ReconcileQueued,
+	})
+	utils.Audit(c, slog.LevelWarn, message, objInfo, dataInfo)
+}
+
+// FetchReconcileJob is an Admin API Endpoint that retrieves the progress of a reconciliation job of the likes.
+func FetchReconcileJob(c *gin.Context) {
+	defer func() {
+		if err := recover(); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err, "request_id": c.GetString(initializers.RequestIDKey)})
+		}
+	}()
+
+	// Get the job id off the query string
+	jobID, ok := c.GetQuery("id")
+	if !ok {
+		panic("Failed to get id off the query string")
+	}
+
+	// Get the job off Redis
+	jobKey := utils.RedisConstants.LIKE_RECONCILE_JOB_KEY_PREFIX + jobID
+	fields, err := initializers.RDB.HGetAll(initializers.RDB_CTX, jobKey).Result()
+	if err != nil || len(fields) == 0 {
+		panic("Failed to find the reconciliation job")
+	}
+
+	job := gin.H{
+		"id":         jobID,
+		"source":     fields["source"],
+		"status":     fields["status"],
+		"total":      utils.StrToInt(fields["total"]),
+		"articles":   utils.StrToInt(fields["articles"]),
+		"counters":   utils.StrToInt(fields["counters"]),
+		"sets":       utils.StrToInt(fields["sets"]),
+		"rows":       utils.StrToInt(fields["rows"]),
+		"created_at": fields["created_at"],
+	}
+	if fields["error"] != "" {
+		job["error"] = fields["error"]
+	}
+	if fields["finished_at"] != "" {
+		job["finished_at"] = fields["finished_at"]
+	}
+
+	// Return a success response with the job
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Reconciliation job retrieved successfully",
+		"job":     job,
+	})
+}
+
+// runReconcileJob reconciles the likes, reporting its progress in the job.
+// The context carries the ID of the request that started the job, not the request itself.
+func runReconcileJob(ctx context.Context, jobID string, source string) {
+	jobKey := utils.RedisConstants.LIKE_RECONCILE_JOB_KEY_PREFIX + jobID
+	setStatus := func(status string, values ...interface{}) {
+		initializers.RDB.HSet(initializers.RDB_CTX, jobKey, append([]interface{}{"status", status}, values...)...)
+	}
+	defer func() {
+		if err := recover(); err != nil {
+			initializers.LOGGER.ErrorContext(ctx, "Reconciliation job failed", "error", err, "job_id", jobID)
+			setStatus(ReconcileFailed, "error", fmt.Sprint(err), "finished_at", time.Now().Format(time.RFC3339))
+		}
+	}()
+
+	setStatus(ReconcileRunning)
+	_, err := utils.ReconcileLikes(ctx, source, func(report *utils.LikeReconcileReport) {
+		initializers.RDB.HSet(initializers.RDB_CTX, jobKey,
+			"total", report.Total,
+			"articles", report.Articles,
+			"counters", report.Counters,
+			"sets", report.Sets,
+			"rows", report.Rows,
+		)
+	})
+	if err != nil {
+		initializers.LOGGER.ErrorContext(ctx, "Reconciliation job failed", "error", err.Error(), "job_id", jobID)
+		setStatus(ReconcileFailed, "error", err.Error(), "finished_at", time.Now().Format(time.RFC3339))
+		return
+	}
+	setStatus(ReconcileDone, "finished_at", time.Now().Format(time.RFC3339))
+	initializers.RDB.Expire(initializers.RDB_CTX, jobKey, utils.RedisConstants.LIKE_RECONCILE_JOB_EXPIRE_TIME)
+}
@@ -0,0 +1,311 @@
+package controllers
+
+import (
+	"auth/initializers"
+	"auth/models"
+	"auth/utils"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetModerationRules is an Admin API Endpoint that retrieves the rules of the moderation pipeline.
+func GetModerationRules(c *gin.Context) {
+	defer func() {
+		if err := recover(); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err, "request_id": c.GetString(initializers.RequestIDKey)})
+		}
+	}()
+
+	// Get the rules from the database
+	var rules []models.ModerationRule
+	result := initializers.DB.Order("id").Find(&rules)
+	if result.Error != nil {
+		panic("Failed to get the moderation rules from the database")
+	}
+
+	// Return a success response with the rules
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Moderation rules retrieved successfully",
+		"rules":   rules,
+	})
+}
+
+// AddModerationRule is an Admin API Endpoint that adds a rule to the moderation pipeline.
+func AddModerationRule(c *gin.Context) {
+	defer func() {
+		if err := recover(); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err, "request_id": c.GetString(initializers.RequestIDKey)})
+			initializers.LOGGER.ErrorContext(c, "AddModerationRule Failed", "error", err, "sub", utils.GetSubInfo(c), "params", c.MustGet("params"))
+		}
+	}()
+
+	// [Get the filtered parsed body and save it to the context]
+	rawBody := utils.GetRawBody(c)
+	parsedBody := utils.GetParsedBody(rawBody)
+	utils.BlurMap(parsedBody, "password")
+	c.Set("params", parsedBody)
+
+	// Get the rule off the request body
+	var body struct {
+		Name      string `json:"name" binding:"required"`
+		Kind      string `json:"kind" binding:"required"`
+		Target    string `json:"target" binding:"required"`
+		Pattern   string `json:"pattern"`
+		Threshold int    `json:"threshold"`
+		Action    uint   `json:"action" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		panic("name, kind, target and action are required")
+	}
+
+	// Check if the rule is valid
+	rule := models.ModerationRule{
+		Name:      body.Name,
+		Kind:      body.Kind,
+		Target:    body.Target,
+		Pattern:   body.Pattern,
+		Threshold: body.Threshold,
+		Action:    body.Action,
+		Enabled:   true,
+	}
+	if err := utils.ValidateModerationRule(rule); err != nil {
+		panic("Invalid rule: " + err.Error())
+	}
+
+	// Add the rule in the database
+	result := initializers.DB.Create(&rule)
+	if result.Error != nil {
+		panic("Failed to add moderation rule, which may already exist")
+	}
+
+	// [Prepare the object and data information for logging]
+	objInfo := utils.ObjInfo{
+		Op:    utils.OpCreate,
+		Table: models.ModerationRule{}.TableName(),
+		ID:    rule.ID,
+	}
+	dataInfo := utils.DataInfo{
+		OldData: nil,
+		NewData: map[string]interface{}{
+			"name":      rule.Name,
+			"kind":      rule.Kind,
+			"target":    rule.Target,
+			"pattern":   rule.Pattern,
+			"threshold": rule.Threshold,
+			"action":    rule.Action,
+		},
+	}
+
+	// Return a success response
+	message := "Moderation rule added successfully"
+	c.JSON(http.StatusOK, gin.H{
+		"message": message,
+		"rule":    rule,
+	})
+	utils.Audit(c, slog.LevelWarn, message, objInfo, dataInfo)
+}
+
+// SetModerationRule is an Admin API Endpoint that modifies a rule of the moderation pipeline.
+// Only the given fields are modified, and a rule is disabled by setting "enabled" to false.
+func SetModerationRule(c *gin.Context) {
+	defer func() {
+		if err := recover(); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err, "request_id": c.GetString(initializers.RequestIDKey)})
+			initializers.LOGGER.ErrorContext(c, "SetModerationRule Failed", "error", err, "sub", utils.GetSubInfo(c), "params", c.MustGet("params"))
+		}
+	}()
+
+	// [Get the filtered parsed body and save it to the context]
+	rawBody := utils.GetRawBody(c)
+	parsedBody := utils.GetParsedBody(rawBody)
+	utils.BlurMap(parsedBody, "password")
+	c.Set("params", parsedBody)
+
+	// Get the rule ID and the modified fields off the request body
+	var body struct {
+		ID        uint    `json:"id" binding:"required"`
+		Target    *string `json:"target"`
+		Pattern   *string `json:"pattern"`
+		Threshold *int    `json:"threshold"`
+		Action    *uint   `json:"action"`
+		Enabled   *bool   `json:"enabled"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		panic("ID is required")
+	}
+
+	// Get the rule from the database
+	var rule models.ModerationRule
+	result := initializers.DB.First(&rule, body.ID)
+	if result.Error != nil {
+		panic("Failed to find the moderation rule")
+	}
+	old := rule
+
+	// Check if the modified rule is valid
+	if body.Target != nil {
+		rule.Target = *body.Target
+	}
+	if body.Pattern != nil {
+		rule.Pattern = *body.Pattern
+	}
+	if body.Threshold != nil {
+		rule.Threshold = *body.Threshold
+	}
+	if body.Action != nil {
+		rule.Action = *body.Action
+	}
+	if body.Enabled != nil {
+		rule.Enabled = *body.Enabled
+	}
+	if err := utils.ValidateModerationRule(rule); err != nil {
+		panic("Invalid rule: " + err.Error())
+	}
+
+	// Modify the rule in the database
+	result = initializers.DB.Model(&rule).Updates(map[string]interface{}{
+		"target":    rule.Target,
+		"pattern":   rule.Pattern,
+		"threshold": rule.Threshold,
+		"action":    rule.Action,
+		"enabled":   rule.Enabled,
+	})
+	if result.Error != nil {
+		panic("Failed to modify the moderation rule")
+	}
+
+	// [Prepare the object and data information for logging]
+	objInfo := utils.ObjInfo{
+		Op:    utils.OpUpdate,
+		Table: models.ModerationRule{}.TableName(),
+		ID:    rule.ID,
+	}
+	dataInfo := utils.DataInfo{
+		OldData: map[string]interface{}{
+			"target":    old.Target,
+			"pattern":   old.Pattern,
+			"threshold": old.Threshold,
+			"action":    old.Action,
+			"enabled":   old.Enabled,
+		},
+		NewData: map[string]interface{}{
+			"target":    rule.Target,
+			"pattern":   rule.Pattern,
+			"threshold": rule.Threshold,
+			"action":    rule.Action,
+			"enabled":   rule.Enabled,
+		},
+	}
+
+	// Return a success response
+	message := "Moderation rule modified successfully"
+	c.JSON(http.StatusOK, gin.H{
+		"message": message,
+		"rule":    rule,
+	})
+	utils.Audit(c, slog.LevelWarn, message, objInfo, dataInfo)
+}
+
+// DelModerationRule is an Admin API Endpoint that deletes a rule of the moderation pipeline.
+func DelModerationRule(c *gin.Context) {
+	defer func() {
+		if err := recover(); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err, "request_id": c.GetString(initializers.RequestIDKey)})
+			initializers.LOGGER.ErrorContext(c, "DelModerationRule Failed", "error", err, "sub", utils.GetSubInfo(c), "params", utils.GetParsedQuery(c))
+		}
+	}()
+
+	// Get the rule ID off the query string
+	id, ok := c.GetQuery("id")
+	if !ok {
+		panic("ID is required")
+	}
+	ruleID, err := strconv.Atoi(id)
+	if err != nil {
+		panic("Invalid ID: Type error")
+	}
+
+	// [Get the rule from the database]
+	var rule models.ModerationRule
+	initializers.DB.Where("id = ?", ruleID).Find(&rule)
+
+	// Delete the rule in the database
+	result := initializers.DB.Delete(&models.ModerationRule{}, ruleID)
+	if result.Error != nil || result.RowsAffected == 0 {
+		panic("Failed to delete moderation rule, which may not exist")
+	}
+
+	// [Prepare the object and data information for logging]
+	objInfo := utils.ObjInfo{
+		Op:    utils.OpDelete,
+		Table: models.ModerationRule{}.TableName(),
+		ID:    uint(ruleID),
+	}
+	dataInfo := utils.DataInfo{
+		OldData: map[string]interface{}{
+			"name":    rule.Name,
+			"kind":    rule.Kind,
+			"pattern": rule.Pattern,
+		},
+		NewData: nil,
+	}
+
+	// Return a success response
+	message := "Moderation rule deleted successfully"
+	c.JSON(http.StatusOK, gin.H{
+		"message": message,
+	})
+	utils.Audit(c, slog.LevelWarn, message, objInfo, dataInfo)
+}
+
+// GetModerationVerdicts is an Admin API Endpoint that retrieves the verdict log of the moderation pipeline, newest first.
+// The verdicts can be filtered with the "target_type", "verdict" and "rule_id" query parameters.
+func GetModerationVerdicts(c *gin.Context) {
+	defer func() {
+		if err := recover(); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err, "request_id": c.GetString(initializers.RequestIDKey)})
+		}
+	}()
+
+	// Get the pagination parameters
+	params := utils.GetPaginationParams(c)
+
+	// Prepare the query with the optional filters
+	query := initializers.DB.Model(&models.ModerationVerdict{})
+	if targetType, ok := c.GetQuery("target_type"); ok {
+		query = query.Where("target_type = ?", targetType)
+	}
+	if verdict, ok := c.GetQuery("verdict"); ok {
+		query = query.Where("verdict = ?", utils.StrToUint(verdict))
+	}
+	if ruleID, ok := c.GetQuery("rule_id"); ok {
+		query = query.Where("rule_id = ?", utils.StrToUint(ruleID))
+	}
+
+	// Get the total number of verdicts
+	var total int64
+	result := query.Count(&total)
+	if result.Error != nil {
+		panic("Failed to get the total number of verdicts")
+	}
+
+	// Get the verdicts from the database, without the signatures
+	var verdicts []models.ModerationVerdict
+	result = query.Omit("signature").Order("id DESC").Offset(params.Offset).Limit(params.PageSize).Find(&verdicts)
+	if result.Error != nil {
+		panic("Failed to get the verdicts from the database")
+	}
+
+	// Get the pagination result
+	pagination := utils.GetPaginationResult(params, len(verdicts), total)
+
+	// Return a success response with the verdicts
+	c.JSON(http.StatusOK, gin.H{
+		"message":    "Verdicts retrieved successfully",
+		"verdicts":   verdicts,
+		"pagination": pagination,
+	})
+}
@@ -0,0 +1,106 @@
+package controllers
+
+import (
+	"auth/initializers"
+	"auth/models"
+	"auth/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// FetchNotifications retrieves the notifications of the current user, newest first.
+// Only the unread notifications are retrieved if the "unread" query parameter is true.
+func FetchNotifications(c *gin.Context) {
+	defer func() {
+		if err := recover(); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err, "request_id": c.GetString(initializers.RequestIDKey)})
+		}
+	}()
+
+	// Get the user off the context
+	user, _ := c.Get("user")
+	userID := user.(models.User).ID
+
+	// Get the pagination parameters
+	params := utils.GetPaginationParams(c)
+
+	// Prepare the query with the optional unread filter
+	query := initializers.DB.Model(&models.Notification{}).Where("notifications.user_id = ?", userID)
+	if c.Query("unread") == "true" {
+		query = query.Where("notifications.read = ?", false)
+	}
+
+	// Get the total number of notifications
+	var total int64
+	result := query.Session(&gorm.Session{}).Count(&total)
+	if result.Error != nil {
+		panic("Failed to get the total number of notifications")
+	}
+
+	// Get the notifications from the database
+	// Use JOIN to get the actor's email
+	var notifications []map[string]interface{}
+	result = query.Session(&gorm.Session{}).
+		Joins("JOIN users ON notifications.actor_id = users.id").
+		Select("notifications.id as id", "notifications.kind as kind", "users.email as actor", "notifications.article_id as article_id",
+			"notifications.comment_id as comment_id", "notifications.read as `read`", "notifications.created_at as created_at").
+		Order("notifications.created_at DESC").
+		Offset(params.Offset).Limit(params.PageSize).Find(&notifications)
+	if result.Error != nil {
+		panic("Failed to get the notifications from the database")
+	}
+
+	// Convert the actor's email to a string
+	for i := range notifications {
+		if email, ok := notifications[i]["actor"].([]byte); ok {
+			notifications[i]["actor"] = string(email)
+		}
+	}
+
+	// Get the pagination result
+	pagination := utils.GetPaginationResult(params, len(notifications), total)
+
+	// Return a success response with the notifications
+	c.JSON(http.StatusOK, gin.H{
+		"message":       "Notifications retrieved successfully",
+		"notifications": notifications,
+		"pagination":    pagination,
+	})
+}
+
+// ReadNotifications marks notifications of the current user as read, or all of them if no IDs are given.
+func ReadNotifications(c *gin.Context) {
+	defer func() {
+		if err := recover(); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err, "request_id": c.GetString(initializers.RequestIDKey)})
+		}
+	}()
+
+	// Get the user off the context
+	user, _ := c.Get("user")
+	userID := user.(models.User).ID
+
+	// Get the notification IDs off the request
+	var body struct {
+		IDs []uint `json:"ids"`
+	}
+	c.ShouldBindJSON(&body)
+
+	// Mark the notifications as read in the database
+	query := initializers.DB.Model(&models.Notification{}).Where("user_id = ? AND `read` = ?", userID, false)
+	if len(body.IDs) > 0 {
+		query = query.Where("id IN (?)", body.IDs)
+	}
+	result := query.Update("read", true)
+	if result.Error != nil {
+		panic("Failed to mark the notifications as read")
+	}
+
+	// Return a success response
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Notifications marked as read successfully",
+		"count":   result.RowsAffected,
+	})
+}
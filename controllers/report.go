@@ -0,0 +1,377 @@
+package controllers
+
+import (
+	"auth/initializers"
+	"auth/models"
+	"auth/utils"
+	"log/slog"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// PostReport reports an article, a comment or a user to the moderators.
+// Once enough distinct readers have reported an article or a comment, it is hidden until it is moderated.
+func PostReport(c *gin.Context) {
+	defer func() {
+		if err := recover(); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err, "request_id": c.GetString(initializers.RequestIDKey)})
+			initializers.LOGGER.ErrorContext(c, "PostReport Failed", "error", err, "sub", utils.GetSubInfo(c), "params", c.MustGet("params"))
+		}
+	}()
+
+	// [Get the filtered parsed body and save it to the context]
+	rawBody := utils.GetRawBody(c)
+	parsedBody := utils.GetParsedBody(rawBody)
+	utils.BlurMap(parsedBody, "password")
+	c.Set("params", parsedBody)
+
+	// Get the user off the context
+	user, _ := c.Get("user")
+	userID := user.(models.User).ID
+
+	// Get the target, reason and optional detail off the request
+	var body struct {
+		TargetType string `json:"target_type" binding:"required"`
+		TargetID   uint   `json:"target_id" binding:"required"`
+		Reason     string `json:"reason" binding:"required"`
+		Detail     string `json:"detail"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		panic("target_type, target_id and reason are required")
+	}
+
+	// Validate the reason
+	if !slices.Contains(models.ReportReasons, body.Reason) {
+		panic("Invalid reason: Must be one of spam, harassment, hate, sexual, violence, misinformation, copyright, or other")
+	}
+	if body.Reason == "other" && body.Detail == "" {
+		panic("Detail is required for the reason other")
+	}
+	if len([]rune(body.Detail)) > 500 {
+		panic("Detail is too long: At most 500 characters")
+	}
+
+	// Check if the target is visible to the user and is not the user's own
+	ownerID, ok := getReportTargetOwner(userID, body.TargetType, body.TargetID)
+	if !ok {
+		panic("Failed to find the reported " + body.TargetType)
+	}
+	if ownerID == userID {
+		panic("Failed to report: Cannot report yourself or your own content")
+	}
+
+	// Check if the user has already reported the target
+	result := initializers.DB.Where("reporter_id = ? AND target_type = ? AND target_id = ? AND status = ?",
+		userID, body.TargetType, body.TargetID, models.ReportOpen).Limit(1).Find(&models.Report{})
+	if result.RowsAffected > 0 {
+		panic("Failed to report: Already reported and waiting for moderation")
+	}
+
+	// Create the report in the database
+	report := models.Report{
+		ReporterID: userID,
+		TargetType: body.TargetType,
+		TargetID:   body.TargetID,
+		Reason:     body.Reason,
+		Detail:     body.Detail,
+	}
+	result = initializers.DB.Create(&report)
+	if result.Error != nil {
+		panic("Failed to report")
+	}
+
+	// Hide the target if it has been reported too many times
+	hidden := hideReportedTarget(c, report.TargetType, report.TargetID)
+
+	// [Prepare the object and data information for logging]
+	objInfo := utils.ObjInfo{
+		Op:    utils.OpCreate,
+		Table: models.Report{}.TableName(),
+		ID:    report.ID,
+	}
+	dataInfo := utils.DataInfo{
+		OldData: nil,
+		NewData: map[string]interface{}{
+			"target_type": report.TargetType,
+			"target_id":   report.TargetID,
+			"reason":      report.Reason,
+			"hidden":      hidden,
+		},
+	}
+
+	// Return a success response
+	message := "Report posted successfully"
+	c.JSON(http.StatusOK, gin.H{
+		"message": message,
+	})
+	utils.Audit(c, slog.LevelInfo, message, objInfo, dataInfo)
+}
+
+// GetReports is an Admin API Endpoint that retrieves the moderation queue.
+// The open reports are grouped by target, the most reported first, and can be filtered by target type.
+func GetReports(c *gin.Context) {
+	defer func() {
+		if err := recover(); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err, "request_id": c.GetString(initializers.RequestIDKey)})
+		}
+	}()
+
+	// Get the pagination parameters
+	params := utils.GetPaginationParams(c)
+
+	// Prepare the query with the optional target type filter
+	query := initializers.DB.Model(&models.Report{}).Where("status = ?", models.ReportOpen)
+	if targetType, ok := c.GetQuery("target_type"); ok {
+		query = query.Where("target_type = ?", targetType)
+	}
+
+	// Get the total number of reported targets
+	var total int64
+	result := query.Session(&gorm.Session{}).Select("COUNT(DISTINCT target_type, target_id)").Scan(&total)
+	if result.Error != nil {
+		panic("Failed to get the total number of reported targets")
+	}
+	if total == 0 {
+		c.JSON(http.StatusOK, gin.H{
+			"message": "Reports retrieved successfully",
+			"reports": []map[string]interface{}{},
+		})
+		return
+	}
+
+	// Get the reported targets from the database
+	var groups []map[string]interface{}
+	result = query.Session(&gorm.Session{}).
+		Select("target_type", "target_id", "COUNT(*) as reports", "COUNT(DISTINCT reporter_id) as reporters",
+			"MIN(created_at) as first_reported_at", "MAX(created_at) as last_reported_at").
+		Group("target_type, target_id").
+		Order("reporters DESC, last_reported_at DESC").
+		Offset(params.Offset).Limit(params.PageSize).Find(&groups)
+	if result.Error != nil {
+		panic("Failed to get the reports from the database")
+	}
+
+	// Get the reasons and the current state of each target
+	for _, group := range groups {
+		targetType := group["target_type"].(string)
+		targetID := group["target_id"].(uint)
+
+		var reasons []struct {
+			Reason string
+			Count  int64
+		}
+		initializers.DB.Model(&models.Report{}).
+			Select("reason", "COUNT(*) as count").
+			Where("target_type = ? AND target_id = ? AND status = ?", targetType, targetID, models.ReportOpen).
+			Group("reason").Scan(&reasons)
+		counts := make(map[string]int64, len(reasons))
+		for _, reason := range reasons {
+			counts[reason.Reason] = reason.Count
+		}
+		group["reasons"] = counts
+		group["target"] = getReportTarget(targetType, targetID)
+	}
+
+	// Get the pagination result
+	pagination := utils.GetPaginationResult(params, len(groups), total)
+
+	// Return a success response with the reported targets
+	c.JSON(http.StatusOK, gin.H{
+		"message":    "Reports retrieved successfully",
+		"reports":    groups,
+		"pagination": pagination,
+	})
+}
+
+// SetReportStatus is an Admin API Endpoint that closes the open reports on a target with a decision.
+// Articles and comments are usually moderated with SetArticleStatus and SetCommentStatus instead,
+// which close their reports as well.
+func SetReportStatus(c *gin.Context) {
+	defer func() {
+		if err := recover(); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err, "request_id": c.GetString(initializers.RequestIDKey)})
+			initializers.LOGGER.ErrorContext(c, "SetReportStatus Failed", "error", err, "sub", utils.GetSubInfo(c), "params", c.MustGet("params"))
+		}
+	}()
+
+	// [Get the filtered parsed body and save it to the context]
+	rawBody := utils.GetRawBody(c)
+	parsedBody := utils.GetParsedBody(rawBody)
+	utils.BlurMap(parsedBody, "password")
+	c.Set("params", parsedBody)
+
+	// Get the user off the context
+	user, _ := c.Get("user")
+	userID := user.(models.User).ID
+
+	// Get the target, status and decision off the request
+	var body struct {
+		TargetType string `json:"target_type" binding:"required"`
+		TargetID   uint   `json:"target_id" binding:"required"`
+		Status     uint   `json:"status" binding:"required"`
+		Decision   string `json:"decision" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		panic("target_type, target_id, status and decision are required")
+	}
+
+	// Validate the status
+	if body.Status != models.ReportResolved && body.Status != models.ReportDismissed {
+		panic("Invalid status: Must be Resolved(1) or Dismissed(2)")
+	}
+
+	// Close the open reports on the target
+	closed := closeReports(c, body.TargetType, body.TargetID, userID, body.Status, body.Decision)
+	if closed == 0 {
+		panic("Failed to find open reports on the target")
+	}
+
+	// [Prepare the object and data information for logging]
+	objInfo := utils.ObjInfo{
+		Op:    utils.OpUpdate,
+		Table: models.Report{}.TableName(),
+		ID:    body.TargetID,
+	}
+	dataInfo := utils.DataInfo{
+		OldData: map[string]interface{}{
+			"status": models.ReportOpen,
+		},
+		NewData: map[string]interface{}{
+			"target_type": body.TargetType,
+			"status":      body.Status,
+			"decision":    body.Decision,
+			"reports":     closed,
+		},
+	}
+
+	// Return a success response
+	message := "Report Status set successfully"
+	c.JSON(http.StatusOK, gin.H{
+		"message": message,
+		"reports": closed,
+	})
+	utils.Audit(c, slog.LevelInfo, message, objInfo, dataInfo)
+}
+
+// getReportTargetOwner returns the ID of the user responsible for a reported target,
+// and whether the target exists and is visible to the reporter.
+// The approved articles are visible to everyone, the others to their authors and co-authors only,
+// and the approved comments to the readers of their articles.
+func getReportTargetOwner(reporterID uint, targetType string, targetID uint) (uint, bool) {
+	switch targetType {
+	case models.ReportArticle:
+		var article models.Article
+		result := initializers.DB.Where("id = ?", targetID).Limit(1).Find(&article)
+		if result.RowsAffected == 0 {
+			return 0, false
+		}
+		return article.AuthorID, article.Status == models.Approved || canReadArticle(reporterID, article)
+	case models.ReportComment:
+		var comment models.Comment
+		result := initializers.DB.Select("author_id", "article_id").Where("id = ? AND status = ?", targetID, models.Approved).Limit(1).Find(&comment)
+		if result.RowsAffected == 0 {
+			return 0, false
+		}
+		var article models.Article
+		result = initializers.DB.Where("id = ?", comment.ArticleID).Limit(1).Find(&article)
+		return comment.AuthorID, result.RowsAffected > 0 && canReadArticle(reporterID, article)
+	case models.ReportUser:
+		result := initializers.DB.Select("id").Where("id = ?", targetID).Limit(1).Find(&models.User{})
+		return targetID, result.RowsAffected > 0
+	default:
+		panic("Invalid target_type: Must be one of article, comment, or user")
+	}
+}
+
+// getReportTarget returns a summary of a reported target for the moderation queue, or nil if it is gone.
+func getReportTarget(targetType string, targetID uint) map[string]interface{} {
+	var target map[string]interface{}
+	switch targetType {
+	case models.ReportArticle:
+		initializers.DB.Model(&models.Article{}).Select("title", "author_id", "status").Where("id = ?", targetID).Limit(1).Find(&target)
+	case models.ReportComment:
+		initializers.DB.Model(&models.Comment{}).Select("content", "author_id", "article_id", "status").Where("id = ?", targetID).Limit(1).Find(&target)
+	case models.ReportUser:
+		initializers.DB.Model(&models.User{}).Select("email").Where("id = ?", targetID).Limit(1).Find(&target)
+		if email, ok := target["email"].([]byte); ok {
+			target["email"] = string(email)
+		}
+	}
+	return target
+}
+
+// hideReportedTarget sends a visible article or comment back to moderation once
+// the number of distinct readers with open reports on it reaches the threshold.
+// It reports whether the target has been hidden.
+func hideReportedTarget(c *gin.Context, targetType string, targetID uint) bool {
+	if targetType != models.ReportArticle && targetType != models.ReportComment {
+		return false
+	}
+
+	var reporters int64
+	initializers.DB.Model(&models.Report{}).
+		Where("target_type = ? AND target_id = ? AND status = ?", targetType, targetID, models.ReportOpen).
+		Distinct("reporter_id").Count(&reporters)
+	if reporters < int64(initializers.ReportHideThreshold) {
+		return false
+	}
+
+	var result *gorm.DB
+	if targetType == models.ReportArticle {
+		result = initializers.DB.Model(&models.Article{}).
+			Where("id = ? AND status IN (?)", targetID, []uint{models.Approved, models.Scheduled}).
+			Update("status", models.Pending)
+		if result.RowsAffected > 0 {
+			utils.IndexArticle(c, targetID)
+		}
+	} else {
+		result = initializers.DB.Model(&models.Comment{}).
+			Where("id = ? AND status = ?", targetID, models.Approved).
+			Update("status", models.Pending)
+		if result.RowsAffected > 0 {
+			utils.IndexComment(c, targetID)
+		}
+	}
+	if result.Error != nil || result.RowsAffected == 0 {
+		return false
+	}
+	initializers.LOGGER.WarnContext(c, "Reported content hidden", "target_type", targetType, "target_id", targetID, "reporters", reporters)
+	return true
+}
+
+// closeReports closes the open reports on a target with the moderator's decision.
+// It returns the number of closed reports.
+func closeReports(c *gin.Context, targetType string, targetID uint, moderatorID uint, status uint, decision string) int64 {
+	now := time.Now()
+	result := initializers.DB.Model(&models.Report{}).
+		Where("target_type = ? AND target_id = ? AND status = ?", targetType, targetID, models.ReportOpen).
+		Updates(map[string]interface{}{
+			"status":       status,
+			"moderator_id": moderatorID,
+			"decision":     decision,
+			"decided_at":   now,
+		})
+	if result.Error != nil {
+		initializers.LOGGER.ErrorContext(c, "Failed to close the reports", "error", result.Error.Error(), "target_type", targetType, "target_id", targetID)
+		return 0
+	}
+	return result.RowsAffected
+}
+
+// decideReports closes the open reports on an article or a comment moderated to a status.
+// Rejecting the target resolves its reports and approving it dismisses them, while the other
+// statuses leave them open. It returns the number of closed reports.
+func decideReports(c *gin.Context, targetType string, targetID uint, moderatorID uint, status uint, reason string) int64 {
+	switch status {
+	case models.Rejected:
+		return closeReports(c, targetType, targetID, moderatorID, models.ReportResolved, reason)
+	case models.Approved, models.Scheduled:
+		return closeReports(c, targetType, targetID, moderatorID, models.ReportDismissed, reason)
+	default:
+		return 0
+	}
+}
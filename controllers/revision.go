@@ -0,0 +1,325 @@
+package controllers
+
+import (
+	"auth/initializers"
+	"auth/models"
+	"auth/utils"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// FetchArticleRevisions retrieves the revisions of an article of the current user or co-authored by them.
+func FetchArticleRevisions(c *gin.Context) {
+	defer func() {
+		if err := recover(); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err, "request_id": c.GetString(initializers.RequestIDKey)})
+		}
+	}()
+
+	// Get the user off the context
+	user, _ := c.Get("user")
+	userID := user.(models.User).ID
+
+	// Get the article ID off the query string
+	id, ok := c.GetQuery("id")
+	if !ok {
+		panic("ID is required")
+	}
+	articleID, err := strconv.Atoi(id)
+	if err != nil {
+		panic("Invalid ID: Type error")
+	}
+
+	// Check if the article belongs to the user
+	// The co-authors may go through its revisions as well
+	var article models.Article
+	result := initializers.DB.Select("id", "author_id").Where("id = ?", uint(articleID)).First(&article)
+	if result.Error != nil || (article.AuthorID != userID && !isCoauthor(userID, article.ID)) {
+		panic("Failed to find the article")
+	}
+
+	// Get the pagination parameters
+	params := utils.GetPaginationParams(c)
+
+	// Get the total number of revisions
+	var total int64
+	result = initializers.DB.Model(&models.ArticleRevision{}).Where("article_id = ?", uint(articleID)).Count(&total)
+	if result.Error != nil {
+		panic("Failed to get the total number of revisions")
+	}
+
+	// Get the revisions from the database
+	var revisions []map[string]interface{}
+	result = initializers.DB.Model(&models.ArticleRevision{}).
+		Select("version", "title", "editor_id", "created_at").
+		Where("article_id = ?", uint(articleID)).
+		Order("version DESC").
+		Offset(params.Offset).Limit(params.PageSize).Find(&revisions)
+	if result.Error != nil {
+		panic("Failed to get the revisions from the database")
+	}
+
+	// Get the pagination result
+	pagination := utils.GetPaginationResult(params, len(revisions), total)
+
+	// Return a success response with the revisions
+	c.JSON(http.StatusOK, gin.H{
+		"message":    "Revisions retrieved successfully",
+		"revisions":  revisions,
+		"pagination": pagination,
+	})
+}
+
+// DiffArticleRevisions compares two revisions of an article of the current user or co-authored by them.
+func DiffArticleRevisions(c *gin.Context) {
+	defer func() {
+		if err := recover(); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err, "request_id": c.GetString(initializers.RequestIDKey)})
+		}
+	}()
+
+	// Get the user off the context
+	user, _ := c.Get("user")
+	userID := user.(models.User).ID
+
+	// Get the article ID and the versions off the query string
+	id, ok1 := c.GetQuery("id")
+	from, ok2 := c.GetQuery("from")
+	to, ok3 := c.GetQuery("to")
+	if !(ok1 && ok2 && ok3) {
+		panic("The id, from and to versions are all required")
+	}
+	articleID, err1 := strconv.Atoi(id)
+	fromVersion, err2 := strconv.Atoi(from)
+	toVersion, err3 := strconv.Atoi(to)
+	if err1 != nil || err2 != nil || err3 != nil {
+		panic("Invalid ID or version: Type error")
+	}
+
+	// Check if the article belongs to the user
+	// The co-authors may go through its revisions as well
+	var article models.Article
+	result := initializers.DB.Select("id", "author_id").Where("id = ?", uint(articleID)).First(&article)
+	if result.Error != nil || (article.AuthorID != userID && !isCoauthor(userID, article.ID)) {
+		panic("Failed to find the article")
+	}
+
+	// Get both revisions from the database
+	var fromRevision, toRevision models.ArticleRevision
+	result = initializers.DB.Where("article_id = ? AND version = ?", uint(articleID), uint(fromVersion)).First(&fromRevision)
+	if result.Error != nil {
+		panic("Failed to find the revision: " + from)
+	}
+	result = initializers.DB.Where("article_id = ? AND version = ?", uint(articleID), uint(toVersion)).First(&toRevision)
+	if result.Error != nil {
+		panic("Failed to find the revision: " + to)
+	}
+
+	// Compare the bodies line by line
+	diff, err := utils.DiffLines(fromRevision.Body, toRevision.Body)
+	if err != nil {
+		panic("Failed to compare the revisions: " + err.Error())
+	}
+	added, removed := 0, 0
+	for _, line := range diff {
+		switch line.Op {
+		case utils.DiffInsert:
+			added++
+		case utils.DiffDelete:
+			removed++
+		}
+	}
+
+	// Return a success response with the diff
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Revisions compared successfully",
+		"title": gin.H{
+			"from": fromRevision.Title,
+			"to":   toRevision.Title,
+		},
+		"diff":    diff,
+		"added":   added,
+		"removed": removed,
+	})
+}
+
+// RestoreArticleRevision restores an article of the current user or co-authored by them to one of its revisions.
+// The restored content is kept as a new revision, so that the history is never rewritten.
+func RestoreArticleRevision(c *gin.Context) {
+	defer func() {
+		if err := recover(); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err, "request_id": c.GetString(initializers.RequestIDKey)})
+			initializers.LOGGER.ErrorContext(c, "RestoreArticleRevision Failed", "error", err, "sub", utils.GetSubInfo(c), "params", c.MustGet("params"))
+		}
+	}()
+
+	// [Get the filtered parsed body and save it to the context]
+	rawBody := utils.GetRawBody(c)
+	parsedBody := utils.GetParsedBody(rawBody)
+	utils.BlurMap(parsedBody, "password")
+	c.Set("params", parsedBody)
+
+	// Get the user off the context
+	user, _ := c.Get("user")
+	userID := user.(models.User).ID
+
+	// Get the article ID and the version off the request
+	var body struct {
+		ID      uint `json:"id" binding:"required"`
+		Version uint `json:"version" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		panic("Both id and version are required")
+	}
+
+	// Get the article of the user from the database
+	// The co-authors may restore its revisions as well
+	var article models.Article
+	result := initializers.DB.Where("id = ?", body.ID).First(&article)
+	if result.Error != nil || (article.AuthorID != userID && !isCoauthor(userID, article.ID)) {
+		panic("Failed to find the article")
+	}
+	oldArticle := article
+
+	// Get the revision from the database
+	var revision models.ArticleRevision
+	result = initializers.DB.Where("article_id = ? AND version = ?", body.ID, body.Version).First(&revision)
+	if result.Error != nil {
+		panic("Failed to find the revision")
+	}
+
+	// Edit the article with the content of the revision
+	err := editArticle(c, &article, revision.Title, revision.Body, userID)
+	if err != nil {
+		panic(err.Error())
+	}
+
+	// [Prepare the object and data information for logging]
+	objInfo := utils.ObjInfo{
+		Op:    utils.OpUpdate,
+		Table: models.Article{}.TableName(),
+		ID:    article.ID,
+	}
+	dataInfo := utils.DataInfo{
+		OldData: map[string]interface{}{
+			"title":  oldArticle.Title,
+			"body":   oldArticle.Body,
+			"status": oldArticle.Status,
+		},
+		NewData: map[string]interface{}{
+			"title":    article.Title,
+			"body":     article.Body,
+			"status":   article.Status,
+			"restored": revision.Version,
+		},
+	}
+
+	// Return a success response
+	message := "Article restored successfully"
+	c.JSON(http.StatusOK, gin.H{
+		"message": message,
+		"status":  article.Status,
+	})
+	utils.Audit(c, slog.LevelInfo, message, objInfo, dataInfo)
+}
+
+// editArticle changes the title and body of an article and keeps the new version as a revision.
+// Unless the editor is an admin or the article is a draft, the article goes back to moderation.
+func editArticle(c *gin.Context, article *models.Article, title string, body string, editorID uint) error {
+	// Check if the article is a draft or the editor is an admin
+	var email string
+	initializers.DB.Model(&models.User{}).Select("email").Where("id = ?", editorID).Find(&email)
+	var status uint
+	if article.Status == models.Draft {
+		status = models.Draft
+	} else if ok, _ := initializers.E.HasGroupingPolicy(email, "admin"); ok {
+		status = approvedStatus(article.PublishAt)
+	} else {
+		status = models.Pending
+	}
+
+	// Render the new Markdown body into HTML
+	rendered := models.Article{Body: body}
+	if err := renderArticle(&rendered); err != nil {
+		return err
+	}
+
+	// Start a transaction to ensure atomicity
+	err := initializers.DB.Transaction(func(tx *gorm.DB) error {
+		// Keep the current version first if the article was posted before revisions were kept
+		var count int64
+		tx.Model(&models.ArticleRevision{}).Where("article_id = ?", article.ID).Count(&count)
+		if count == 0 {
+			if err := saveArticleRevision(tx, article, article.AuthorID); err != nil {
+				return err
+			}
+		}
+
+		// Update the article in the database
+		result := tx.Model(&models.Article{}).Where("id = ?", article.ID).Updates(map[string]interface{}{
+			"title":     title,
+			"body":      body,
+			"body_html": rendered.BodyHTML,
+			"toc":       rendered.TOC,
+			"status":    status,
+		})
+		if result.Error != nil {
+			return errors.New("failed to edit the article")
+		}
+		titleChanged := article.Title != title
+		article.Title = title
+		article.Body = body
+		article.BodyHTML = rendered.BodyHTML
+		article.TOC = rendered.TOC
+		article.Status = status
+
+		// Give the article the slug of its new title, the former one being redirected
+		if titleChanged || article.Slug == nil {
+			if err := assignSlug(tx, article); err != nil {
+				return err
+			}
+		}
+
+		// Bind the uploaded attachments the new version links
+		if err := bindAttachments(tx, article); err != nil {
+			return err
+		}
+
+		// Keep the new version
+		return saveArticleRevision(tx, article, editorID)
+	})
+	if err != nil {
+		return err
+	}
+
+	// Update the search index
+	utils.IndexArticle(c, article.ID)
+	return nil
+}
+
+// saveArticleRevision keeps the current title and body of an article as its next revision.
+func saveArticleRevision(tx *gorm.DB, article *models.Article, editorID uint) error {
+	var version uint
+	result := tx.Model(&models.ArticleRevision{}).Select("COALESCE(MAX(version), 0)").Where("article_id = ?", article.ID).Scan(&version)
+	if result.Error != nil {
+		return errors.New("failed to get the latest revision")
+	}
+
+	revision := models.ArticleRevision{
+		ArticleID: article.ID,
+		Version:   version + 1,
+		Title:     article.Title,
+		Body:      article.Body,
+		EditorID:  editorID,
+	}
+	result = tx.Create(&revision)
+	if result.Error != nil {
+		return errors.New("failed to save the article revision")
+	}
+	return nil
+}
@@ -0,0 +1,153 @@
+package controllers
+
+import (
+	"auth/initializers"
+	"auth/models"
+	"auth/utils"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// snippetWidth is the maximum length in bytes of the snippets of the search results.
+const snippetWidth = 240
+
+// Search searches the approved articles and comments, ranked by relevance.
+// The premium articles the user may not read in full are previewed by their excerpts instead of their snippets,
+// and the comments on them have no snippet.
+// Quoted phrases in the "q" query parameter must match exactly, and the results can be filtered
+// by "type" (article or comment), "author" and the date range "from"-"to".
+func Search(c *gin.Context) {
+	defer func() {
+		if err := recover(); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err, "request_id": c.GetString(initializers.RequestIDKey)})
+		}
+	}()
+
+	// Get the query off the query string
+	terms, phrases := utils.ParseSearchQuery(c.Query("q"))
+	if len(terms) == 0 && len(phrases) == 0 {
+		panic("Query is required")
+	}
+
+	// Get the pagination parameters
+	params := utils.GetPaginationParams(c)
+
+	// Get the filters off the query string
+	query := utils.SearchQuery{
+		Terms:   terms,
+		Phrases: phrases,
+		Offset:  params.Offset,
+		Limit:   params.PageSize,
+	}
+	if kind, ok := c.GetQuery("type"); ok {
+		if kind != models.SearchArticle && kind != models.SearchComment {
+			panic("Invalid type: Must be either article or comment")
+		}
+		query.Kind = kind
+	}
+	if author, ok := c.GetQuery("author"); ok {
+		query.AuthorID = utils.StrToUint(author)
+		if query.AuthorID == 0 {
+			panic("Invalid author: Type error")
+		}
+	}
+	if from, ok := c.GetQuery("from"); ok {
+		day, err := time.ParseInLocation(time.DateOnly, from, time.Local)
+		if err != nil {
+			panic("Invalid from: Must be a date like 2006-01-02")
+		}
+		query.From = &day
+	}
+	if to, ok := c.GetQuery("to"); ok {
+		day, err := time.ParseInLocation(time.DateOnly, to, time.Local)
+		if err != nil {
+			panic("Invalid to: Must be a date like 2006-01-02")
+		}
+		day = day.AddDate(0, 0, 1)
+		query.To = &day
+	}
+
+	// Search the index
+	hits, total, err := utils.SEARCH.Search(query)
+	if err != nil {
+		panic("Failed to search")
+	}
+
+	// Get the user off the context
+	user, _ := c.Get("user")
+	userID := user.(models.User).ID
+
+	// Get the emails and preview lengths of the authors
+	var authorIDs, articleIDs []uint
+	for _, hit := range hits {
+		authorIDs = append(authorIDs, hit.AuthorID)
+		articleIDs = append(articleIDs, hit.ArticleID)
+	}
+	var users []models.User
+	initializers.DB.Select("id", "email", "preview_length").Where("id IN (?)", authorIDs).Find(&users)
+	usersByID := make(map[uint]models.User, len(users))
+	for _, user := range users {
+		usersByID[user.ID] = user
+	}
+
+	// Get the articles of the hits the user may not read in full, whose bodies are replaced by their previews
+	var articles []models.Article
+	initializers.DB.Where("id IN (?)", articleIDs).Find(&articles)
+	locked := make(map[uint]models.Article)
+	for _, article := range articles {
+		if !canReadArticle(userID, article) {
+			locked[article.ID] = article
+		}
+	}
+
+	// Prepare the results with the highlighted snippets
+	results := make([]gin.H, 0, len(hits))
+	for _, hit := range hits {
+		snippet := utils.Snippet(hit.Body, terms, phrases, snippetWidth)
+		article, isLocked := locked[hit.ArticleID]
+		if isLocked {
+			// The comments on a locked article are not previewed
+			snippet = ""
+			if hit.Kind == models.SearchArticle {
+				snippet = articleExcerpt(article, usersByID[article.AuthorID].PreviewLength)
+			}
+		}
+		results = append(results, gin.H{
+			"type":       hit.Kind,
+			"id":         hit.RefID,
+			"article_id": hit.ArticleID,
+			"title":      utils.Snippet(hit.Title, terms, phrases, snippetWidth),
+			"snippet":    snippet,
+			"locked":     isLocked,
+			"author":     usersByID[hit.AuthorID].Email,
+			"posted_at":  hit.PostedAt,
+			"score":      hit.Score,
+		})
+	}
+
+	// Get the pagination result
+	// No match is not an error, so there is no page to paginate
+	var pagination *utils.PaginationResult
+	if total > 0 {
+		pagination = utils.GetPaginationResult(params, len(results), total)
+	}
+
+	// Return a success response with the results
+	c.JSON(http.StatusOK, gin.H{
+		"message":    "Search completed successfully",
+		"results":    results,
+		"pagination": pagination,
+	})
+}
+
+// articleExcerpt returns the preview of the body of an article, rendering the articles posted before the cached renderings.
+func articleExcerpt(article models.Article, previewLength uint) string {
+	if article.BodyHTML == "" {
+		if err := renderArticle(&article); err != nil {
+			return ""
+		}
+	}
+	return utils.Excerpt(article.BodyHTML, int(previewLength))
+}
@@ -0,0 +1,547 @@
+package controllers
+
+import (
+	"auth/initializers"
+	"auth/models"
+	"auth/utils"
+	"errors"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// FetchSeriesList retrieves the series of an author ("author_id", the current user by default), with their numbers of articles.
+// Readers other than the author only count the approved articles.
+func FetchSeriesList(c *gin.Context) {
+	defer func() {
+		if err := recover(); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err, "request_id": c.GetString(initializers.RequestIDKey)})
+		}
+	}()
+
+	// Get the user off the context
+	user, _ := c.Get("user")
+	userID := user.(models.User).ID
+
+	// Get the author off the query string
+	authorID := userID
+	if id, ok := c.GetQuery("author_id"); ok {
+		authorID = utils.StrToUint(id)
+	}
+
+	// Get the pagination parameters
+	params := utils.GetPaginationParams(c)
+
+	// Get the total number of series of the author
+	query := initializers.DB.Model(&models.Series{}).Where("author_id = ?", authorID)
+	var total int64
+	result := query.Session(&gorm.Session{}).Count(&total)
+	if result.Error != nil {
+		panic("Failed to get the total number of series")
+	}
+	if total == 0 {
+		c.JSON(http.StatusOK, gin.H{
+			"message": "Series retrieved successfully",
+			"series":  []map[string]interface{}{},
+		})
+		return
+	}
+
+	// Get the series from the database
+	var series []map[string]interface{}
+	result = query.Session(&gorm.Session{}).Select("id", "author_id", "title", "description", "created_at", "updated_at").
+		Order("created_at DESC").Offset(params.Offset).Limit(params.PageSize).Find(&series)
+	if result.Error != nil {
+		panic("Failed to get the series from the database")
+	}
+
+	// Count the articles of the series the user may see
+	var seriesIDs []uint
+	for _, s := range series {
+		seriesIDs = append(seriesIDs, s["id"].(uint))
+	}
+	var counts []struct {
+		SeriesID uint
+		Count    int64
+	}
+	countQuery := initializers.DB.Model(&models.SeriesArticle{}).
+		Joins("JOIN articles ON series_articles.article_id = articles.id AND articles.deleted_at IS NULL").
+		Select("series_articles.series_id AS series_id", "COUNT(*) AS count").
+		Where("series_articles.series_id IN (?)", seriesIDs)
+	if authorID != userID {
+		countQuery = countQuery.Where("articles.status = ?", models.Approved)
+	}
+	countQuery.Group("series_articles.series_id").Scan(&counts)
+	countsBySeriesID := make(map[uint]int64, len(counts))
+	for _, count := range counts {
+		countsBySeriesID[count.SeriesID] = count.Count
+	}
+	for i := range series {
+		series[i]["articles"] = countsBySeriesID[series[i]["id"].(uint)]
+	}
+
+	// Get the pagination result
+	pagination := utils.GetPaginationResult(params, len(series), total)
+
+	// Return a success response with the series
+	c.JSON(http.StatusOK, gin.H{
+		"message":    "Series retrieved successfully",
+		"series":     series,
+		"pagination": pagination,
+	})
+}
+
+// FetchSeries retrieves a series with its articles in order.
+// Readers other than the author only get the approved articles, with previews of the premium ones
+// of the authors they have not subscribed to (see gateArticles), as in FetchUserArticles.
+func FetchSeries(c *gin.Context) {
+	defer func() {
+		if err := recover(); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err, "request_id": c.GetString(initializers.RequestIDKey)})
+		}
+	}()
+
+	// Get the user off the context
+	user, _ := c.Get("user")
+	userID := user.(models.User).ID
+
+	// Get the id off the path parameter
+	id, ok := c.Params.Get("id")
+	if !ok {
+		panic("ID is required")
+	}
+	seriesID, err := strconv.Atoi(id)
+	if err != nil {
+		panic("Invalid ID: Type error")
+	}
+
+	// Get the format of the article bodies
+	format := getArticleFormat(c)
+
+	// Get the series from the database
+	var series models.Series
+	result := initializers.DB.Where("id = ?", uint(seriesID)).Limit(1).Find(&series)
+	if result.RowsAffected == 0 {
+		panic("Failed to find the series")
+	}
+
+	// Get the articles of the series from the database, in order
+	query := initializers.DB.Model(&models.Article{}).
+		Joins("JOIN series_articles ON series_articles.article_id = articles.id").
+		Select("articles.id as id", "articles.author_id as author_id", "articles.title as title", "articles.slug as slug", "articles.body as body", "articles.body_html as body_html", "articles.toc as toc",
+			"articles.likes as likes", "articles.dislikes as dislikes", "articles.status as status", "articles.publish_at as publish_at", "articles.unlisted as unlisted", "articles.free as free",
+			"articles.category_id as category_id", "series_articles.position as position").
+		Where("series_articles.series_id = ?", series.ID).
+		Order("series_articles.position")
+	if series.AuthorID != userID {
+		query = query.Where("articles.status = ?", models.Approved)
+	}
+	articles := []map[string]interface{}{}
+	result = query.Find(&articles)
+	if result.Error != nil {
+		panic("Failed to get the articles from the database")
+	}
+
+	// Replace the premium articles by their previews, then set the bodies in the requested format
+	gateArticles(userID, articles)
+	formatArticles(format, articles)
+
+	// Check if the user has liked or disliked the articles, record the views and map the tags and the series navigation to the articles
+	var articleIDs []uint
+	for i := range articles {
+		articleID := articles[i]["id"].(uint)
+		articleIDs = append(articleIDs, articleID)
+		setLikeState(c, userID, articles[i])
+		if series.AuthorID != userID && articles[i]["status"].(uint) == models.Approved && !articles[i]["locked"].(bool) {
+			utils.RecordArticleView(c, articleID, userID)
+		}
+	}
+	tags := getArticleTags(articleIDs)
+	navigations := getSeriesNavigations(userID, articleIDs)
+	for i := range articles {
+		articles[i]["tags"] = tags[articles[i]["id"].(uint)]
+		articles[i]["series"] = navigations[articles[i]["id"].(uint)]
+	}
+
+	// Return a success response with the series
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Series retrieved successfully",
+		"series": gin.H{
+			"id":          series.ID,
+			"author_id":   series.AuthorID,
+			"title":       series.Title,
+			"description": series.Description,
+			"created_at":  series.CreatedAt,
+			"updated_at":  series.UpdatedAt,
+		},
+		"articles": articles,
+	})
+}
+
+// PostSeries creates a series of the current user, with the IDs of its articles in order.
+func PostSeries(c *gin.Context) {
+	defer func() {
+		if err := recover(); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err, "request_id": c.GetString(initializers.RequestIDKey)})
+			initializers.LOGGER.ErrorContext(c, "PostSeries Failed", "error", err, "sub", utils.GetSubInfo(c), "params", c.MustGet("params"))
+		}
+	}()
+
+	// [Get the filtered parsed body and save it to the context]
+	rawBody := utils.GetRawBody(c)
+	parsedBody := utils.GetParsedBody(rawBody)
+	utils.BlurMap(parsedBody, "password")
+	c.Set("params", parsedBody)
+
+	// Get the user off the context
+	user, _ := c.Get("user")
+	userID := user.(models.User).ID
+
+	// Get the title, description and articles off the request
+	var body struct {
+		Title       string `json:"title" binding:"required"`
+		Description string `json:"description"`
+		ArticleIDs  []uint `json:"article_ids"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		panic("Title is required")
+	}
+	title := strings.TrimSpace(body.Title)
+	if title == "" || len(title) > 255 {
+		panic("Invalid title: Must be 1 to 255 bytes")
+	}
+
+	// Create the series with its articles
+	series := models.Series{
+		AuthorID:    userID,
+		Title:       title,
+		Description: body.Description,
+	}
+	err := initializers.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&series).Error; err != nil {
+			return errors.New("failed to create the series")
+		}
+		return setSeriesArticles(tx, series, body.ArticleIDs)
+	})
+	if err != nil {
+		panic(err.Error())
+	}
+
+	// [Prepare the object and data information for logging]
+	objInfo := utils.ObjInfo{
+		Op:    utils.OpCreate,
+		Table: models.Series{}.TableName(),
+		ID:    series.ID,
+	}
+	dataInfo := utils.DataInfo{
+		OldData: nil,
+		NewData: map[string]interface{}{
+			"title":       series.Title,
+			"description": series.Description,
+			"article_ids": body.ArticleIDs,
+		},
+	}
+
+	// Return a success response with the series
+	message := "Series created successfully"
+	c.JSON(http.StatusOK, gin.H{
+		"message": message,
+		"id":      series.ID,
+	})
+	utils.Audit(c, slog.LevelInfo, message, objInfo, dataInfo)
+}
+
+// EditSeries edits a series of the current user. The articles are replaced as a whole, in the given order.
+func EditSeries(c *gin.Context) {
+	defer func() {
+		if err := recover(); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err, "request_id": c.GetString(initializers.RequestIDKey)})
+			initializers.LOGGER.ErrorContext(c, "EditSeries Failed", "error", err, "sub", utils.GetSubInfo(c), "params", c.MustGet("params"))
+		}
+	}()
+
+	// [Get the filtered parsed body and save it to the context]
+	rawBody := utils.GetRawBody(c)
+	parsedBody := utils.GetParsedBody(rawBody)
+	utils.BlurMap(parsedBody, "password")
+	c.Set("params", parsedBody)
+
+	// Get the user off the context
+	user, _ := c.Get("user")
+	userID := user.(models.User).ID
+
+	// Get the series ID, title, description and articles off the request
+	var body struct {
+		ID          uint   `json:"id" binding:"required"`
+		Title       string `json:"title" binding:"required"`
+		Description string `json:"description"`
+		ArticleIDs  []uint `json:"article_ids"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		panic("Both id and title are required")
+	}
+	title := strings.TrimSpace(body.Title)
+	if title == "" || len(title) > 255 {
+		panic("Invalid title: Must be 1 to 255 bytes")
+	}
+
+	// Get the series of the user from the database
+	var series models.Series
+	result := initializers.DB.Where("id = ? AND author_id = ?", body.ID, userID).Limit(1).Find(&series)
+	if result.RowsAffected == 0 {
+		panic("Failed to find the series")
+	}
+	oldSeries := series
+	var oldArticleIDs []uint
+	initializers.DB.Model(&models.SeriesArticle{}).Where("series_id = ?", series.ID).Order("position").Pluck("article_id", &oldArticleIDs)
+
+	// Update the series and replace its articles
+	series.Title = title
+	series.Description = body.Description
+	err := initializers.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Series{}).Where("id = ?", series.ID).Updates(map[string]interface{}{
+			"title":       series.Title,
+			"description": series.Description,
+		})
+		if result.Error != nil {
+			return errors.New("failed to update the series")
+		}
+		return setSeriesArticles(tx, series, body.ArticleIDs)
+	})
+	if err != nil {
+		panic(err.Error())
+	}
+
+	// [Prepare the object and data information for logging]
+	objInfo := utils.ObjInfo{
+		Op:    utils.OpUpdate,
+		Table: models.Series{}.TableName(),
+		ID:    series.ID,
+	}
+	dataInfo := utils.DataInfo{
+		OldData: map[string]interface{}{
+			"title":       oldSeries.Title,
+			"description": oldSeries.Description,
+			"article_ids": oldArticleIDs,
+		},
+		NewData: map[string]interface{}{
+			"title":       series.Title,
+			"description": series.Description,
+			"article_ids": body.ArticleIDs,
+		},
+	}
+
+	// Return a success response
+	message := "Series edited successfully"
+	c.JSON(http.StatusOK, gin.H{
+		"message": message,
+	})
+	utils.Audit(c, slog.LevelInfo, message, objInfo, dataInfo)
+}
+
+// DelSeries deletes a series of the current user. Its articles are kept.
+func DelSeries(c *gin.Context) {
+	defer func() {
+		if err := recover(); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err, "request_id": c.GetString(initializers.RequestIDKey)})
+			initializers.LOGGER.ErrorContext(c, "DelSeries Failed", "error", err, "sub", utils.GetSubInfo(c), "params", utils.GetParsedQuery(c))
+		}
+	}()
+
+	// Get the user off the context
+	user, _ := c.Get("user")
+	userID := user.(models.User).ID
+
+	// Get the id off the query string
+	id, ok := c.GetQuery("id")
+	if !ok {
+		panic("Failed to get id off the query string")
+	}
+	seriesID, err := strconv.Atoi(id)
+	if err != nil {
+		panic("Invalid id")
+	}
+
+	// Get the series of the user from the database
+	var series models.Series
+	result := initializers.DB.Where("id = ? AND author_id = ?", uint(seriesID), userID).Limit(1).Find(&series)
+	if result.RowsAffected == 0 {
+		panic("Failed to find the series")
+	}
+	var articleIDs []uint
+	initializers.DB.Model(&models.SeriesArticle{}).Where("series_id = ?", series.ID).Order("position").Pluck("article_id", &articleIDs)
+
+	// Delete the series, releasing its articles
+	err = initializers.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("series_id = ?", series.ID).Delete(&models.SeriesArticle{}).Error; err != nil {
+			return errors.New("failed to release the articles of the series")
+		}
+		if err := tx.Delete(&series).Error; err != nil {
+			return errors.New("failed to delete the series")
+		}
+		return nil
+	})
+	if err != nil {
+		panic(err.Error())
+	}
+
+	// [Prepare the object and data information for logging]
+	objInfo := utils.ObjInfo{
+		Op:    utils.OpDelete,
+		Table: models.Series{}.TableName(),
+		ID:    series.ID,
+	}
+	dataInfo := utils.DataInfo{
+		OldData: map[string]interface{}{
+			"title":       series.Title,
+			"description": series.Description,
+			"article_ids": articleIDs,
+		},
+		NewData: nil,
+	}
+
+	// Return a success response
+	message := "Series deleted successfully"
+	c.JSON(http.StatusOK, gin.H{
+		"message": message,
+	})
+	utils.Audit(c, slog.LevelWarn, message, objInfo, dataInfo)
+}
+
+// setSeriesArticles replaces the articles of a series by the given ones, in order.
+// The articles must be written by the author of the series, and not belong to another series.
+func setSeriesArticles(tx *gorm.DB, series models.Series, articleIDs []uint) error {
+	if len(articleIDs) > models.MaxSeriesArticles {
+		return errors.New("too many articles: must be at most " + strconv.Itoa(models.MaxSeriesArticles))
+	}
+	seen := make(map[uint]bool, len(articleIDs))
+	for _, articleID := range articleIDs {
+		if seen[articleID] {
+			return errors.New("duplicate article: " + strconv.Itoa(int(articleID)))
+		}
+		seen[articleID] = true
+	}
+
+	if len(articleIDs) > 0 {
+		// Check if the articles are written by the author
+		var count int64
+		tx.Model(&models.Article{}).Where("id IN (?) AND author_id = ?", articleIDs, series.AuthorID).Count(&count)
+		if count != int64(len(articleIDs)) {
+			return errors.New("failed to find the articles")
+		}
+
+		// Check if the articles belong to another series
+		var taken []uint
+		tx.Model(&models.SeriesArticle{}).Where("article_id IN (?) AND series_id <> ?", articleIDs, series.ID).Pluck("article_id", &taken)
+		if len(taken) > 0 {
+			return errors.New("article already in another series: " + strconv.Itoa(int(taken[0])))
+		}
+	}
+
+	// Replace the articles of the series
+	if err := tx.Where("series_id = ?", series.ID).Delete(&models.SeriesArticle{}).Error; err != nil {
+		return errors.New("failed to update the articles of the series")
+	}
+	if len(articleIDs) == 0 {
+		return nil
+	}
+	rows := make([]models.SeriesArticle, 0, len(articleIDs))
+	for i, articleID := range articleIDs {
+		rows = append(rows, models.SeriesArticle{SeriesID: series.ID, Position: uint(i + 1), ArticleID: articleID})
+	}
+	if err := tx.Create(&rows).Error; err != nil {
+		return errors.New("failed to update the articles of the series")
+	}
+	return nil
+}
+
+// getSeriesNavigation returns the series of an article with the previous and next articles the reader may see,
+// or nil if the article is not in a series.
+func getSeriesNavigation(readerID uint, articleID uint) gin.H {
+	return getSeriesNavigations(readerID, []uint{articleID})[articleID]
+}
+
+// getSeriesNavigations returns the series navigation (see getSeriesNavigation) of the articles in a series, by article ID.
+// The series and their articles are fetched at once, whatever the number of articles.
+func getSeriesNavigations(readerID uint, articleIDs []uint) map[uint]gin.H {
+	navigations := make(map[uint]gin.H)
+	if len(articleIDs) == 0 {
+		return navigations
+	}
+	var entries []models.SeriesArticle
+	initializers.DB.Where("article_id IN (?)", articleIDs).Find(&entries)
+	if len(entries) == 0 {
+		return navigations
+	}
+	var seriesIDs []uint
+	for _, entry := range entries {
+		seriesIDs = append(seriesIDs, entry.SeriesID)
+	}
+	var seriesList []models.Series
+	initializers.DB.Where("id IN (?)", seriesIDs).Find(&seriesList)
+	seriesByID := make(map[uint]models.Series, len(seriesList))
+	for _, series := range seriesList {
+		seriesByID[series.ID] = series
+	}
+
+	// Get the articles of the series, in order
+	var parts []struct {
+		ID       uint
+		Title    string
+		Slug     *string
+		Status   uint
+		SeriesID uint
+		Position uint
+	}
+	initializers.DB.Model(&models.Article{}).
+		Joins("JOIN series_articles ON series_articles.article_id = articles.id").
+		Select("articles.id AS id", "articles.title AS title", "articles.slug AS slug", "articles.status AS status",
+			"series_articles.series_id AS series_id", "series_articles.position AS position").
+		Where("series_articles.series_id IN (?)", seriesIDs).
+		Find(&parts)
+	sort.Slice(parts, func(i, j int) bool { return parts[i].Position < parts[j].Position })
+
+	for _, entry := range entries {
+		series, ok := seriesByID[entry.SeriesID]
+		if !ok {
+			continue
+		}
+
+		// Keep the articles of the series the reader may see, along with the article itself
+		var visible []int
+		for i, part := range parts {
+			if part.SeriesID == series.ID && (series.AuthorID == readerID || part.Status == models.Approved || part.ID == entry.ArticleID) {
+				visible = append(visible, i)
+			}
+		}
+
+		navigation := gin.H{
+			"id":    series.ID,
+			"title": series.Title,
+			"total": len(visible),
+			"prev":  nil,
+			"next":  nil,
+		}
+		for k, i := range visible {
+			if parts[i].ID != entry.ArticleID {
+				continue
+			}
+			navigation["part"] = k + 1
+			if k > 0 {
+				prev := parts[visible[k-1]]
+				navigation["prev"] = gin.H{"id": prev.ID, "title": prev.Title, "slug": prev.Slug}
+			}
+			if k < len(visible)-1 {
+				next := parts[visible[k+1]]
+				navigation["next"] = gin.H{"id": next.ID, "title": next.Title, "slug": next.Slug}
+			}
+		}
+		navigations[entry.ArticleID] = navigation
+	}
+	return navigations
+}
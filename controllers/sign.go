@@ -15,8 +15,8 @@ import (
 func SignUp(c *gin.Context) {
 	defer func() {
 		if err := recover(); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err})
-			initializers.LOGGER.Error("SignUp failed", "error", err, "ip", c.ClientIP(), "params", c.MustGet("params"))
+			c.JSON(http.StatusBadRequest, gin.H{"error": err, "request_id": c.GetString(initializers.RequestIDKey)})
+			initializers.LOGGER.ErrorContext(c, "SignUp failed", "error", err, "ip", c.ClientIP(), "params", c.MustGet("params"))
 		}
 	}()
 
@@ -55,19 +55,22 @@ func SignUp(c *gin.Context) {
 	// Create a role inheritance rule in Casbin
 	initializers.E.AddGroupingPolicy(body.Email, "user")
 
+	// Count the sign-up for the statistics
+	utils.IncrStat(utils.StatSignUps, 1)
+
 	// Return a success response
 	message := "User signed up successfully"
 	c.JSON(http.StatusOK, gin.H{
 		"message": message,
 	})
-	initializers.LOGGER.Info(message, "ip", c.ClientIP(), "email", body.Email)
+	initializers.LOGGER.InfoContext(c, message, "ip", c.ClientIP(), "email", body.Email)
 }
 
 func Login(c *gin.Context) {
 	defer func() {
 		if err := recover(); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err})
-			initializers.LOGGER.Error("Login failed", "error", err, "ip", c.ClientIP(), "params", c.MustGet("params"))
+			c.JSON(http.StatusBadRequest, gin.H{"error": err, "request_id": c.GetString(initializers.RequestIDKey)})
+			initializers.LOGGER.ErrorContext(c, "Login failed", "error", err, "ip", c.ClientIP(), "params", c.MustGet("params"))
 		}
 	}()
 
@@ -112,19 +115,23 @@ func Login(c *gin.Context) {
 	c.SetSameSite(http.SameSiteLaxMode)                                // Lax mode for CSRF protection
 	c.SetCookie("Authorization", JWT, 3600*24*30, "", "", false, true) // HttpOnly true for XSS protection
 
+	// Feed the anomaly detector with the login and count it for the statistics
+	utils.RecordLogin(c, user.Email, c.ClientIP())
+	utils.IncrStat(utils.StatLogins, 1)
+
 	// Return a success response
 	message := "User logged in successfully"
 	c.JSON(http.StatusOK, gin.H{
 		"message": message,
 	})
-	initializers.LOGGER.Info(message, "ip", c.ClientIP(), "email", body.Email)
+	initializers.LOGGER.InfoContext(c, message, "ip", c.ClientIP(), "email", body.Email)
 }
 
 // SendCode sends a verification code to the user's email address
 func SendCode(c *gin.Context) {
 	defer func() {
 		if err := recover(); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err})
+			c.JSON(http.StatusBadRequest, gin.H{"error": err, "request_id": c.GetString(initializers.RequestIDKey)})
 		}
 	}()
 
@@ -162,7 +169,7 @@ func SendCode(c *gin.Context) {
 func VerifyCode(c *gin.Context) {
 	defer func() {
 		if err := recover(); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err})
+			c.JSON(http.StatusBadRequest, gin.H{"error": err, "request_id": c.GetString(initializers.RequestIDKey)})
 		}
 	}()
 
@@ -207,6 +214,10 @@ func VerifyCode(c *gin.Context) {
 	c.SetSameSite(http.SameSiteLaxMode)                                // Lax mode for CSRF protection
 	c.SetCookie("Authorization", JWT, 3600*24*30, "", "", false, true) // HttpOnly true for XSS protection
 
+	// Feed the anomaly detector with the login and count it for the statistics
+	utils.RecordLogin(c, user.Email, c.ClientIP())
+	utils.IncrStat(utils.StatLogins, 1)
+
 	// Return a success response
 	message := "User logged in successfully"
 	c.JSON(http.StatusOK, gin.H{
@@ -0,0 +1,205 @@
+package controllers
+
+import (
+	"auth/initializers"
+	"auth/models"
+	"auth/utils"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// FetchArticle retrieves a single article by its ID.
+// Readers who have not subscribed to the author get a preview of a premium article (see gateArticles).
+func FetchArticle(c *gin.Context) {
+	defer func() {
+		if err := recover(); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err, "request_id": c.GetString(initializers.RequestIDKey)})
+		}
+	}()
+
+	// Get the id off the path parameter
+	id, ok := c.Params.Get("id")
+	if !ok {
+		panic("ID is required")
+	}
+	articleID, err := strconv.Atoi(id)
+	if err != nil {
+		panic("Invalid ID: Type error")
+	}
+
+	// Get the article from the database
+	var article models.Article
+	result := initializers.DB.Where("id = ?", uint(articleID)).Limit(1).Find(&article)
+	if result.RowsAffected == 0 {
+		panic("Failed to find the article")
+	}
+
+	fetchArticle(c, article)
+}
+
+// FetchArticleBySlug retrieves a single article by its slug.
+// The former slugs of an article are redirected to its canonical slug.
+func FetchArticleBySlug(c *gin.Context) {
+	defer func() {
+		if err := recover(); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err, "request_id": c.GetString(initializers.RequestIDKey)})
+		}
+	}()
+
+	// Get the slug off the path parameter
+	slug, ok := c.Params.Get("slug")
+	if !ok || slug == "" {
+		panic("Slug is required")
+	}
+
+	// Get the article from the database
+	var article models.Article
+	result := initializers.DB.Where("slug = ?", slug).Limit(1).Find(&article)
+	if result.RowsAffected > 0 {
+		fetchArticle(c, article)
+		return
+	}
+
+	// Redirect a former slug to the canonical one
+	var former models.ArticleSlug
+	result = initializers.DB.Where("slug = ?", slug).Limit(1).Find(&former)
+	if result.RowsAffected > 0 {
+		initializers.DB.Select("id", "slug").Where("id = ?", former.ArticleID).Limit(1).Find(&article)
+		if article.Slug != nil {
+			c.Redirect(http.StatusMovedPermanently, "/api/ui/article/slug/"+url.PathEscape(*article.Slug))
+			return
+		}
+	}
+	panic("Failed to find the article")
+}
+
+// fetchArticle returns an article to the current user, who may read the approved articles and their own.
+func fetchArticle(c *gin.Context, article models.Article) {
+	// Get the user off the context
+	user, _ := c.Get("user")
+	userID := user.(models.User).ID
+
+	// Check if the user is allowed to view the article
+	// Unlisted articles are reachable by link, and the co-authors may view the articles that are not approved
+	if article.AuthorID != userID && article.Status != models.Approved && !isCoauthor(userID, article.ID) {
+		panic("Failed to find the article")
+	}
+
+	// Give a slug to the articles posted before slugs
+	if article.Slug == nil {
+		if err := assignSlug(initializers.DB, &article); err != nil {
+			initializers.LOGGER.ErrorContext(c, "Failed to assign a slug to the article", "error", err.Error(), "article_id", article.ID)
+		}
+	}
+
+	// Get the format of the article body
+	format := getArticleFormat(c)
+
+	// Get the article from the database
+	// Use JOIN to get the author's email
+	var articles []map[string]interface{}
+	result := initializers.DB.Model(&models.Article{}).
+		Joins("JOIN users ON articles.author_id = users.id").
+		Select("articles.id as id", "articles.author_id as author_id", "articles.title as title", "articles.slug as slug", "articles.body as body", "articles.body_html as body_html", "articles.toc as toc",
+			"articles.likes as likes", "articles.dislikes as dislikes", "articles.status as status", "articles.publish_at as publish_at", "articles.unlisted as unlisted", "articles.free as free",
+			"articles.category_id as category_id", "articles.created_at as created_at", "articles.updated_at as updated_at", "users.email as author").
+		Where("articles.id = ?", article.ID).
+		Find(&articles)
+	if result.Error != nil || len(articles) == 0 {
+		panic("Failed to get the article from the database")
+	}
+	if email, ok := articles[0]["author"].([]byte); ok {
+		articles[0]["author"] = string(email)
+	}
+
+	// Replace a premium article by its preview, then set the body in the requested format
+	gateArticles(userID, articles)
+	formatArticles(format, articles)
+
+	// Check if the user has liked or disliked the article, and map the tags, the co-authors and the series navigation to it
+	setLikeState(c, userID, articles[0])
+	articles[0]["tags"] = getArticleTags([]uint{article.ID})[article.ID]
+	articles[0]["coauthor_ids"] = getCoauthorIDs([]uint{article.ID})[article.ID]
+	if series := getSeriesNavigation(userID, article.ID); series != nil {
+		articles[0]["series"] = series
+	}
+
+	// Record the view of the other readers, once it is read in full
+	if article.AuthorID != userID && !articles[0]["locked"].(bool) {
+		utils.RecordArticleView(c, article.ID, userID)
+	}
+
+	// Return a success response with the article
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Article retrieved successfully",
+		"article": articles[0],
+	})
+}
+
+// assignSlug gives an article the unique slug of its title, keeping its former slug for the redirects.
+// The slugs of the other articles, including the deleted ones and their former slugs, are never reused.
+func assignSlug(tx *gorm.DB, article *models.Article) error {
+	base := utils.Slugify(article.Title)
+	if base == "" {
+		// The titles that cannot be transliterated are named after the article
+		base = "article-" + strconv.Itoa(int(article.ID))
+	} else if utils.IsNumericSlug(base) {
+		base = "article-" + base
+	}
+
+	slug := base
+	for n := 2; !isSlugAvailable(tx, slug, article.ID); n++ {
+		slug = base + "-" + strconv.Itoa(n)
+	}
+	if article.Slug != nil && *article.Slug == slug {
+		return nil
+	}
+
+	// Keep the former slug, and take back the slug if the article had it before
+	if article.Slug != nil {
+		if err := tx.Create(&models.ArticleSlug{ArticleID: article.ID, Slug: *article.Slug}).Error; err != nil {
+			return errors.New("failed to keep the former slug of the article")
+		}
+	}
+	if err := tx.Where("article_id = ? AND slug = ?", article.ID, slug).Delete(&models.ArticleSlug{}).Error; err != nil {
+		return errors.New("failed to assign a slug to the article")
+	}
+	if err := tx.Unscoped().Model(&models.Article{}).Where("id = ?", article.ID).Update("slug", slug).Error; err != nil {
+		return errors.New("failed to assign a slug to the article")
+	}
+	article.Slug = &slug
+	return nil
+}
+
+// isSlugAvailable reports whether a slug is not used by another article, currently or formerly.
+func isSlugAvailable(tx *gorm.DB, slug string, articleID uint) bool {
+	var count int64
+	tx.Unscoped().Model(&models.Article{}).Where("slug = ? AND id <> ?", slug, articleID).Count(&count)
+	if count > 0 {
+		return false
+	}
+	tx.Model(&models.ArticleSlug{}).Where("slug = ? AND article_id <> ?", slug, articleID).Count(&count)
+	return count == 0
+}
+
+// setLikeState sets whether the reader has liked, disliked or bookmarked an article,
+// along with the top 5 leaderboards of its likes and dislikes.
+func setLikeState(c *gin.Context, readerID uint, article map[string]interface{}) {
+	articleID := article["id"].(uint)
+	key_liked := utils.RedisConstants.ARTICLE_LIKED_KEY_PREFIX + strconv.Itoa(int(articleID))
+	key_disliked := utils.RedisConstants.ARTICLE_DISLIKED_KEY_PREFIX + strconv.Itoa(int(articleID))
+	article["liked"] = initializers.RDB.ZScore(initializers.RDB_CTX, key_liked, strconv.Itoa(int(readerID))).Err() == nil
+	article["disliked"] = initializers.RDB.ZScore(initializers.RDB_CTX, key_disliked, strconv.Itoa(int(readerID))).Err() == nil
+	article["bookmarked"] = utils.IsBookmarked(c, readerID, articleID)
+
+	var top5_likes, top5_dislikes []uint
+	initializers.RDB.ZRange(initializers.RDB_CTX, key_liked, 0, 4).ScanSlice(&top5_likes)
+	initializers.RDB.ZRange(initializers.RDB_CTX, key_disliked, 0, 4).ScanSlice(&top5_dislikes)
+	article["top5_likes"] = top5_likes
+	article["top5_dislikes"] = top5_dislikes
+}
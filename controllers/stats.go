@@ -0,0 +1,158 @@
+package controllers
+
+import (
+	"auth/initializers"
+	"auth/models"
+	"auth/utils"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// statBucket is a time range [Start, End) of the statistics.
+type statBucket struct {
+	Start time.Time
+	End   time.Time
+}
+
+// GetStats is an Admin API Endpoint that retrieves the activity statistics of the system.
+// The statistics are bucketed by the "granularity" query parameter (day, week or month),
+// and the "count" query parameter sets the number of buckets up to the current one.
+func GetStats(c *gin.Context) {
+	defer func() {
+		if err := recover(); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err, "request_id": c.GetString(initializers.RequestIDKey)})
+		}
+	}()
+
+	// Get the granularity and count off the query string
+	granularity := c.DefaultQuery("granularity", "day")
+	if granularity != "day" && granularity != "week" && granularity != "month" {
+		panic("Invalid granularity: Must be one of day, week, or month")
+	}
+	count, err := strconv.Atoi(c.DefaultQuery("count", "7"))
+	if err != nil || count < 1 || count > 60 {
+		panic("Invalid count: Must be an integer between 1 and 60")
+	}
+
+	// First check if the statistics are in the Redis cache
+	key := utils.RedisConstants.CACHE_STATS_KEY_PREFIX + granularity + ":" + strconv.Itoa(count)
+	if cached, err := initializers.RDB.Get(initializers.RDB_CTX, key).Result(); err == nil {
+		c.Data(http.StatusOK, "application/json; charset=utf-8", []byte(cached))
+		return
+	}
+
+	// Prepare the buckets and the days they cover
+	buckets := getStatBuckets(granularity, count)
+	from, to := buckets[0].Start, buckets[len(buckets)-1].End
+	var days []time.Time
+	for day := from; day.Before(to); day = day.AddDate(0, 0, 1) {
+		days = append(days, day)
+	}
+
+	// Get the daily counts from MySQL
+	articles := countByDay(initializers.DB.Unscoped().Model(&models.Article{}), from, to)
+	comments := countByDay(initializers.DB.Unscoped().Model(&models.Comment{}), from, to)
+	pending := countByDay(initializers.DB.Model(&models.Article{}).Where("status = ?", models.Pending), from, to)
+
+	// Get the daily counts from the Redis counters
+	redisStats := map[string][]int64{}
+	for _, name := range []string{utils.StatSignUps, utils.StatLogins, utils.StatSubscriptions, utils.StatSeckillOrders, utils.StatCreditsMoved} {
+		redisStats[name] = utils.GetStat(name, days)
+	}
+
+	// Sum the daily counts into the buckets
+	var stats []map[string]interface{}
+	for _, bucket := range buckets {
+		stat := map[string]interface{}{
+			"start": bucket.Start.Format(time.DateOnly),
+			"end":   bucket.End.Format(time.DateOnly),
+		}
+		sums := map[string]int64{}
+		for i, day := range days {
+			if day.Before(bucket.Start) || !day.Before(bucket.End) {
+				continue
+			}
+			dayKey := day.Format(time.DateOnly)
+			sums["articles"] += articles[dayKey]
+			sums["comments"] += comments[dayKey]
+			sums["pending_moderation"] += pending[dayKey]
+			for name, counts := range redisStats {
+				sums[name] += counts[i]
+			}
+		}
+		for name, sum := range sums {
+			stat[name] = sum
+		}
+		stats = append(stats, stat)
+	}
+
+	// Get the total number of articles pending moderation
+	var pendingTotal int64
+	result := initializers.DB.Model(&models.Article{}).Where("status = ?", models.Pending).Count(&pendingTotal)
+	if result.Error != nil {
+		panic("Failed to get the total number of pending articles")
+	}
+
+	// Cache the statistics in Redis for a short time
+	response := gin.H{
+		"message":       "Statistics retrieved successfully",
+		"granularity":   granularity,
+		"stats":         stats,
+		"pending_total": pendingTotal,
+	}
+	if content, err := json.Marshal(response); err == nil {
+		initializers.RDB.Set(initializers.RDB_CTX, key, content, utils.RedisConstants.CACHE_STATS_EXPIRE_TIME)
+	}
+
+	// Return a success response with the statistics
+	c.JSON(http.StatusOK, response)
+}
+
+// getStatBuckets returns the last count buckets of the granularity, from the oldest to the current one.
+func getStatBuckets(granularity string, count int) []statBucket {
+	year, month, day := time.Now().Date()
+	today := time.Date(year, month, day, 0, 0, 0, 0, time.Local)
+
+	// Get the start of the current bucket and the function to move between buckets
+	var start time.Time
+	var shift func(t time.Time, n int) time.Time
+	switch granularity {
+	case "week":
+		// Weeks start on Monday
+		start = today.AddDate(0, 0, -((int(today.Weekday()) + 6) % 7))
+		shift = func(t time.Time, n int) time.Time { return t.AddDate(0, 0, 7*n) }
+	case "month":
+		start = time.Date(year, month, 1, 0, 0, 0, 0, time.Local)
+		shift = func(t time.Time, n int) time.Time { return t.AddDate(0, n, 0) }
+	default:
+		start = today
+		shift = func(t time.Time, n int) time.Time { return t.AddDate(0, 0, n) }
+	}
+
+	buckets := make([]statBucket, 0, count)
+	for i := count - 1; i >= 0; i-- {
+		bucketStart := shift(start, -i)
+		buckets = append(buckets, statBucket{Start: bucketStart, End: shift(bucketStart, 1)})
+	}
+	return buckets
+}
+
+// countByDay counts the rows of the query created within [from, to), grouped by local day.
+// The rows are bucketed in Go, since the dates of MySQL would follow the time zone of its session instead.
+func countByDay(query *gorm.DB, from time.Time, to time.Time) map[string]int64 {
+	var times []time.Time
+	result := query.Where("created_at >= ? AND created_at < ?", from, to).Pluck("created_at", &times)
+	if result.Error != nil {
+		panic("Failed to get the statistics from the database")
+	}
+	counts := make(map[string]int64)
+	for _, t := range times {
+		counts[t.In(time.Local).Format(time.DateOnly)]++
+	}
+	return counts
+}
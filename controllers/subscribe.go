@@ -5,6 +5,7 @@ import (
 	"auth/models"
 	"auth/utils"
 	"errors"
+	"log/slog"
 	"net/http"
 	"strconv"
 	"time"
@@ -17,10 +18,17 @@ import (
 func Subscribe(c *gin.Context) {
 	defer func() {
 		if err := recover(); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err})
+			c.JSON(http.StatusBadRequest, gin.H{"error": err, "request_id": c.GetString(initializers.RequestIDKey)})
+			initializers.LOGGER.ErrorContext(c, "Subscribe Failed", "error", err, "sub", utils.GetSubInfo(c), "params", c.MustGet("params"))
 		}
 	}()
 
+	// [Get the filtered parsed body and save it to the context]
+	rawBody := utils.GetRawBody(c)
+	parsedBody := utils.GetParsedBody(rawBody)
+	utils.BlurMap(parsedBody, "password")
+	c.Set("params", parsedBody)
+
 	// Get the reader ID off the context
 	reader, _ := c.Get("user")
 	readerID := reader.(models.User).ID
@@ -56,13 +64,16 @@ func Subscribe(c *gin.Context) {
 		panic("You do not have enough credits.")
 	}
 
+	// Prepare the subscription object
+	subscription := models.Subscribe{
+		AuthorID: authorID,
+		ReaderID: readerID,
+	}
+
 	// Start a transaction to ensure atomicity
 	err := initializers.DB.Transaction(func(tx *gorm.DB) error {
 		// Create a new subscription
-		result := tx.Create(&models.Subscribe{
-			AuthorID: authorID,
-			ReaderID: readerID,
-		})
+		result := tx.Create(&subscription)
 		if result.Error != nil {
 			return errors.New("failed to create a subscription")
 		}
@@ -83,15 +94,40 @@ func Subscribe(c *gin.Context) {
 		panic(err.Error())
 	}
 
+	// Count the subscription and the credits for the statistics
+	utils.IncrStat(utils.StatSubscriptions, 1)
+	utils.IncrStat(utils.StatCreditsMoved, int64(author.Subfee))
+
+	// [Prepare the object and data information for logging]
+	objInfo := utils.ObjInfo{
+		Op:    utils.OpCreate,
+		Table: models.Subscribe{}.TableName(),
+		ID:    subscription.ID,
+	}
+	dataInfo := utils.DataInfo{
+		OldData: map[string]interface{}{
+			"reader_credits": readerCredits,
+			"author_credits": author.Credits,
+		},
+		NewData: map[string]interface{}{
+			"author_id":      authorID,
+			"reader_id":      readerID,
+			"reader_credits": readerCredits - author.Subfee,
+			"author_credits": author.Credits + author.Subfee,
+		},
+	}
+
 	// Return a success response
-	c.JSON(http.StatusOK, gin.H{"message": "Subscription successful"})
+	message := "Subscription successful"
+	c.JSON(http.StatusOK, gin.H{"message": message})
+	utils.Audit(c, slog.LevelInfo, message, objInfo, dataInfo)
 }
 
 // FetchUserDiscounts fetches the discounts of an author.
 func FetchUserDiscounts(c *gin.Context) {
 	defer func() {
 		if err := recover(); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err})
+			c.JSON(http.StatusBadRequest, gin.H{"error": err, "request_id": c.GetString(initializers.RequestIDKey)})
 		}
 	}()
 
@@ -135,10 +171,17 @@ func FetchUserDiscounts(c *gin.Context) {
 func PostDiscount(c *gin.Context) {
 	defer func() {
 		if err := recover(); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err})
+			c.JSON(http.StatusBadRequest, gin.H{"error": err, "request_id": c.GetString(initializers.RequestIDKey)})
+			initializers.LOGGER.ErrorContext(c, "PostDiscount Failed", "error", err, "sub", utils.GetSubInfo(c), "params", c.MustGet("params"))
 		}
 	}()
 
+	// [Get the filtered parsed body and save it to the context]
+	rawBody := utils.GetRawBody(c)
+	parsedBody := utils.GetParsedBody(rawBody)
+	utils.BlurMap(parsedBody, "password")
+	c.Set("params", parsedBody)
+
 	// Get the user ID off the context
 	user, _ := c.Get("user")
 	userID := user.(models.User).ID
@@ -196,20 +239,45 @@ func PostDiscount(c *gin.Context) {
 	ttl := endTime.Sub(beginTime)
 	initializers.RDB.Set(initializers.RDB_CTX, key, body.Stock, ttl)
 
+	// [Prepare the object and data information for logging]
+	objInfo := utils.ObjInfo{
+		Op:    utils.OpCreate,
+		Table: models.Discount{}.TableName(),
+		ID:    discount.ID,
+	}
+	dataInfo := utils.DataInfo{
+		OldData: nil,
+		NewData: map[string]interface{}{
+			"discount":  discount.Discount,
+			"stock":     discount.Stock,
+			"beginTime": discount.BeginTime.Format(time.RFC3339),
+			"endTime":   discount.EndTime.Format(time.RFC3339),
+		},
+	}
+
 	// Return a success response
+	message := "Discount created successfully"
 	c.JSON(http.StatusOK, gin.H{
-		"message": "Discount created successfully",
+		"message": message,
 	})
+	utils.Audit(c, slog.LevelInfo, message, objInfo, dataInfo)
 }
 
 // Seckill allows a reader to purchase a discount.
 func Seckill(c *gin.Context) {
 	defer func() {
 		if err := recover(); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err})
+			c.JSON(http.StatusBadRequest, gin.H{"error": err, "request_id": c.GetString(initializers.RequestIDKey)})
+			initializers.LOGGER.ErrorContext(c, "Seckill Failed", "error", err, "sub", utils.GetSubInfo(c), "params", c.MustGet("params"))
 		}
 	}()
 
+	// [Get the filtered parsed body and save it to the context]
+	rawBody := utils.GetRawBody(c)
+	parsedBody := utils.GetParsedBody(rawBody)
+	utils.BlurMap(parsedBody, "password")
+	c.Set("params", parsedBody)
+
 	// Get the reader ID off the context
 	_reader_, _ := c.Get("user")
 	reader := _reader_.(models.User)
@@ -230,19 +298,40 @@ func Seckill(c *gin.Context) {
 	}
 
 	// Call the seckill function
-	err := seckill(reader, discount)
+	// The request ID is passed along so that the asynchronous task can be traced back to this request
+	err := seckill(reader, discount, c.GetString(initializers.RequestIDKey))
 	if err != nil {
 		panic(err.Error())
 	}
 
+	// [Prepare the object and data information for logging]
+	// The subscription and the credit transfer are completed asynchronously by the seckill task
+	objInfo := utils.ObjInfo{
+		Op:    utils.OpUpdate,
+		Table: models.Discount{}.TableName(),
+		ID:    discount.ID,
+	}
+	dataInfo := utils.DataInfo{
+		OldData: map[string]interface{}{
+			"reader_credits": reader.Credits,
+		},
+		NewData: map[string]interface{}{
+			"author_id":      discount.AuthorID,
+			"reader_id":      reader.ID,
+			"reader_credits": reader.Credits - discount.Discount,
+		},
+	}
+
 	// Return a success response
+	message := "Discount purchased successfully"
 	c.JSON(http.StatusOK, gin.H{
-		"message": "Discount purchased successfully",
+		"message": message,
 	})
+	utils.Audit(c, slog.LevelInfo, message, objInfo, dataInfo)
 }
 
 // *** Using Redis for Seckill Operation ***
-func seckill(reader models.User, discount models.Discount) error {
+func seckill(reader models.User, discount models.Discount, requestID string) error {
 	// (1) Check if the reader is the author himself/herself
 	if reader.ID == discount.AuthorID {
 		return errors.New("failed to purchase the discount: you cannot purchase your own discount")
@@ -254,7 +343,7 @@ func seckill(reader models.User, discount models.Discount) error {
 
 	// Call SeckillScript to perform the seckill operation atomically
 	// ret, _ := utils.SeckillScript.Run(initializers.RDB_CTX, initializers.RDB, []string{}, discount.ID, reader.ID).Int()
-	ret, _ := utils.SeckillScript.Run(initializers.RDB_CTX, initializers.RDB, []string{}, reader.ID, discount.AuthorID, discount.ID, discount.Discount).Int()
+	ret, _ := utils.SeckillScript.Run(initializers.RDB_CTX, initializers.RDB, []string{}, reader.ID, discount.AuthorID, discount.ID, discount.Discount, requestID).Int()
 	if ret == 1 {
 		return errors.New("failed to purchase the discount: already subscribed")
 	} else if ret == 2 {
@@ -266,6 +355,9 @@ func seckill(reader models.User, discount models.Discount) error {
 	// Asynchronously process the seckill task
 	// tasks.AddSeckillTask(reader.ID, discount.AuthorID, discount.ID, discount.Discount)
 
+	// Count the order for the statistics
+	utils.IncrStat(utils.StatSeckillOrders, 1)
+
 	return nil
 }
 
@@ -0,0 +1,411 @@
+package controllers
+
+import (
+	"auth/initializers"
+	"auth/models"
+	"auth/utils"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Limits of the tags attached to an article.
+const (
+	maxArticleTags = 10
+	maxTagLength   = 32
+)
+
+// SuggestTags autocompletes a tag name, the most used tags come first.
+func SuggestTags(c *gin.Context) {
+	defer func() {
+		if err := recover(); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err, "request_id": c.GetString(initializers.RequestIDKey)})
+		}
+	}()
+
+	// Get the prefix and limit off the query string
+	prefix := normalizeTag(c.Query("q"))
+	if prefix == "" {
+		panic("Query is required")
+	}
+	limit := getTagLimit(c)
+
+	// Get the tags starting with the prefix from the database
+	// Escape the wildcards of the LIKE pattern
+	pattern := strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(prefix) + "%"
+	var tags []map[string]interface{}
+	result := initializers.DB.Model(&models.Tag{}).
+		Joins("LEFT JOIN article_tags ON article_tags.tag_id = tags.id").
+		Select("tags.name AS name", "COUNT(article_tags.article_id) AS count").
+		Where("tags.name LIKE ?", pattern).
+		Group("tags.id").
+		Order("count DESC, tags.name").
+		Limit(limit).Find(&tags)
+	if result.Error != nil {
+		panic("Failed to get the tags from the database")
+	}
+
+	// Convert the tag names to strings
+	for i := range tags {
+		if name, ok := tags[i]["name"].([]byte); ok {
+			tags[i]["name"] = string(name)
+		}
+	}
+
+	// Return a success response with the tags
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Tags retrieved successfully",
+		"tags":    tags,
+	})
+}
+
+// GetPopularTags retrieves the tags used by the most approved and listed articles.
+func GetPopularTags(c *gin.Context) {
+	defer func() {
+		if err := recover(); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err, "request_id": c.GetString(initializers.RequestIDKey)})
+		}
+	}()
+
+	// Get the limit off the query string
+	limit := getTagLimit(c)
+
+	// Count the articles of each tag in the database
+	var tags []map[string]interface{}
+	result := initializers.DB.Table("article_tags").
+		Joins("JOIN tags ON tags.id = article_tags.tag_id").
+		Joins("JOIN articles ON articles.id = article_tags.article_id").
+		Select("tags.name AS name", "COUNT(*) AS count").
+		Where("articles.status = ? AND articles.unlisted = ? AND articles.deleted_at IS NULL", models.Approved, false).
+		Group("tags.id").
+		Order("count DESC, tags.name").
+		Limit(limit).Find(&tags)
+	if result.Error != nil {
+		panic("Failed to get the tags from the database")
+	}
+
+	// Convert the tag names to strings
+	for i := range tags {
+		if name, ok := tags[i]["name"].([]byte); ok {
+			tags[i]["name"] = string(name)
+		}
+	}
+
+	// Return a success response with the tags
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Tags retrieved successfully",
+		"tags":    tags,
+	})
+}
+
+// FetchCategories retrieves the category tree.
+func FetchCategories(c *gin.Context) {
+	defer func() {
+		if err := recover(); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err, "request_id": c.GetString(initializers.RequestIDKey)})
+		}
+	}()
+
+	// Get all the categories from the database
+	var categories []models.Category
+	result := initializers.DB.Order("name").Find(&categories)
+	if result.Error != nil {
+		panic("Failed to get the categories from the database")
+	}
+
+	// Return a success response with the category tree
+	c.JSON(http.StatusOK, gin.H{
+		"message":    "Categories retrieved successfully",
+		"categories": buildCategoryTree(categories, nil),
+	})
+}
+
+// AddCategory is an Admin API Endpoint that adds a category under an optional parent.
+func AddCategory(c *gin.Context) {
+	defer func() {
+		if err := recover(); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err, "request_id": c.GetString(initializers.RequestIDKey)})
+			initializers.LOGGER.ErrorContext(c, "AddCategory Failed", "error", err, "sub", utils.GetSubInfo(c), "params", c.MustGet("params"))
+		}
+	}()
+
+	// [Get the filtered parsed body and save it to the context]
+	rawBody := utils.GetRawBody(c)
+	parsedBody := utils.GetParsedBody(rawBody)
+	utils.BlurMap(parsedBody, "password")
+	c.Set("params", parsedBody)
+
+	// Get the name and parent ID off the request body
+	var body struct {
+		Name     string `json:"name" binding:"required"`
+		ParentID *uint  `json:"parent_id"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		panic("Name is required")
+	}
+
+	// Check if the parent exists
+	if body.ParentID != nil {
+		result := initializers.DB.First(&models.Category{}, *body.ParentID)
+		if result.Error != nil {
+			panic("Failed to find the parent category")
+		}
+	}
+
+	// Add the category in the database
+	category := models.Category{Name: strings.TrimSpace(body.Name), ParentID: body.ParentID}
+	result := initializers.DB.Create(&category)
+	if result.Error != nil {
+		panic("Failed to add the category")
+	}
+
+	// [Prepare the object and data information for logging]
+	objInfo := utils.ObjInfo{
+		Op:    utils.OpCreate,
+		Table: models.Category{}.TableName(),
+		ID:    category.ID,
+	}
+	dataInfo := utils.DataInfo{
+		OldData: nil,
+		NewData: map[string]interface{}{
+			"name":      category.Name,
+			"parent_id": category.ParentID,
+		},
+	}
+
+	// Return a success response
+	message := "Category added successfully"
+	c.JSON(http.StatusOK, gin.H{
+		"message": message,
+		"id":      category.ID,
+	})
+	utils.Audit(c, slog.LevelInfo, message, objInfo, dataInfo)
+}
+
+// DelCategory is an Admin API Endpoint that deletes a category without subcategories.
+// The articles of the category become uncategorized.
+func DelCategory(c *gin.Context) {
+	defer func() {
+		if err := recover(); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err, "request_id": c.GetString(initializers.RequestIDKey)})
+			initializers.LOGGER.ErrorContext(c, "DelCategory Failed", "error", err, "sub", utils.GetSubInfo(c), "params", utils.GetParsedQuery(c))
+		}
+	}()
+
+	// Get the category ID off the query string
+	id, ok := c.GetQuery("id")
+	if !ok {
+		panic("ID is required")
+	}
+	categoryID, err := strconv.Atoi(id)
+	if err != nil {
+		panic("Invalid ID: Type error")
+	}
+
+	// [Get the category from the database]
+	var category models.Category
+	initializers.DB.First(&category, uint(categoryID))
+
+	// Check if the category has subcategories
+	var children int64
+	initializers.DB.Model(&models.Category{}).Where("parent_id = ?", uint(categoryID)).Count(&children)
+	if children > 0 {
+		panic("Failed to delete the category: It still has subcategories")
+	}
+
+	// Start a transaction to ensure atomicity
+	err = initializers.DB.Transaction(func(tx *gorm.DB) error {
+		// Uncategorize the articles of the category
+		result := tx.Model(&models.Article{}).Where("category_id = ?", uint(categoryID)).Update("category_id", nil)
+		if result.Error != nil {
+			return errors.New("failed to uncategorize the articles")
+		}
+
+		// Delete the category from the database
+		result = tx.Delete(&models.Category{}, uint(categoryID))
+		if result.Error != nil || result.RowsAffected == 0 {
+			return errors.New("failed to delete the category")
+		}
+
+		return nil
+	})
+	if err != nil {
+		panic(err.Error())
+	}
+
+	// [Prepare the object and data information for logging]
+	objInfo := utils.ObjInfo{
+		Op:    utils.OpDelete,
+		Table: models.Category{}.TableName(),
+		ID:    category.ID,
+	}
+	dataInfo := utils.DataInfo{
+		OldData: map[string]interface{}{
+			"name":      category.Name,
+			"parent_id": category.ParentID,
+		},
+		NewData: nil,
+	}
+
+	// Return a success response
+	message := "Category deleted successfully"
+	c.JSON(http.StatusOK, gin.H{
+		"message": message,
+	})
+	utils.Audit(c, slog.LevelInfo, message, objInfo, dataInfo)
+}
+
+// filterArticles applies the tag, category, author and date range filters of the query string to an article query.
+// The category filter also matches the articles of the subcategories, and the date range is inclusive.
+func filterArticles(c *gin.Context, query *gorm.DB) *gorm.DB {
+	if tag, ok := c.GetQuery("tag"); ok {
+		tagged := initializers.DB.Table("article_tags").
+			Select("article_tags.article_id").
+			Joins("JOIN tags ON tags.id = article_tags.tag_id").
+			Where("tags.name = ?", normalizeTag(tag))
+		query = query.Where("articles.id IN (?)", tagged)
+	}
+	if category, ok := c.GetQuery("category"); ok {
+		categoryID := utils.StrToUint(category)
+		if categoryID == 0 {
+			panic("Invalid category: Type error")
+		}
+		query = query.Where("articles.category_id IN (?)", getCategoryDescendants(categoryID))
+	}
+	if author, ok := c.GetQuery("author"); ok {
+		authorID := utils.StrToUint(author)
+		if authorID == 0 {
+			panic("Invalid author: Type error")
+		}
+		query = query.Where("articles.author_id = ?", authorID)
+	}
+	if from, ok := c.GetQuery("from"); ok {
+		day, err := time.ParseInLocation(time.DateOnly, from, time.Local)
+		if err != nil {
+			panic("Invalid from: Must be a date like 2006-01-02")
+		}
+		query = query.Where("articles.created_at >= ?", day)
+	}
+	if to, ok := c.GetQuery("to"); ok {
+		day, err := time.ParseInLocation(time.DateOnly, to, time.Local)
+		if err != nil {
+			panic("Invalid to: Must be a date like 2006-01-02")
+		}
+		query = query.Where("articles.created_at < ?", day.AddDate(0, 0, 1))
+	}
+	return query
+}
+
+// getArticleTags returns the tag names of each article.
+func getArticleTags(articleIDs []uint) map[uint][]string {
+	var rows []struct {
+		ArticleID uint
+		Name      string
+	}
+	initializers.DB.Table("article_tags").
+		Select("article_tags.article_id AS article_id", "tags.name AS name").
+		Joins("JOIN tags ON tags.id = article_tags.tag_id").
+		Where("article_tags.article_id IN (?)", articleIDs).
+		Order("tags.name").
+		Scan(&rows)
+
+	tags := make(map[uint][]string)
+	for _, row := range rows {
+		tags[row.ArticleID] = append(tags[row.ArticleID], row.Name)
+	}
+	return tags
+}
+
+// findOrCreateTags returns the tags with the given names, creating the ones that don't exist yet.
+func findOrCreateTags(tx *gorm.DB, names []string) ([]models.Tag, error) {
+	tags := make([]models.Tag, 0, len(names))
+	for _, name := range names {
+		var tag models.Tag
+		result := tx.Where(models.Tag{Name: name}).FirstOrCreate(&tag)
+		if result.Error != nil {
+			return nil, errors.New("failed to create the tag: " + name)
+		}
+		tags = append(tags, tag)
+	}
+	return tags, nil
+}
+
+// normalizeTags normalizes and deduplicates the tag names attached to an article.
+func normalizeTags(names []string) []string {
+	var tags []string
+	seen := make(map[string]bool)
+	for _, name := range names {
+		tag := normalizeTag(name)
+		if tag == "" || seen[tag] {
+			continue
+		}
+		if len(tag) > maxTagLength {
+			panic("Invalid tag: Must be at most " + strconv.Itoa(maxTagLength) + " bytes")
+		}
+		seen[tag] = true
+		tags = append(tags, tag)
+	}
+	if len(tags) > maxArticleTags {
+		panic("Too many tags: Must be at most " + strconv.Itoa(maxArticleTags))
+	}
+	return tags
+}
+
+// normalizeTag lowercases a tag name and collapses its whitespace.
+func normalizeTag(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), " "))
+}
+
+// getTagLimit returns the "limit" query parameter of the tag endpoints.
+func getTagLimit(c *gin.Context) int {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if err != nil || limit < 1 || limit > 50 {
+		panic("Invalid limit: Must be an integer between 1 and 50")
+	}
+	return limit
+}
+
+// getCategoryDescendants returns the ID of a category and the IDs of all its subcategories.
+func getCategoryDescendants(categoryID uint) []uint {
+	var categories []models.Category
+	result := initializers.DB.Select("id", "parent_id").Find(&categories)
+	if result.Error != nil {
+		panic("Failed to get the categories from the database")
+	}
+
+	children := make(map[uint][]uint)
+	for _, category := range categories {
+		if category.ParentID != nil {
+			children[*category.ParentID] = append(children[*category.ParentID], category.ID)
+		}
+	}
+
+	ids := []uint{categoryID}
+	for i := 0; i < len(ids); i++ {
+		ids = append(ids, children[ids[i]]...)
+	}
+	return ids
+}
+
+// buildCategoryTree returns the subtree of the categories under the parent (nil for the roots).
+func buildCategoryTree(categories []models.Category, parentID *uint) []gin.H {
+	tree := []gin.H{}
+	for _, category := range categories {
+		if (parentID == nil) != (category.ParentID == nil) || (parentID != nil && *parentID != *category.ParentID) {
+			continue
+		}
+		id := category.ID
+		tree = append(tree, gin.H{
+			"id":       category.ID,
+			"name":     category.Name,
+			"children": buildCategoryTree(categories, &id),
+		})
+	}
+	return tree
+}
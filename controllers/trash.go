@@ -0,0 +1,280 @@
+package controllers
+
+import (
+	"auth/initializers"
+	"auth/models"
+	"auth/utils"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// FetchTrash retrieves the deleted articles or comments of the current user, most recently deleted first.
+// The "type" query parameter selects the articles (default) or the comments.
+func FetchTrash(c *gin.Context) {
+	defer func() {
+		if err := recover(); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err, "request_id": c.GetString(initializers.RequestIDKey)})
+		}
+	}()
+
+	// Get the user off the context
+	user, _ := c.Get("user")
+	userID := user.(models.User).ID
+
+	c.JSON(http.StatusOK, getTrash(c, userID))
+}
+
+// GetTrash is an Admin API Endpoint that retrieves the deleted articles or comments of all users.
+// The "type" query parameter selects the articles (default) or the comments, and "author_id" filters them by author.
+func GetTrash(c *gin.Context) {
+	defer func() {
+		if err := recover(); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err, "request_id": c.GetString(initializers.RequestIDKey)})
+		}
+	}()
+
+	c.JSON(http.StatusOK, getTrash(c, utils.StrToUint(c.Query("author_id"))))
+}
+
+// RestoreTrash restores a deleted article or comment of the current user.
+func RestoreTrash(c *gin.Context) {
+	defer func() {
+		if err := recover(); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err, "request_id": c.GetString(initializers.RequestIDKey)})
+			initializers.LOGGER.ErrorContext(c, "RestoreTrash Failed", "error", err, "sub", utils.GetSubInfo(c), "params", c.MustGet("params"))
+		}
+	}()
+
+	// Get the user off the context
+	user, _ := c.Get("user")
+	userID := user.(models.User).ID
+
+	restoreTrash(c, userID)
+}
+
+// RecoverTrash is an Admin API Endpoint that restores a deleted article or comment of any user.
+func RecoverTrash(c *gin.Context) {
+	defer func() {
+		if err := recover(); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err, "request_id": c.GetString(initializers.RequestIDKey)})
+			initializers.LOGGER.ErrorContext(c, "RecoverTrash Failed", "error", err, "sub", utils.GetSubInfo(c), "params", c.MustGet("params"))
+		}
+	}()
+
+	restoreTrash(c, 0)
+}
+
+// getTrash retrieves a page of the deleted articles or comments of an author (0 for all authors).
+// The comments deleted along with their article are left out, since they are restored with it.
+func getTrash(c *gin.Context, authorID uint) gin.H {
+	// Get the pagination parameters
+	params := utils.GetPaginationParams(c)
+
+	// Prepare the query of the deleted articles or comments
+	var query *gorm.DB
+	var columns []string
+	trashType := c.DefaultQuery("type", models.ReportArticle)
+	switch trashType {
+	case models.ReportArticle:
+		query = initializers.DB.Unscoped().Model(&models.Article{}).Where("deleted_at IS NOT NULL")
+		columns = []string{"id", "title", "author_id", "status", "deleted_at"}
+	case models.ReportComment:
+		deletedArticles := initializers.DB.Unscoped().Model(&models.Article{}).Select("id").Where("deleted_at IS NOT NULL")
+		query = initializers.DB.Unscoped().Model(&models.Comment{}).Where("deleted_at IS NOT NULL AND article_id NOT IN (?)", deletedArticles)
+		columns = []string{"id", "content", "author_id", "article_id", "parent_id", "deleted_at"}
+	default:
+		panic("Invalid type: Must be article or comment")
+	}
+	if authorID != 0 {
+		query = query.Where("author_id = ?", authorID)
+	}
+
+	// Get the total number of deleted items
+	var total int64
+	result := query.Session(&gorm.Session{}).Count(&total)
+	if result.Error != nil {
+		panic("Failed to get the total number of deleted items")
+	}
+	if total == 0 {
+		return gin.H{
+			"message": "Trash retrieved successfully",
+			"trash":   []map[string]interface{}{},
+		}
+	}
+
+	// Get the deleted items from the database
+	var items []map[string]interface{}
+	result = query.Session(&gorm.Session{}).Select(columns).Order("deleted_at DESC").
+		Offset(params.Offset).Limit(params.PageSize).Find(&items)
+	if result.Error != nil {
+		panic("Failed to get the deleted items from the database")
+	}
+
+	// Add the time when each item is purged for good
+	retention := time.Duration(initializers.TrashRetentionDays) * 24 * time.Hour
+	for _, item := range items {
+		if deletedAt, ok := item["deleted_at"].(time.Time); ok {
+			item["purge_at"] = deletedAt.Add(retention)
+		}
+	}
+
+	// Get the pagination result
+	pagination := utils.GetPaginationResult(params, len(items), total)
+
+	return gin.H{
+		"message":    "Trash retrieved successfully",
+		"trash":      items,
+		"pagination": pagination,
+	}
+}
+
+// restoreTrash restores the deleted article or comment of the request, which must belong to the author (0 for any author).
+func restoreTrash(c *gin.Context, authorID uint) {
+	// [Get the filtered parsed body and save it to the context]
+	rawBody := utils.GetRawBody(c)
+	parsedBody := utils.GetParsedBody(rawBody)
+	utils.BlurMap(parsedBody, "password")
+	c.Set("params", parsedBody)
+
+	// Get the type and ID off the request
+	var body struct {
+		Type string `json:"type" binding:"required"`
+		ID   uint   `json:"id" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		panic("Both type and id are required")
+	}
+
+	// Restore the article with its comments, or the comment with its replies
+	var table string
+	var restored map[string]interface{}
+	switch body.Type {
+	case models.ReportArticle:
+		comments, err := restoreArticle(c, body.ID, authorID)
+		if err != nil {
+			panic(err.Error())
+		}
+		table = models.Article{}.TableName()
+		restored = map[string]interface{}{"comments": comments}
+	case models.ReportComment:
+		replies, err := restoreComment(c, body.ID, authorID)
+		if err != nil {
+			panic(err.Error())
+		}
+		table = models.Comment{}.TableName()
+		restored = map[string]interface{}{"replies": replies}
+	default:
+		panic("Invalid type: Must be article or comment")
+	}
+
+	// [Prepare the object and data information for logging]
+	objInfo := utils.ObjInfo{
+		Op:    utils.OpUpdate,
+		Table: table,
+		ID:    body.ID,
+	}
+	dataInfo := utils.DataInfo{
+		OldData: map[string]interface{}{
+			"deleted": true,
+		},
+		NewData: map[string]interface{}{
+			"deleted":  false,
+			"restored": restored,
+		},
+	}
+
+	// Return a success response
+	message := "Restored from the trash successfully"
+	c.JSON(http.StatusOK, gin.H{
+		"message":  message,
+		"restored": restored,
+	})
+	utils.Audit(c, slog.LevelInfo, message, objInfo, dataInfo)
+}
+
+// restoreArticle restores a deleted article along with the comments deleted with it.
+// It returns the number of restored comments.
+func restoreArticle(c *gin.Context, articleID uint, authorID uint) (int64, error) {
+	var article models.Article
+	result := initializers.DB.Unscoped().Where("id = ? AND deleted_at IS NOT NULL", articleID).Limit(1).Find(&article)
+	if result.RowsAffected == 0 || (authorID != 0 && article.AuthorID != authorID) {
+		return 0, errors.New("failed to find the article in the trash")
+	}
+	if result := initializers.DB.Select("id").Where("id = ?", article.AuthorID).Limit(1).Find(&models.User{}); result.RowsAffected == 0 {
+		return 0, errors.New("failed to restore the article: Its author has been deleted")
+	}
+
+	var comments int64
+	err := initializers.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Unscoped().Model(&models.Article{}).Where("id = ?", articleID).Update("deleted_at", nil)
+		if result.Error != nil {
+			return errors.New("failed to restore the article")
+		}
+
+		// The comments removed before the article are left in the trash
+		result = tx.Unscoped().Model(&models.Comment{}).
+			Where("article_id = ? AND deleted_at >= ?", articleID, article.DeletedAt.Time).
+			Update("deleted_at", nil)
+		if result.Error != nil {
+			return errors.New("failed to restore the comments on the article")
+		}
+		comments = result.RowsAffected
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	// Update the search index
+	utils.IndexArticle(c, articleID)
+	return comments, nil
+}
+
+// restoreComment restores a deleted comment along with the replies deleted with it.
+// The article and the parent comment must not be deleted. It returns the number of restored replies.
+func restoreComment(c *gin.Context, commentID uint, authorID uint) (int, error) {
+	var comment models.Comment
+	result := initializers.DB.Unscoped().Where("id = ? AND deleted_at IS NOT NULL", commentID).Limit(1).Find(&comment)
+	if result.RowsAffected == 0 || (authorID != 0 && comment.AuthorID != authorID) {
+		return 0, errors.New("failed to find the comment in the trash")
+	}
+	if result := initializers.DB.Select("id").Where("id = ?", comment.ArticleID).Limit(1).Find(&models.Article{}); result.RowsAffected == 0 {
+		return 0, errors.New("failed to restore the comment: Its article has been deleted")
+	}
+	if comment.ParentID != nil {
+		if result := initializers.DB.Select("id").Where("id = ?", *comment.ParentID).Limit(1).Find(&models.Comment{}); result.RowsAffected == 0 {
+			return 0, errors.New("failed to restore the comment: The comment it replies to has been deleted")
+		}
+	}
+
+	// Get the replies deleted with the comment, the ones removed before it are left in the trash
+	var replyIDs []uint
+	parentIDs := []uint{commentID}
+	for depth := 1; depth <= models.MaxCommentDepth && len(parentIDs) > 0; depth++ {
+		var ids []uint
+		initializers.DB.Unscoped().Model(&models.Comment{}).
+			Where("parent_id IN (?) AND deleted_at >= ?", parentIDs, comment.DeletedAt.Time).
+			Pluck("id", &ids)
+		replyIDs = append(replyIDs, ids...)
+		parentIDs = ids
+	}
+
+	result = initializers.DB.Unscoped().Model(&models.Comment{}).
+		Where("id IN (?)", append([]uint{commentID}, replyIDs...)).
+		Update("deleted_at", nil)
+	if result.Error != nil {
+		return 0, errors.New("failed to restore the comment")
+	}
+
+	// Update the search index
+	utils.IndexComment(c, commentID)
+	for _, replyID := range replyIDs {
+		utils.IndexComment(c, replyID)
+	}
+	return len(replyIDs), nil
+}
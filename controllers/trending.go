@@ -0,0 +1,81 @@
+package controllers
+
+import (
+	"auth/initializers"
+	"auth/models"
+	"auth/utils"
+	"net/http"
+	"sort"
+
+	"github.com/gin-gonic/gin"
+)
+
+// FetchTrendingArticles retrieves the hottest articles of a trending window, the hottest first.
+// The "window" query parameter selects the day (default) or the week, and "limit" the number of articles.
+// Readers get previews of the premium articles of the authors they have not subscribed to (see gateArticles).
+func FetchTrendingArticles(c *gin.Context) {
+	defer func() {
+		if err := recover(); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err, "request_id": c.GetString(initializers.RequestIDKey)})
+		}
+	}()
+
+	// Get the trending window and the limit off the query string
+	window, ok := utils.TrendingWindows[c.DefaultQuery("window", "day")]
+	if !ok {
+		panic("Invalid window: Must be day or week")
+	}
+	limit := getTagLimit(c)
+
+	// Get the format of the article bodies
+	format := getArticleFormat(c)
+
+	// Get the hottest articles off the trending set
+	// Fetch more than the limit, since some of them may no longer be listed
+	ids, scores, err := utils.GetTrending(window, 2*limit)
+	if err != nil {
+		panic("Failed to get the trending articles")
+	}
+
+	// Get the listed articles from the database
+	// Use JOIN to get the author's email
+	articles := []map[string]interface{}{}
+	if len(ids) > 0 {
+		result := initializers.DB.Model(&models.Article{}).
+			Joins("JOIN users ON articles.author_id = users.id").
+			Select("articles.id as id", "articles.author_id as author_id", "articles.title as title", "articles.slug as slug", "articles.body as body", "articles.body_html as body_html", "articles.toc as toc", "articles.likes as likes", "articles.dislikes as dislikes", "articles.free as free", "articles.category_id as category_id", "users.email as author").
+			Where("articles.id IN (?) AND articles.status = ? AND articles.unlisted = ?", ids, models.Approved, false).
+			Find(&articles)
+		if result.Error != nil {
+			panic("Failed to get the articles from the database")
+		}
+	}
+
+	// Order the articles by hot score and keep the hottest ones
+	sort.SliceStable(articles, func(i, j int) bool {
+		return scores[articles[i]["id"].(uint)] > scores[articles[j]["id"].(uint)]
+	})
+	if len(articles) > limit {
+		articles = articles[:limit]
+	}
+
+	// Convert the author's email to a string and add the hot score
+	for i := range articles {
+		if email, ok := articles[i]["author"].([]byte); ok {
+			articles[i]["author"] = string(email)
+		}
+		articles[i]["hot_score"] = scores[articles[i]["id"].(uint)]
+	}
+
+	// Replace the premium articles by their previews, then set the bodies in the requested format
+	user, _ := c.Get("user")
+	gateArticles(user.(models.User).ID, articles)
+	formatArticles(format, articles)
+
+	// Return a success response with the trending articles
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Trending articles retrieved successfully",
+		"window":   window.Name,
+		"articles": articles,
+	})
+}
@@ -6,6 +6,7 @@ import (
 	"auth/utils"
 	"errors"
 	"fmt"
+	"log/slog"
 	"math/bits"
 	"net/http"
 	"os"
@@ -25,11 +26,12 @@ func Fetch(c *gin.Context) {
 	// Get the user off the context
 	user, _ := c.Get("user")
 	userMap := map[string]interface{}{
-		"id":      user.(models.User).ID,
-		"email":   user.(models.User).Email,
-		"address": user.(models.User).Address,
-		"credits": user.(models.User).Credits,
-		"subfee":  user.(models.User).Subfee,
+		"id":             user.(models.User).ID,
+		"email":          user.(models.User).Email,
+		"address":        user.(models.User).Address,
+		"credits":        user.(models.User).Credits,
+		"subfee":         user.(models.User).Subfee,
+		"preview_length": user.(models.User).PreviewLength,
 	}
 
 	// Return a success response with the user
@@ -43,8 +45,8 @@ func Fetch(c *gin.Context) {
 func Modify(c *gin.Context) {
 	defer func() {
 		if err := recover(); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err})
-			initializers.LOGGER.Error("Modify failed", "error", err, "sub", utils.GetSubInfo(c), "params", c.MustGet("params"))
+			c.JSON(http.StatusBadRequest, gin.H{"error": err, "request_id": c.GetString(initializers.RequestIDKey)})
+			initializers.LOGGER.ErrorContext(c, "Modify failed", "error", err, "sub", utils.GetSubInfo(c), "params", c.MustGet("params"))
 		}
 	}()
 
@@ -63,10 +65,11 @@ func Modify(c *gin.Context) {
 
 	// Get the email and password off the request body
 	var body struct {
-		Email    string `json:"email"`
-		Password string `json:"password"`
-		Address  string `json:"address"`
-		Subfee   uint   `json:"subfee"`
+		Email         string `json:"email"`
+		Password      string `json:"password"`
+		Address       string `json:"address"`
+		Subfee        uint   `json:"subfee"`
+		PreviewLength uint   `json:"preview_length"`
 	}
 	c.ShouldBind(&body)
 
@@ -80,6 +83,11 @@ func Modify(c *gin.Context) {
 		panic("Invalid subfee value")
 	}
 
+	// Validate the preview length value
+	if body.PreviewLength > 2000 {
+		panic("Invalid preview length value")
+	}
+
 	// Hash the password
 	var hashedPassword string
 	if body.Password != "" {
@@ -88,7 +96,7 @@ func Modify(c *gin.Context) {
 	}
 
 	// Update the user in the database
-	result := initializers.DB.Where("id = ?", userID).Updates(&models.User{Email: body.Email, Password: hashedPassword, Address: body.Address, Subfee: body.Subfee})
+	result := initializers.DB.Where("id = ?", userID).Updates(&models.User{Email: body.Email, Password: hashedPassword, Address: body.Address, Subfee: body.Subfee, PreviewLength: body.PreviewLength})
 	if result.Error != nil || result.RowsAffected == 0 {
 		panic("Failed to update user in the database")
 	}
@@ -141,14 +149,14 @@ func Modify(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{
 		"message": message,
 	})
-	initializers.LOGGER.Info(message, "sub", utils.GetSubInfo(c), "obj", objInfo, "data", dataInfo)
+	utils.Audit(c, slog.LevelInfo, message, objInfo, dataInfo)
 }
 
 // SignIn 用于记录当前用户在当天完成签到
 func SignIn(c *gin.Context) {
 	defer func() {
 		if err := recover(); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err})
+			c.JSON(http.StatusBadRequest, gin.H{"error": err, "request_id": c.GetString(initializers.RequestIDKey)})
 		}
 	}()
 
@@ -179,7 +187,7 @@ func SignIn(c *gin.Context) {
 func SignInCount(c *gin.Context) {
 	defer func() {
 		if err := recover(); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err})
+			c.JSON(http.StatusBadRequest, gin.H{"error": err, "request_id": c.GetString(initializers.RequestIDKey)})
 		}
 	}()
 
@@ -204,7 +212,8 @@ func SignInCount(c *gin.Context) {
 func SignInAward(c *gin.Context) {
 	defer func() {
 		if err := recover(); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err})
+			c.JSON(http.StatusBadRequest, gin.H{"error": err, "request_id": c.GetString(initializers.RequestIDKey)})
+			initializers.LOGGER.ErrorContext(c, "SignInAward Failed", "error", err, "sub", utils.GetSubInfo(c))
 		}
 	}()
 
@@ -231,16 +240,41 @@ func SignInAward(c *gin.Context) {
 	// (2) count >= 15 -> 50 credits
 	// (3) 10 credits
 	award := 10 + 40*utils.BoolToUint(count >= 15) + 50*utils.BoolToUint(count >= 28)
+
+	// [Get the user credits from the database]
+	var credits uint
+	initializers.DB.Model(&models.User{}).Select("credits").Where("id = ?", userID).Find(&credits)
+
 	result := initializers.DB.Model(&models.User{}).Where("id = ?", userID).Update("credits", gorm.Expr("credits + ?", award))
 	if result.Error != nil || result.RowsAffected == 0 {
 		panic("Failed to award sign-in credits")
 	}
 
+	// Count the credits for the statistics
+	utils.IncrStat(utils.StatCreditsMoved, int64(award))
+
+	// [Prepare the object and data information for logging]
+	objInfo := utils.ObjInfo{
+		Op:    utils.OpUpdate,
+		Table: models.User{}.TableName(),
+		ID:    userID,
+	}
+	dataInfo := utils.DataInfo{
+		OldData: map[string]interface{}{
+			"credits": credits,
+		},
+		NewData: map[string]interface{}{
+			"credits": credits + award,
+		},
+	}
+
 	// Return a success response with the award
+	message := "Sign-in award granted successfully"
 	c.JSON(http.StatusOK, gin.H{
-		"message": "Sign-in award granted successfully",
+		"message": message,
 		"award":   award,
 	})
+	utils.Audit(c, slog.LevelInfo, message, objInfo, dataInfo)
 }
 
 func getSignInCount(userID uint) (int, error) {
@@ -268,7 +302,7 @@ func getSignInCount(userID uint) (int, error) {
 func FetchUsers(c *gin.Context) {
 	defer func() {
 		if err := recover(); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err})
+			c.JSON(http.StatusBadRequest, gin.H{"error": err, "request_id": c.GetString(initializers.RequestIDKey)})
 		}
 	}()
 
@@ -304,7 +338,7 @@ func FetchUsers(c *gin.Context) {
 func FetchUser(c *gin.Context) {
 	defer func() {
 		if err := recover(); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err})
+			c.JSON(http.StatusBadRequest, gin.H{"error": err, "request_id": c.GetString(initializers.RequestIDKey)})
 		}
 	}()
 
@@ -387,7 +421,7 @@ func fetchUser(userID int) (*models.User, error) {
 func GetAvatar(c *gin.Context) {
 	defer func() {
 		if err := recover(); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err})
+			c.JSON(http.StatusBadRequest, gin.H{"error": err, "request_id": c.GetString(initializers.RequestIDKey)})
 		}
 	}()
 
@@ -422,7 +456,8 @@ func GetAvatar(c *gin.Context) {
 func UploadAvatar(c *gin.Context) {
 	defer func() {
 		if err := recover(); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err})
+			c.JSON(http.StatusBadRequest, gin.H{"error": err, "request_id": c.GetString(initializers.RequestIDKey)})
+			initializers.LOGGER.ErrorContext(c, "UploadAvatar Failed", "error", err, "sub", utils.GetSubInfo(c))
 		}
 	}()
 
@@ -479,8 +514,25 @@ func UploadAvatar(c *gin.Context) {
 		panic("Failed to update avatar file name in the database")
 	}
 
+	// [Prepare the object and data information for logging]
+	objInfo := utils.ObjInfo{
+		Op:    utils.OpUpdate,
+		Table: models.User{}.TableName(),
+		ID:    userID,
+	}
+	dataInfo := utils.DataInfo{
+		OldData: map[string]interface{}{
+			"avatar": oldFileName,
+		},
+		NewData: map[string]interface{}{
+			"avatar": fileName,
+		},
+	}
+
 	// Return a success response
+	message := "Avatar uploaded successfully"
 	c.JSON(http.StatusOK, gin.H{
-		"message": "Avatar uploaded successfully",
+		"message": message,
 	})
+	utils.Audit(c, slog.LevelInfo, message, objInfo, dataInfo)
 }
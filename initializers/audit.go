@@ -0,0 +1,39 @@
+package initializers
+
+import (
+	"auth/models"
+	"sync"
+)
+
+// auditRoutes caches the configured audit routes, keyed by "METHOD PATH".
+var auditRoutes = struct {
+	sync.RWMutex
+	m map[string]bool
+}{m: make(map[string]bool)}
+
+func InitAudit() {
+	LoadAuditRoutes()
+}
+
+// LoadAuditRoutes reloads the audit routes from the database.
+// It must be called after the audit routes are modified.
+func LoadAuditRoutes() {
+	var routes []models.AuditRoute
+	if err := DB.Find(&routes).Error; err != nil {
+		panic("Failed to load audit routes: " + err.Error())
+	}
+	m := make(map[string]bool, len(routes))
+	for _, route := range routes {
+		m[route.Method+" "+route.Path] = true
+	}
+	auditRoutes.Lock()
+	auditRoutes.m = m
+	auditRoutes.Unlock()
+}
+
+// IsAuditedRoute reports whether the route has been configured to be audited.
+func IsAuditedRoute(method string, path string) bool {
+	auditRoutes.RLock()
+	defer auditRoutes.RUnlock()
+	return auditRoutes.m[method+" "+path]
+}
@@ -21,8 +21,18 @@ func ConnectToDB() {
 }
 
 func SyncDB() {
-	err := DB.AutoMigrate(&models.User{}, &models.Article{}, &models.Comment{}, &models.Subscribe{}, &models.Discount{})
+	// The default audit routes are only seeded when the table is created, so that the admins may delete them
+	seedAudit := !DB.Migrator().HasTable(&models.AuditRoute{})
+
+	err := DB.AutoMigrate(&models.User{}, &models.Article{}, &models.Comment{}, &models.Subscribe{}, &models.Discount{}, &models.AuditRoute{}, &models.Alert{}, &models.ArticleRevision{}, &models.Tag{}, &models.Category{}, &models.SearchDocument{}, &models.Notification{}, &models.Report{}, &models.ModerationRule{}, &models.ModerationVerdict{}, &models.ArticleViewStat{}, &models.ArticleSlug{}, &models.Attachment{}, &models.Series{}, &models.SeriesArticle{}, &models.ArticleAuthor{}, &models.Bookmark{}, &models.ReadingList{}, &models.ReadingListItem{}, &models.ArticleLike{})
 	if err != nil {
 		panic("Failed to synchronize database: " + err.Error())
 	}
+
+	if seedAudit {
+		// The admin user listing is audited by default
+		if err := DB.Create(&models.AuditRoute{Method: "GET", Path: "/api/bg/users"}).Error; err != nil {
+			panic("Failed to seed audit routes: " + err.Error())
+		}
+	}
 }
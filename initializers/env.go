@@ -2,6 +2,8 @@ package initializers
 
 import (
 	"os"
+	"strconv"
+	"strings"
 
 	"github.com/joho/godotenv"
 )
@@ -12,6 +14,27 @@ var (
 	AvatarDefault  string
 	LogFileDir     string
 	LogFileDefault string
+	// Optional path of a JSON redaction policy for the logs (see RedactionPolicy)
+	RedactionPolicyFile string
+	// Whether the anomaly detector denies the offending users temporarily, and for how long
+	AnomalyAutoDeny    bool
+	AnomalyDenyMinutes int
+	// Backend of the search index: "mysql" (FULLTEXT, default) or "memory" (embedded inverted index)
+	SearchBackend string
+	// Number of distinct readers whose open reports hide an article or a comment until it is moderated
+	ReportHideThreshold int
+	// Whether the articles passing the moderation rules are approved without waiting for a moderator
+	ModerationAutoApprove bool
+	// Number of days the deleted articles and comments stay in the trash before they are purged
+	TrashRetentionDays int
+	// Public base URL of the API, for the absolute links of the feeds
+	PublicURL string
+	// Directory of the article attachments, their maximum size and the default storage quota of a user, in MB
+	AttachmentDir     string
+	AttachmentMaxMB   int
+	AttachmentQuotaMB int
+	// Maximum size of an imported archive in MB
+	ImportMaxMB int
 )
 
 func LoadEnvVar() {
@@ -24,4 +47,43 @@ func LoadEnvVar() {
 	AvatarDefault = os.Getenv("AVATAR_DEFAULT")
 	LogFileDir = os.Getenv("LOG_FILE_DIR")
 	LogFileDefault = os.Getenv("LOG_FILE_DEFAULT")
+	RedactionPolicyFile = os.Getenv("REDACTION_POLICY")
+	AnomalyAutoDeny, _ = strconv.ParseBool(os.Getenv("ANOMALY_AUTO_DENY"))
+	AnomalyDenyMinutes, err = strconv.Atoi(os.Getenv("ANOMALY_DENY_MINUTES"))
+	if err != nil || AnomalyDenyMinutes <= 0 {
+		AnomalyDenyMinutes = 30
+	}
+	SearchBackend = os.Getenv("SEARCH_BACKEND")
+	if SearchBackend == "" {
+		SearchBackend = "mysql"
+	}
+	ReportHideThreshold, err = strconv.Atoi(os.Getenv("REPORT_HIDE_THRESHOLD"))
+	if err != nil || ReportHideThreshold <= 0 {
+		ReportHideThreshold = 5
+	}
+	ModerationAutoApprove, _ = strconv.ParseBool(os.Getenv("MODERATION_AUTO_APPROVE"))
+	TrashRetentionDays, err = strconv.Atoi(os.Getenv("TRASH_RETENTION_DAYS"))
+	if err != nil || TrashRetentionDays <= 0 {
+		TrashRetentionDays = 30
+	}
+	PublicURL = strings.TrimRight(os.Getenv("PUBLIC_URL"), "/")
+	if PublicURL == "" {
+		PublicURL = "http://localhost:8080"
+	}
+	AttachmentDir = os.Getenv("ATTACHMENT_DIR")
+	if AttachmentDir == "" {
+		AttachmentDir = "./attachments"
+	}
+	AttachmentMaxMB, err = strconv.Atoi(os.Getenv("ATTACHMENT_MAX_MB"))
+	if err != nil || AttachmentMaxMB <= 0 {
+		AttachmentMaxMB = 10
+	}
+	AttachmentQuotaMB, err = strconv.Atoi(os.Getenv("ATTACHMENT_QUOTA_MB"))
+	if err != nil || AttachmentQuotaMB <= 0 {
+		AttachmentQuotaMB = 100
+	}
+	ImportMaxMB, err = strconv.Atoi(os.Getenv("IMPORT_MAX_MB"))
+	if err != nil || ImportMaxMB <= 0 {
+		ImportMaxMB = 20
+	}
 }
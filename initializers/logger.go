@@ -1,6 +1,7 @@
 package initializers
 
 import (
+	"context"
 	"log/slog"
 	"os"
 	"path/filepath"
@@ -8,6 +9,14 @@ import (
 
 var LOGGER *slog.Logger
 
+// RequestIDKey is the key under which the request ID is stored in the context.
+const RequestIDKey = "request_id"
+
+// WithRequestID returns a context carrying the request ID, for the work that outlives its request (e.g. background jobs).
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, RequestIDKey, id)
+}
+
 func InitLogger() {
 	// Open the log file or create it if it doesn't exist
 	EnsureLogFileDefault()
@@ -23,8 +32,34 @@ func InitLogger() {
 	})
 
 	// Create a logger with the handler
-	LOGGER = slog.New(handler)
+	// (1) Wrap the handler so that sensitive data is redacted from each record
+	// (2) Wrap the handler so that the request ID in the context is attached to each record
+	InitRedaction()
+	LOGGER = slog.New(contextHandler{redactHandler{Handler: handler, policy: REDACTION}})
 
 	// Set the logger as the default logger
 	slog.SetDefault(LOGGER)
 }
+
+// contextHandler is a slog handler that attaches the request ID carried by the context to each record.
+// Use the *Context variants of the logger methods (e.g. InfoContext) to pass the context.
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if ctx != nil {
+		if id, ok := ctx.Value(RequestIDKey).(string); ok && id != "" {
+			r.AddAttrs(slog.String(RequestIDKey, id))
+		}
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
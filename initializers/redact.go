@@ -0,0 +1,254 @@
+package initializers
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// RedactionMode is the way a sensitive value is redacted:
+const (
+	RedactMask = "mask" // replace the value with asterisks
+	RedactHash = "hash" // replace the value with a keyed hash, so that equal values can still be correlated
+	RedactDrop = "drop" // remove the attribute (or the matched substring) entirely
+)
+
+// FieldRule redacts the attributes whose path matches Path.
+// Path is a dot-separated attribute path such as "data.old_data.email", in which "*" matches a single segment.
+// A path without any dot matches the key at any depth, e.g. "password".
+type FieldRule struct {
+	Path string `json:"path"`
+	Mode string `json:"mode"`
+}
+
+// DetectorRule redacts the substrings of string values (and of log messages) that match Pattern.
+type DetectorRule struct {
+	Name    string `json:"name"`
+	Pattern string `json:"pattern"`
+	Mode    string `json:"mode"`
+	re      *regexp.Regexp
+}
+
+// RedactionPolicy is the set of rules applied to every log record.
+type RedactionPolicy struct {
+	Fields    []FieldRule    `json:"fields"`
+	Detectors []DetectorRule `json:"detectors"`
+}
+
+// DefaultRedactionPolicy is used if no policy file is configured in REDACTION_POLICY.
+var DefaultRedactionPolicy = RedactionPolicy{
+	Fields: []FieldRule{
+		{Path: "password", Mode: RedactMask},
+		{Path: "code", Mode: RedactDrop},
+		{Path: "email", Mode: RedactHash},
+		{Path: "address", Mode: RedactMask},
+	},
+	Detectors: []DetectorRule{
+		{Name: "email", Pattern: `[\w.+-]+@([\w-]+\.)+[\w-]{2,}`, Mode: RedactHash},
+	},
+}
+
+var REDACTION *RedactionPolicy
+
+func InitRedaction() {
+	policy := DefaultRedactionPolicy
+	if RedactionPolicyFile != "" {
+		content, err := os.ReadFile(RedactionPolicyFile)
+		if err != nil {
+			panic("Failed to read redaction policy: " + err.Error())
+		}
+		policy = RedactionPolicy{}
+		if err := json.Unmarshal(content, &policy); err != nil {
+			panic("Failed to parse redaction policy: " + err.Error())
+		}
+	}
+
+	// Validate the rules and compile the detectors
+	for _, rule := range policy.Fields {
+		if !isRedactionMode(rule.Mode) {
+			panic("Invalid redaction mode for field " + rule.Path + ": " + rule.Mode)
+		}
+	}
+	detectors := make([]DetectorRule, len(policy.Detectors))
+	for i, rule := range policy.Detectors {
+		if !isRedactionMode(rule.Mode) {
+			panic("Invalid redaction mode for detector " + rule.Name + ": " + rule.Mode)
+		}
+		re, err := regexp.Compile(rule.Pattern)
+		if err != nil {
+			panic("Invalid pattern for detector " + rule.Name + ": " + err.Error())
+		}
+		rule.re = re
+		detectors[i] = rule
+	}
+	policy.Detectors = detectors
+
+	REDACTION = &policy
+}
+
+func isRedactionMode(mode string) bool {
+	return mode == RedactMask || mode == RedactHash || mode == RedactDrop
+}
+
+// redactHandler is a slog handler that applies the redaction policy to the message and every attribute of each record.
+type redactHandler struct {
+	slog.Handler
+	policy *RedactionPolicy
+	groups []string
+}
+
+func (h redactHandler) Handle(ctx context.Context, r slog.Record) error {
+	record := slog.NewRecord(r.Time, r.Level, h.policy.redactString(r.Message), r.PC)
+	r.Attrs(func(a slog.Attr) bool {
+		if attr, ok := h.policy.redactAttr(h.groups, a); ok {
+			record.AddAttrs(attr)
+		}
+		return true
+	})
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h redactHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	var redacted []slog.Attr
+	for _, a := range attrs {
+		if attr, ok := h.policy.redactAttr(h.groups, a); ok {
+			redacted = append(redacted, attr)
+		}
+	}
+	return redactHandler{h.Handler.WithAttrs(redacted), h.policy, h.groups}
+}
+
+func (h redactHandler) WithGroup(name string) slog.Handler {
+	groups := append(append([]string{}, h.groups...), name)
+	return redactHandler{h.Handler.WithGroup(name), h.policy, groups}
+}
+
+// redactAttr redacts an attribute located under the given path.
+// It returns false if the attribute should be dropped.
+func (p *RedactionPolicy) redactAttr(parent []string, a slog.Attr) (slog.Attr, bool) {
+	keyPath := append(append([]string{}, parent...), a.Key)
+	if mode, ok := p.matchField(keyPath); ok {
+		if mode == RedactDrop {
+			return slog.Attr{}, false
+		}
+		return slog.String(a.Key, p.apply(mode, a.Value.Resolve().String())), true
+	}
+
+	value := a.Value.Resolve()
+	switch value.Kind() {
+	case slog.KindGroup:
+		var attrs []any
+		for _, ga := range value.Group() {
+			if attr, ok := p.redactAttr(keyPath, ga); ok {
+				attrs = append(attrs, attr)
+			}
+		}
+		return slog.Group(a.Key, attrs...), true
+	case slog.KindString:
+		return slog.String(a.Key, p.redactString(value.String())), true
+	case slog.KindAny:
+		v := value.Any()
+		if err, ok := v.(error); ok {
+			if _, ok := v.(json.Marshaler); !ok {
+				return slog.String(a.Key, p.redactString(err.Error())), true
+			}
+		}
+		// Normalize structs (e.g. utils.DataInfo) into plain maps and slices, so that nested fields can be matched
+		content, err := json.Marshal(v)
+		if err != nil {
+			return slog.String(a.Key, p.redactString(fmt.Sprint(v))), true
+		}
+		var normalized interface{}
+		if err := json.Unmarshal(content, &normalized); err != nil {
+			return a, true
+		}
+		redacted, _ := p.redactValue(keyPath, normalized)
+		return slog.Any(a.Key, redacted), true
+	default:
+		return slog.Attr{Key: a.Key, Value: value}, true
+	}
+}
+
+// redactValue redacts a JSON-like value located under the given path.
+// It returns false if the value should be dropped.
+func (p *RedactionPolicy) redactValue(keyPath []string, v interface{}) (interface{}, bool) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		redacted := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			childPath := append(append([]string{}, keyPath...), key)
+			if mode, ok := p.matchField(childPath); ok {
+				if mode != RedactDrop && value != nil {
+					redacted[key] = p.apply(mode, fmt.Sprint(value))
+				} else if mode != RedactDrop {
+					redacted[key] = nil
+				}
+				continue
+			}
+			if value, ok := p.redactValue(childPath, value); ok {
+				redacted[key] = value
+			}
+		}
+		return redacted, true
+	case []interface{}:
+		redacted := make([]interface{}, 0, len(v))
+		for _, value := range v {
+			if value, ok := p.redactValue(keyPath, value); ok {
+				redacted = append(redacted, value)
+			}
+		}
+		return redacted, true
+	case string:
+		return p.redactString(v), true
+	default:
+		return v, true
+	}
+}
+
+// matchField returns the mode of the first field rule matching the path.
+func (p *RedactionPolicy) matchField(keyPath []string) (string, bool) {
+	for _, rule := range p.Fields {
+		var matched bool
+		if strings.Contains(rule.Path, ".") {
+			matched, _ = path.Match(strings.ReplaceAll(rule.Path, ".", "/"), strings.Join(keyPath, "/"))
+		} else {
+			matched, _ = path.Match(rule.Path, keyPath[len(keyPath)-1])
+		}
+		if matched {
+			return rule.Mode, true
+		}
+	}
+	return "", false
+}
+
+// redactString applies the detectors to a string value.
+func (p *RedactionPolicy) redactString(s string) string {
+	for _, rule := range p.Detectors {
+		s = rule.re.ReplaceAllStringFunc(s, func(match string) string {
+			return p.apply(rule.Mode, match)
+		})
+	}
+	return s
+}
+
+// apply redacts a value with the given mode.
+func (p *RedactionPolicy) apply(mode string, s string) string {
+	switch mode {
+	case RedactHash:
+		mac := hmac.New(sha256.New, []byte(SecretKey))
+		mac.Write([]byte(s))
+		return "hash:" + hex.EncodeToString(mac.Sum(nil))[:16]
+	case RedactDrop:
+		return ""
+	default:
+		return "*******"
+	}
+}
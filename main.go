@@ -5,6 +5,7 @@ import (
 	"auth/initializers"
 	"auth/middlewares"
 	"auth/tasks"
+	"auth/utils"
 
 	"github.com/gin-gonic/gin"
 )
@@ -17,13 +18,22 @@ func init() {
 	initializers.ConnectToRedis()
 	initializers.SyncDB()
 	initializers.InitCasbin()
+	initializers.InitAudit()
 	initializers.EnsureAvatarDefault()
+	utils.InitSearch()
 	tasks.InitSeckillProcessor()
+	tasks.InitDenialSweeper()
+	tasks.InitArticleScheduler()
+	tasks.InitTrashPurger()
+	tasks.InitTrendingDecayer()
+	tasks.InitViewFlusher()
+	tasks.InitAttachmentCollector()
+	tasks.InitLikeReconciler()
 }
 
 func main() {
 	r := gin.Default()
-	// r.Use(middlewares.RequestID) // Generate a unique request ID for each request
+	r.Use(middlewares.RequestID) // Generate a unique request ID for each request
 
 	apiGroup := r.Group("/api")
 	{
@@ -33,51 +43,127 @@ func main() {
 		apiGroup.POST("/send_code", controllers.SendCode)
 		apiGroup.POST("/verify_code", controllers.VerifyCode)
 		// ******************************************
+		apiGroup.GET("/feeds/articles", controllers.FetchPublicFeed)
+		apiGroup.GET("/feeds/authors/:id", controllers.FetchAuthorFeed)
 	}
 
-	userInterfaceGroup := apiGroup.Group("/ui", middlewares.RequireAuthentication, middlewares.RequireAuthorization)
+	userInterfaceGroup := apiGroup.Group("/ui", middlewares.RequireAuthentication, middlewares.RequireAuthorization, middlewares.Audit)
 	{
 		userInterfaceGroup.GET("/myself", controllers.Fetch)
 		userInterfaceGroup.PUT("/myself", controllers.Modify) // Log Audit
 		// ************** Using Redis for Sign in **************
 		userInterfaceGroup.POST("/signin", controllers.SignIn)
 		userInterfaceGroup.GET("/signin/count", controllers.SignInCount)
-		userInterfaceGroup.POST("/signin/award", controllers.SignInAward)
+		userInterfaceGroup.POST("/signin/award", controllers.SignInAward) // Log Audit
 		// *****************************************************
 		userInterfaceGroup.GET("/users", controllers.FetchUsers)
 		// ************** Using Redis for Caching **************
 		userInterfaceGroup.GET("/users/:id", controllers.FetchUser)
 		// *****************************************************
 		userInterfaceGroup.GET("/avatar/:id", controllers.GetAvatar)
-		userInterfaceGroup.POST("/avatar", controllers.UploadAvatar)
-		userInterfaceGroup.POST("/subscribe", controllers.Subscribe)
+		userInterfaceGroup.POST("/avatar", controllers.UploadAvatar) // Log Audit
+		userInterfaceGroup.GET("/attachments", controllers.FetchAttachments)
+		userInterfaceGroup.GET("/attachments/:id", controllers.GetAttachment)
+		userInterfaceGroup.POST("/attachments", controllers.UploadAttachment) // Log Audit
+		userInterfaceGroup.DELETE("/attachments", controllers.DelAttachment)  // Log Audit
+		userInterfaceGroup.POST("/subscribe", controllers.Subscribe)          // Log Audit
 		// ************** Using Redis for Seckill **************
-		userInterfaceGroup.POST("/seckill", controllers.Seckill)
+		userInterfaceGroup.POST("/seckill", controllers.Seckill) // Log Audit
 		// *****************************************************
 		userInterfaceGroup.GET("/discounts/:id", controllers.FetchUserDiscounts)
-		userInterfaceGroup.POST("/discounts", controllers.PostDiscount)
+		userInterfaceGroup.POST("/discounts", controllers.PostDiscount) // Log Audit
+		userInterfaceGroup.GET("/articles", controllers.FetchArticles)
 		userInterfaceGroup.GET("/articles/:id", controllers.FetchUserArticles)
-		userInterfaceGroup.POST("/articles", controllers.PostArticle)     // Log Audit
-		userInterfaceGroup.DELETE("/articles", controllers.RemoveArticle) // Log Audit
+		userInterfaceGroup.GET("/article/:id", controllers.FetchArticle)
+		userInterfaceGroup.GET("/article/slug/:slug", controllers.FetchArticleBySlug)
+		// ************** Using Redis for Trending **************
+		userInterfaceGroup.GET("/articles/trending", controllers.FetchTrendingArticles)
+		// ******************************************************
+		userInterfaceGroup.POST("/articles", controllers.PostArticle)             // Log Audit
+		userInterfaceGroup.PUT("/articles", controllers.EditArticle)              // Log Audit
+		userInterfaceGroup.DELETE("/articles", controllers.RemoveArticle)         // Log Audit
+		userInterfaceGroup.PUT("/articles/schedule", controllers.ScheduleArticle) // Log Audit
+		userInterfaceGroup.GET("/articles/coauthors", controllers.FetchCoauthors)
+		userInterfaceGroup.POST("/articles/coauthors", controllers.InviteCoauthor)   // Log Audit
+		userInterfaceGroup.DELETE("/articles/coauthors", controllers.RemoveCoauthor) // Log Audit
+		userInterfaceGroup.GET("/coauthors/invitations", controllers.FetchInvitations)
+		userInterfaceGroup.PUT("/coauthors/invitations", controllers.RespondInvitation) // Log Audit
+		userInterfaceGroup.GET("/articles/export", controllers.ExportArticles)
+		userInterfaceGroup.GET("/articles/import", controllers.FetchImportJob)
+		userInterfaceGroup.POST("/articles/import", controllers.ImportArticles) // Log Audit
+		userInterfaceGroup.GET("/series", controllers.FetchSeriesList)
+		userInterfaceGroup.GET("/series/:id", controllers.FetchSeries)
+		userInterfaceGroup.POST("/series", controllers.PostSeries)  // Log Audit
+		userInterfaceGroup.PUT("/series", controllers.EditSeries)   // Log Audit
+		userInterfaceGroup.DELETE("/series", controllers.DelSeries) // Log Audit
+		userInterfaceGroup.GET("/bookmarks", controllers.FetchBookmarks)
+		userInterfaceGroup.POST("/bookmarks", controllers.PostBookmark)  // Log Audit
+		userInterfaceGroup.DELETE("/bookmarks", controllers.DelBookmark) // Log Audit
+		userInterfaceGroup.GET("/lists", controllers.FetchReadingLists)
+		userInterfaceGroup.GET("/lists/:id", controllers.FetchReadingList)
+		userInterfaceGroup.POST("/lists", controllers.PostReadingList)            // Log Audit
+		userInterfaceGroup.PUT("/lists", controllers.EditReadingList)             // Log Audit
+		userInterfaceGroup.DELETE("/lists", controllers.DelReadingList)           // Log Audit
+		userInterfaceGroup.POST("/lists/items", controllers.PostReadingListItem)  // Log Audit
+		userInterfaceGroup.DELETE("/lists/items", controllers.DelReadingListItem) // Log Audit
+		userInterfaceGroup.GET("/articles/revisions", controllers.FetchArticleRevisions)
+		userInterfaceGroup.GET("/articles/revisions/diff", controllers.DiffArticleRevisions)
+		userInterfaceGroup.POST("/articles/revisions/restore", controllers.RestoreArticleRevision) // Log Audit
 		// ************** Using Redis for Leaderboard **************
-		userInterfaceGroup.POST("/articles/like", controllers.LikeArticle)
+		userInterfaceGroup.POST("/articles/like", controllers.LikeArticle) // Log Audit
 		// *********************************************************
 		userInterfaceGroup.POST("/articles/comment", controllers.PostComment)     // Log Audit
 		userInterfaceGroup.DELETE("/articles/comment", controllers.RemoveComment) // Log Audit
+		userInterfaceGroup.GET("/articles/comments", controllers.FetchArticleComments)
+		userInterfaceGroup.GET("/notifications", controllers.FetchNotifications)
+		userInterfaceGroup.PUT("/notifications", controllers.ReadNotifications)
+		userInterfaceGroup.POST("/feeds/token", controllers.RotateFeedToken) // Log Audit
+		userInterfaceGroup.GET("/tags/suggest", controllers.SuggestTags)
+		userInterfaceGroup.GET("/tags/popular", controllers.GetPopularTags)
+		userInterfaceGroup.GET("/categories", controllers.FetchCategories)
+		userInterfaceGroup.GET("/search", controllers.Search)
+		// ************** Using Redis for Analytics **************
+		userInterfaceGroup.GET("/analytics", controllers.FetchAnalytics)
+		// *******************************************************
+		userInterfaceGroup.POST("/reports", controllers.PostReport) // Log Audit
+		userInterfaceGroup.GET("/trash", controllers.FetchTrash)
+		userInterfaceGroup.POST("/trash/restore", controllers.RestoreTrash) // Log Audit
 	}
 
-	backgroundGroup := apiGroup.Group("/bg", middlewares.RequireAuthentication, middlewares.RequireAuthorization)
+	backgroundGroup := apiGroup.Group("/bg", middlewares.RequireAuthentication, middlewares.RequireAuthorization, middlewares.Audit)
 	{
 		backgroundGroup.GET("/users", controllers.GetUsers)
-		backgroundGroup.DELETE("/users", controllers.DelUser) // Log Audit
+		backgroundGroup.DELETE("/users", controllers.DelUser)         // Log Audit
+		backgroundGroup.PUT("/users/quota", controllers.SetUserQuota) // Log Audit
 		backgroundGroup.GET("/rules", controllers.GetDenials)
 		backgroundGroup.POST("/rules", controllers.AddDenial)   // Log Audit
 		backgroundGroup.DELETE("/rules", controllers.DelDenial) // Log Audit
 		backgroundGroup.GET("/articles", controllers.GetArticles)
 		backgroundGroup.PUT("/articles", controllers.SetArticleStatus) // Log Audit
+		backgroundGroup.GET("/likes/reconcile", controllers.FetchReconcileJob)
+		backgroundGroup.POST("/likes/reconcile", controllers.ReconcileLikes) // Log Audit
 		backgroundGroup.GET("/comments", controllers.GetComments)
 		backgroundGroup.PUT("/comments", controllers.SetCommentStatus) // Log Audit
+		backgroundGroup.GET("/reports", controllers.GetReports)
+		backgroundGroup.PUT("/reports", controllers.SetReportStatus) // Log Audit
+		backgroundGroup.GET("/moderation/rules", controllers.GetModerationRules)
+		backgroundGroup.POST("/moderation/rules", controllers.AddModerationRule)   // Log Audit
+		backgroundGroup.PUT("/moderation/rules", controllers.SetModerationRule)    // Log Audit
+		backgroundGroup.DELETE("/moderation/rules", controllers.DelModerationRule) // Log Audit
+		backgroundGroup.GET("/moderation/verdicts", controllers.GetModerationVerdicts)
+		backgroundGroup.GET("/trash", controllers.GetTrash)
+		backgroundGroup.POST("/trash/restore", controllers.RecoverTrash) // Log Audit
+		backgroundGroup.POST("/categories", controllers.AddCategory)     // Log Audit
+		backgroundGroup.DELETE("/categories", controllers.DelCategory)   // Log Audit
 		backgroundGroup.GET("/logs", controllers.DownloadLogFile)
+		backgroundGroup.GET("/audit/routes", controllers.GetAuditRoutes)
+		backgroundGroup.POST("/audit/routes", controllers.AddAuditRoute)   // Log Audit
+		backgroundGroup.DELETE("/audit/routes", controllers.DelAuditRoute) // Log Audit
+		backgroundGroup.GET("/alerts", controllers.GetAlerts)
+		backgroundGroup.PUT("/alerts", controllers.SetAlertStatus) // Log Audit
+		// ************** Using Redis for Statistics **************
+		backgroundGroup.GET("/stats", controllers.GetStats)
+		// ********************************************************
 	}
 
 	r.Run(":8080")
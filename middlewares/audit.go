@@ -0,0 +1,56 @@
+package middlewares
+
+import (
+	"auth/initializers"
+	"auth/utils"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Audit is a middleware that records an audit trail for the request.
+// Mutating requests are always audited, while read requests are only audited if the admin has configured their routes.
+// Handlers that record a detailed audit entry by themselves (see utils.Audit) are not recorded again.
+func Audit(c *gin.Context) {
+	method := c.Request.Method
+	path := c.FullPath()
+	if method == http.MethodGet && !initializers.IsAuditedRoute(method, path) {
+		c.Next()
+		return
+	}
+
+	// [Get the filtered parameters before the handler consumes the request body]
+	var params map[string]interface{}
+	if method == http.MethodGet || method == http.MethodDelete {
+		params = utils.GetParsedQuery(c)
+	} else {
+		params = utils.GetParsedBody(utils.GetRawBody(c))
+		utils.BlurMap(params, "password")
+	}
+
+	// Continue
+	c.Next()
+
+	// Feed the anomaly detector with the successful deletions made by the admin
+	status := c.Writer.Status()
+	if method == http.MethodDelete && strings.HasPrefix(path, "/api/bg/") && status < http.StatusBadRequest {
+		utils.RecordDeletion(c, utils.GetSubEmail(c), path)
+	}
+
+	if c.GetBool(utils.AuditedKey) {
+		return
+	}
+
+	// [Prepare the route information for logging]
+	route := map[string]interface{}{
+		"method": method,
+		"path":   path,
+		"status": status,
+	}
+	if status >= http.StatusBadRequest {
+		initializers.LOGGER.WarnContext(c, "Request audited", "sub", utils.GetSubInfo(c), "route", route, "params", params)
+	} else {
+		initializers.LOGGER.InfoContext(c, "Request audited", "sub", utils.GetSubInfo(c), "route", route, "params", params)
+	}
+}
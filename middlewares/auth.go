@@ -5,19 +5,28 @@ import (
 	"auth/models"
 	"auth/utils"
 	"net/http"
+	"regexp"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 )
 
-// RequestID is a middleware that generates a unique request ID for each request.
-// func RequestID(c *gin.Context) {
-// 	id := uuid.New().String()
-// 	c.Set("request_id", id)
-// 	c.Writer.Header().Set("X-Request-ID", id)
-// 	c.Next()
-// }
+// requestIDPattern restricts the incoming request IDs that are accepted as-is.
+var requestIDPattern = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// RequestID is a middleware that assigns a unique request ID to each request.
+// An incoming X-Request-ID header is reused if it is well-formed, otherwise a new one is generated.
+func RequestID(c *gin.Context) {
+	id := c.GetHeader("X-Request-ID")
+	if !requestIDPattern.MatchString(id) {
+		id = uuid.New().String()
+	}
+	c.Set(initializers.RequestIDKey, id)
+	c.Writer.Header().Set("X-Request-ID", id)
+	c.Next()
+}
 
 // Using JWT for authentication
 func RequireAuthentication(c *gin.Context) {
@@ -77,7 +86,9 @@ func RequireAuthorization(c *gin.Context) {
 	ok, _ := initializers.E.Enforce(sub, obj, act)
 	if !ok {
 		c.AbortWithStatus(http.StatusForbidden)
-		initializers.LOGGER.Warn("Authorization Failed", "email", sub, "path", obj, "method", act)
+		initializers.LOGGER.WarnContext(c, "Authorization Failed", "email", sub, "path", obj, "method", act)
+		// Feed the anomaly detector with the failure
+		utils.RecordForbidden(c, sub)
 		return
 	} else {
 		// Continue
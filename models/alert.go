@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// AlertStatus represents the status of a security alert.
+const (
+	AlertOpen = iota
+	AlertAcknowledged
+	AlertResolved
+)
+
+// Alert is a security alert raised by the anomaly detector.
+type Alert struct {
+	gorm.Model
+	Rule        string `gorm:"size:40;index"`
+	Subject     string `gorm:"size:191;index"` // Email of the user who triggered the alert
+	Count       int64
+	Detail      string
+	Status      uint       `gorm:"default:0"`
+	DeniedUntil *time.Time // Set if the subject has been denied temporarily
+}
+
+func (Alert) TableName() string {
+	return "alerts"
+}
@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// ArticleViewStat is the readership of an article on a day, flushed from the Redis counters.
+type ArticleViewStat struct {
+	ID        uint      `gorm:"primaryKey"`
+	ArticleID uint      `gorm:"uniqueIndex:idx_article_day"`
+	Day       time.Time `gorm:"type:date;uniqueIndex:idx_article_day"`
+	Views     int64     // Number of views
+	Readers   int64     // Number of unique readers, estimated with HyperLogLog
+	UpdatedAt time.Time
+}
+
+func (ArticleViewStat) TableName() string {
+	return "article_view_stats"
+}
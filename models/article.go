@@ -1,6 +1,8 @@
 package models
 
 import (
+	"time"
+
 	"gorm.io/gorm"
 )
 
@@ -9,6 +11,8 @@ const (
 	Pending = iota
 	Approved
 	Rejected
+	Draft     // Private to the author, not submitted for moderation yet
+	Scheduled // Approved, but goes live at its publish time
 )
 
 // LikeCode represents the type of like/dislike action on an article.
@@ -19,20 +23,57 @@ const (
 
 type Article struct {
 	gorm.Model
-	Title    string
-	Body     string
+	Title string
+	Body  string // Markdown source
+	// BodyHTML and TOC cache the sanitized HTML rendering of the body and its table of contents (JSON)
+	BodyHTML string
+	TOC      string
 	AuthorID uint
 	Status   uint `gorm:"default:0"`
 	Likes    uint `gorm:"default:0"`
 	Dislikes uint `gorm:"default:0"`
+	// PublishAt is the time when the article goes live once approved (nil for immediately)
+	PublishAt *time.Time
+	// Unlisted articles are reachable by link but excluded from the public listing
+	Unlisted bool `gorm:"default:false"`
+	// Free articles can be read in full without subscribing to the author, the others are premium
+	Free       bool  `gorm:"default:false"`
+	CategoryID *uint `gorm:"index"`
+	Tags       []Tag `gorm:"many2many:article_tags;"`
+	// Slug is the canonical name of the article in its links (nil for the articles posted before slugs)
+	Slug *string `gorm:"size:191;uniqueIndex"`
 }
 
+// MaxCommentDepth is the maximum depth of the nested replies, the top-level comments are at depth 0.
+const MaxCommentDepth = 5
+
 type Comment struct {
 	gorm.Model
 	Content   string
 	AuthorID  uint
 	Status    uint `gorm:"default:1"`
 	ArticleID uint
+	// ParentID is the comment replied to (nil for a top-level comment)
+	ParentID *uint `gorm:"index"`
+	Depth    uint  `gorm:"default:0"`
+}
+
+// ArticleRevision is a version of an article, kept every time the article is posted, edited or restored.
+type ArticleRevision struct {
+	gorm.Model
+	ArticleID uint `gorm:"uniqueIndex:idx_article_version"`
+	Version   uint `gorm:"uniqueIndex:idx_article_version"`
+	Title     string
+	Body      string
+	EditorID  uint
+}
+
+// ArticleSlug is a former slug of an article, redirected to its canonical slug.
+type ArticleSlug struct {
+	ID        uint   `gorm:"primaryKey"`
+	ArticleID uint   `gorm:"index"`
+	Slug      string `gorm:"size:191;uniqueIndex"`
+	CreatedAt time.Time
 }
 
 func (Article) TableName() string {
@@ -42,3 +83,11 @@ func (Article) TableName() string {
 func (Comment) TableName() string {
 	return "comments"
 }
+
+func (ArticleRevision) TableName() string {
+	return "article_revisions"
+}
+
+func (ArticleSlug) TableName() string {
+	return "article_slugs"
+}
@@ -0,0 +1,22 @@
+package models
+
+import (
+	"gorm.io/gorm"
+)
+
+// Attachment is a file uploaded by a user for an article, stored under the attachment directory.
+// Attachments are uploaded before or while the article is written, and bound to it once the article
+// links them; the unbound ones are garbage-collected after a grace period.
+type Attachment struct {
+	gorm.Model
+	OwnerID   uint   `gorm:"index"`
+	ArticleID *uint  `gorm:"index"`    // Nil until the attachment is bound to an article
+	FileName  string `gorm:"size:64"`  // Name of the stored file
+	Name      string `gorm:"size:255"` // Original name of the uploaded file
+	MIME      string `gorm:"size:64"`  // Content type sniffed off the content of the file
+	Size      int64
+}
+
+func (Attachment) TableName() string {
+	return "attachments"
+}
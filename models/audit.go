@@ -0,0 +1,13 @@
+package models
+
+// AuditRoute is a read route that is audited in addition to the mutating routes.
+// Path is the route pattern as registered in the router, e.g. "/api/ui/users/:id".
+type AuditRoute struct {
+	ID     uint   `gorm:"primaryKey"`
+	Method string `gorm:"size:10;uniqueIndex:idx_audit_route"`
+	Path   string `gorm:"size:191;uniqueIndex:idx_audit_route"`
+}
+
+func (AuditRoute) TableName() string {
+	return "audit_routes"
+}
@@ -0,0 +1,44 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Bookmark is an article saved by a reader to read later.
+type Bookmark struct {
+	ID        uint `gorm:"primaryKey"`
+	UserID    uint `gorm:"uniqueIndex:idx_user_article"`
+	ArticleID uint `gorm:"uniqueIndex:idx_user_article;index"`
+	CreatedAt time.Time
+}
+
+// ReadingList is a named list of articles curated by a user, visible to the other users if it is public.
+type ReadingList struct {
+	gorm.Model
+	UserID      uint   `gorm:"index"`
+	Name        string `gorm:"size:64"`
+	Description string
+	Public      bool `gorm:"default:false"`
+}
+
+// ReadingListItem is an article in a reading list.
+type ReadingListItem struct {
+	ID        uint `gorm:"primaryKey"`
+	ListID    uint `gorm:"uniqueIndex:idx_list_article"`
+	ArticleID uint `gorm:"uniqueIndex:idx_list_article;index"`
+	CreatedAt time.Time
+}
+
+func (Bookmark) TableName() string {
+	return "bookmarks"
+}
+
+func (ReadingList) TableName() string {
+	return "reading_lists"
+}
+
+func (ReadingListItem) TableName() string {
+	return "reading_list_items"
+}
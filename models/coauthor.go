@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// Status of a co-author of an article.
+const (
+	CoauthorInvited  = iota // Waiting for the invitee to accept
+	CoauthorAccepted        // Co-author of the article
+	CoauthorDeclined        // Declined by the invitee, or cancelled by the author
+)
+
+// ArticleAuthor is a co-author of an article, invited by its author (Article.AuthorID).
+// Share is the percentage of the post reward of the co-author. Reward is the amount of credits
+// taken off the author at the invitation, held until the invitee accepts it or it is refunded.
+type ArticleAuthor struct {
+	ID        uint `gorm:"primaryKey"`
+	ArticleID uint `gorm:"uniqueIndex:idx_article_user"`
+	UserID    uint `gorm:"uniqueIndex:idx_article_user;index"`
+	Share     uint
+	Reward    uint
+	Status    uint `gorm:"default:0"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (ArticleAuthor) TableName() string {
+	return "article_authors"
+}
@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// ArticleLike is the like or dislike (LikeCode) of an article by a user, the persistent copy of the Redis like sets.
+type ArticleLike struct {
+	ID        uint `gorm:"primaryKey"`
+	ArticleID uint `gorm:"uniqueIndex:idx_article_user"`
+	UserID    uint `gorm:"uniqueIndex:idx_article_user;index"`
+	Code      uint
+	CreatedAt time.Time
+}
+
+func (ArticleLike) TableName() string {
+	return "article_likes"
+}
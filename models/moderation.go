@@ -0,0 +1,53 @@
+package models
+
+import "gorm.io/gorm"
+
+// ModerationRuleKind represents the check performed by a moderation rule.
+const (
+	RuleKeyword    = "keyword"     // Pattern is a list of keywords, one per line, matched case-insensitively
+	RuleRegex      = "regex"       // Pattern is a regular expression
+	RuleLinks      = "links"       // Threshold is the maximum number of links
+	RuleDuplicate  = "duplicate"   // Threshold is the minimum similarity (%) to a recent post
+	RuleNewAccount = "new_account" // Threshold is the minimum age (hours) of the author's account
+)
+
+// Verdict represents the decision of the moderation pipeline, from the least to the most severe.
+const (
+	VerdictApprove = iota
+	VerdictHold    // Held for review by a moderator
+	VerdictReject
+)
+
+// ModerationRule is a rule of the automated moderation pipeline run on new articles and comments.
+type ModerationRule struct {
+	gorm.Model
+	Name      string `gorm:"size:64;uniqueIndex"`
+	Kind      string `gorm:"size:16"`
+	Target    string `gorm:"size:16"` // "article", "comment" or "all"
+	Pattern   string
+	Threshold int
+	Action    uint `gorm:"default:1"` // The verdict when the rule is triggered: Hold(1) or Reject(2)
+	Enabled   bool `gorm:"default:true"`
+}
+
+// ModerationVerdict is an entry of the verdict log of the moderation pipeline.
+type ModerationVerdict struct {
+	gorm.Model
+	TargetType string `gorm:"size:16;index:idx_verdict_target"`
+	TargetID   uint   `gorm:"index:idx_verdict_target"`
+	AuthorID   uint
+	Verdict    uint  `gorm:"index"`
+	RuleID     *uint // The rule that decided the verdict (nil if no rule was triggered)
+	Detail     string
+	// Signature is the MinHash signature of the shingles of the content, for the duplicate detection
+	// (empty for contents too short to be compared)
+	Signature string `gorm:"size:256"`
+}
+
+func (ModerationRule) TableName() string {
+	return "moderation_rules"
+}
+
+func (ModerationVerdict) TableName() string {
+	return "moderation_verdicts"
+}
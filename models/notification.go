@@ -0,0 +1,26 @@
+package models
+
+import (
+	"gorm.io/gorm"
+)
+
+// NotificationKind represents the kind of a notification.
+const (
+	NotifyMention  = "mention"
+	NotifyCoauthor = "coauthor" // Invitation to co-author an article
+)
+
+// Notification notifies a user of an action of another user (the actor), e.g. a mention in a comment.
+type Notification struct {
+	gorm.Model
+	UserID    uint   `gorm:"index"`
+	Kind      string `gorm:"size:16"`
+	ActorID   uint
+	ArticleID uint
+	CommentID uint
+	Read      bool `gorm:"default:false"`
+}
+
+func (Notification) TableName() string {
+	return "notifications"
+}
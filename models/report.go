@@ -0,0 +1,43 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ReportTarget represents the type of content a report is about.
+const (
+	ReportArticle = "article"
+	ReportComment = "comment"
+	ReportUser    = "user"
+)
+
+// ReportStatus represents the status of a report.
+const (
+	ReportOpen      = iota
+	ReportResolved  // The moderator took action against the target
+	ReportDismissed // The moderator found nothing wrong with the target
+)
+
+// ReportReasons are the reason codes a reader can report content for.
+var ReportReasons = []string{"spam", "harassment", "hate", "sexual", "violence", "misinformation", "copyright", "other"}
+
+// Report is a reader's flag on an article, a comment or a user, waiting in the moderation queue.
+type Report struct {
+	gorm.Model
+	ReporterID uint   `gorm:"index"`
+	TargetType string `gorm:"size:16;index:idx_report_target"`
+	TargetID   uint   `gorm:"index:idx_report_target"`
+	Reason     string `gorm:"size:16"`
+	Detail     string `gorm:"size:500"`
+	Status     uint   `gorm:"default:0;index"`
+	// The moderator's decision on the target, set once the report is closed
+	ModeratorID *uint
+	Decision    string `gorm:"size:500"`
+	DecidedAt   *time.Time
+}
+
+func (Report) TableName() string {
+	return "reports"
+}
@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+)
+
+// SearchKind represents the kind of a searchable document.
+const (
+	SearchArticle = "article"
+	SearchComment = "comment"
+)
+
+// SearchDocument is an approved article or comment kept in the search index.
+// The MySQL backend searches the FULLTEXT index of this table (the ngram parser also tokenizes CJK text).
+type SearchDocument struct {
+	ID        uint      `gorm:"primaryKey"`
+	Kind      string    `gorm:"size:16;uniqueIndex:idx_search_ref"`
+	RefID     uint      `gorm:"uniqueIndex:idx_search_ref"` // ID of the article or comment
+	ArticleID uint      `gorm:"index"`
+	AuthorID  uint      `gorm:"index"`
+	Title     string    `gorm:"index:idx_search_fulltext,class:FULLTEXT,option:WITH PARSER ngram"`
+	Body      string    `gorm:"index:idx_search_fulltext,class:FULLTEXT,option:WITH PARSER ngram"`
+	PostedAt  time.Time `gorm:"index"`
+}
+
+func (SearchDocument) TableName() string {
+	return "search_documents"
+}
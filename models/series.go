@@ -0,0 +1,32 @@
+package models
+
+import (
+	"gorm.io/gorm"
+)
+
+// MaxSeriesArticles is the maximum number of articles in a series.
+const MaxSeriesArticles = 100
+
+// Series is an ordered collection of articles of an author, such as the parts of a tutorial.
+type Series struct {
+	gorm.Model
+	AuthorID    uint   `gorm:"index"`
+	Title       string `gorm:"size:255"`
+	Description string
+}
+
+// SeriesArticle is an article in a series, at its position (from 1). An article belongs to one series at most.
+type SeriesArticle struct {
+	ID        uint `gorm:"primaryKey"`
+	SeriesID  uint `gorm:"uniqueIndex:idx_series_position"`
+	Position  uint `gorm:"uniqueIndex:idx_series_position"`
+	ArticleID uint `gorm:"uniqueIndex"`
+}
+
+func (Series) TableName() string {
+	return "series"
+}
+
+func (SeriesArticle) TableName() string {
+	return "series_articles"
+}
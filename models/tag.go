@@ -0,0 +1,26 @@
+package models
+
+import (
+	"gorm.io/gorm"
+)
+
+// Tag is a label attached to articles, many articles may share the same tag.
+type Tag struct {
+	gorm.Model
+	Name string `gorm:"size:32;uniqueIndex"`
+}
+
+// Category is a node of the category tree, the root categories have no parent.
+type Category struct {
+	gorm.Model
+	Name     string `gorm:"size:64"`
+	ParentID *uint  `gorm:"index"`
+}
+
+func (Tag) TableName() string {
+	return "tags"
+}
+
+func (Category) TableName() string {
+	return "categories"
+}
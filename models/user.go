@@ -1,5 +1,7 @@
 package models
 
+import "time"
+
 type User struct {
 	// gorm.Model
 	ID       uint   `gorm:"primaryKey" redis:"id"`
@@ -10,6 +12,14 @@ type User struct {
 	Credits  uint   `gorm:"default:100" redis:"credits"`
 	Subfee   uint   `gorm:"default:0" redis:"subfee"`
 	Role     string `gorm:"default:'user'" redis:"role"`
+	// PreviewLength is the length in characters of the excerpts of the premium articles shown to non-subscribers
+	PreviewLength uint `gorm:"default:200" redis:"preview_length"`
+	// CreatedAt is the time of the sign up (zero for the accounts created before it was recorded)
+	CreatedAt time.Time `redis:"-"`
+	// FeedToken is the hash of the secret token authenticating the user's feed reader (see utils.NewFeedToken)
+	FeedToken string `gorm:"size:64;index" redis:"-" json:"-"`
+	// StorageQuota is the storage quota of the user's attachments in MB (0 for the default quota)
+	StorageQuota uint `gorm:"default:0" redis:"-"`
 }
 
 func (User) TableName() string {
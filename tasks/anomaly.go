@@ -0,0 +1,40 @@
+package tasks
+
+import (
+	"auth/initializers"
+	"auth/utils"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// InitDenialSweeper initializes the sweeper that lifts the expired temporary denials of the anomaly detector.
+func InitDenialSweeper() {
+	go func() {
+		ticker := time.NewTicker(time.Minute)
+		defer ticker.Stop()
+		for range ticker.C {
+			sweepDenials()
+		}
+	}()
+	fmt.Println("DenialSweeper running...")
+}
+
+// sweepDenials lifts the temporary denials that have expired.
+func sweepDenials() {
+	// ZRANGEBYSCORE anomaly:denials -inf <now>
+	members, err := initializers.RDB.ZRangeByScore(initializers.RDB_CTX, utils.RedisConstants.ANOMALY_DENIAL_KEY, &redis.ZRangeBy{
+		Min: "-inf",
+		Max: strconv.FormatInt(time.Now().Unix(), 10),
+	}).Result()
+	if err != nil {
+		return
+	}
+	for _, member := range members {
+		if utils.LiftDenial(member) {
+			initializers.LOGGER.Info("Temporary denial lifted", "denial", member)
+		}
+	}
+}
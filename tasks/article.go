@@ -0,0 +1,48 @@
+package tasks
+
+import (
+	"auth/initializers"
+	"auth/models"
+	"auth/utils"
+	"context"
+	"fmt"
+	"time"
+)
+
+// InitArticleScheduler initializes the scheduler that flips the scheduled articles live at their publish time.
+func InitArticleScheduler() {
+	go func() {
+		ticker := time.NewTicker(time.Minute)
+		defer ticker.Stop()
+		for range ticker.C {
+			publishScheduledArticles()
+		}
+	}()
+	fmt.Println("ArticleScheduler running...")
+}
+
+// publishScheduledArticles approves the scheduled articles whose publish time has come.
+func publishScheduledArticles() {
+	var ids []uint
+	result := initializers.DB.Model(&models.Article{}).
+		Where("status = ? AND publish_at <= ?", models.Scheduled, time.Now()).
+		Pluck("id", &ids)
+	if result.Error != nil || len(ids) == 0 {
+		return
+	}
+
+	// Keep the status condition, in case an article was rescheduled in the meantime
+	result = initializers.DB.Model(&models.Article{}).
+		Where("id IN (?) AND status = ? AND publish_at <= ?", ids, models.Scheduled, time.Now()).
+		Update("status", models.Approved)
+	if result.Error != nil {
+		initializers.LOGGER.Error("Failed to publish scheduled articles", "error", result.Error.Error(), "ids", ids)
+		return
+	}
+	initializers.LOGGER.Info("Scheduled articles published", "ids", ids)
+
+	// Update the search index
+	for _, id := range ids {
+		utils.IndexArticle(context.Background(), id)
+	}
+}
@@ -0,0 +1,78 @@
+package tasks
+
+import (
+	"auth/initializers"
+	"auth/models"
+	"auth/utils"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// attachmentGracePeriod is how long an uploaded attachment may stay unbound, while its article is being written.
+const attachmentGracePeriod = 24 * time.Hour
+
+// gcBatchSize is the number of orphaned attachments collected at once.
+const gcBatchSize = 500
+
+// InitAttachmentCollector initializes the garbage collector of the orphaned attachments.
+func InitAttachmentCollector() {
+	go func() {
+		ticker := time.NewTicker(time.Hour)
+		defer ticker.Stop()
+		for range ticker.C {
+			collectAttachments()
+		}
+	}()
+	fmt.Println("AttachmentCollector running...")
+}
+
+// collectAttachments deletes the orphaned attachments:
+// (1) the attachments never bound to an article after the grace period
+// (2) the attachments of the purged articles (those in the trash are kept for their restoration)
+// (3) the attachments of the deleted users
+// (4) the files left without an attachment by a failed upload
+func collectAttachments() {
+	cutoff := time.Now().Add(-attachmentGracePeriod)
+
+	for {
+		var attachments []models.Attachment
+		result := initializers.DB.
+			Where("article_id IS NULL AND created_at < ?", cutoff).
+			Or("article_id IS NOT NULL AND NOT EXISTS (SELECT 1 FROM articles WHERE articles.id = attachments.article_id)").
+			Or("NOT EXISTS (SELECT 1 FROM users WHERE users.id = attachments.owner_id)").
+			Limit(gcBatchSize).Find(&attachments)
+		if result.Error != nil || len(attachments) == 0 {
+			break
+		}
+		if err := utils.RemoveAttachments(context.Background(), attachments); err != nil {
+			initializers.LOGGER.Error("Failed to collect the orphaned attachments", "error", err.Error())
+			return
+		}
+		initializers.LOGGER.Info("Orphaned attachments collected", "count", len(attachments))
+
+		if len(attachments) < gcBatchSize {
+			break
+		}
+	}
+
+	// Remove the stray files, leaving the uploads in progress alone
+	entries, err := os.ReadDir(initializers.AttachmentDir)
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil || entry.IsDir() || info.ModTime().After(cutoff) {
+			continue
+		}
+		var count int64
+		initializers.DB.Unscoped().Model(&models.Attachment{}).Where("file_name = ?", entry.Name()).Count(&count)
+		if count == 0 {
+			os.Remove(filepath.Join(initializers.AttachmentDir, entry.Name()))
+			initializers.LOGGER.Info("Stray attachment file removed", "file_name", entry.Name())
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package tasks
+
+import (
+	"auth/initializers"
+	"auth/utils"
+	"context"
+	"fmt"
+	"time"
+)
+
+// InitLikeReconciler initializes the job that reconciles the like counters and sets with the likes table.
+// An empty likes table is first filled out of the sets, which hold the likes made before the table existed.
+func InitLikeReconciler() {
+	go func() {
+		if utils.CountArticleLikes() == 0 {
+			if _, err := utils.ReconcileLikes(context.Background(), utils.LikeSourceSets, nil); err != nil {
+				initializers.LOGGER.Error("Failed to fill the likes table", "error", err.Error())
+			}
+		}
+
+		ticker := time.NewTicker(24 * time.Hour)
+		defer ticker.Stop()
+		for range ticker.C {
+			if _, err := utils.ReconcileLikes(context.Background(), utils.LikeSourceTable, nil); err != nil {
+				initializers.LOGGER.Error("Failed to reconcile the likes", "error", err.Error())
+			}
+		}
+	}()
+	fmt.Println("LikeReconciler running...")
+}
@@ -6,6 +6,7 @@ import (
 	"auth/utils"
 	"errors"
 	"fmt"
+	"log/slog"
 	"time"
 
 	"github.com/redis/go-redis/v9"
@@ -18,6 +19,7 @@ type SeckillTask struct {
 	AuthorID   uint
 	DiscountID uint
 	Credits    uint
+	RequestID  string // ID of the Seckill request that queued the task
 }
 
 // InitSeckillProcessor initializes the seckill task processor.
@@ -41,17 +43,12 @@ func InitSeckillProcessor() {
 			// (2) Parse the task
 			taskID := result[0].Messages[0].ID
 			taskData := result[0].Messages[0].Values
-			task := SeckillTask{
-				ReaderID:   utils.StrToUint(taskData["readerID"].(string)),
-				AuthorID:   utils.StrToUint(taskData["authorID"].(string)),
-				DiscountID: utils.StrToUint(taskData["discountID"].(string)),
-				Credits:    utils.StrToUint(taskData["credits"].(string)),
-			}
+			task := parseSeckillTask(taskData)
 
 			// (3) Process the task
 			if err := processSeckillTask(task); err != nil {
 				// If an error occurs, log it and process the task again in the pending-list
-				fmt.Printf("Error processing task %v: %v\n", task, err)
+				taskLogger(task).Error("Seckill task failed", "task_id", taskID, "task", task, "error", err.Error())
 				handlePendingList()
 			} else {
 				// If no error occurs, acknowledge the task to remove it from the pending-list
@@ -63,6 +60,26 @@ func InitSeckillProcessor() {
 	fmt.Println("SeckillTaskProcessor running...")
 }
 
+// parseSeckillTask parses a seckill task from the values of a stream message.
+func parseSeckillTask(taskData map[string]interface{}) SeckillTask {
+	task := SeckillTask{
+		ReaderID:   utils.StrToUint(taskData["readerID"].(string)),
+		AuthorID:   utils.StrToUint(taskData["authorID"].(string)),
+		DiscountID: utils.StrToUint(taskData["discountID"].(string)),
+		Credits:    utils.StrToUint(taskData["credits"].(string)),
+	}
+	// Messages queued before the request ID was introduced do not carry one
+	if requestID, ok := taskData["requestID"].(string); ok {
+		task.RequestID = requestID
+	}
+	return task
+}
+
+// taskLogger returns a logger that tags each record with the request ID of the task.
+func taskLogger(task SeckillTask) *slog.Logger {
+	return initializers.LOGGER.With(initializers.RequestIDKey, task.RequestID)
+}
+
 // processSeckillTask processes a seckill task.
 func processSeckillTask(task SeckillTask) error {
 	// Here you would implement the logic to process the seckill task.
@@ -96,6 +113,12 @@ func processSeckillTask(task SeckillTask) error {
 
 		return nil
 	})
+	if err == nil {
+		// Count the subscription and the credits for the statistics
+		utils.IncrStat(utils.StatSubscriptions, 1)
+		utils.IncrStat(utils.StatCreditsMoved, int64(task.Credits))
+		taskLogger(task).Info("Seckill task processed", "task", task)
+	}
 
 	return err
 }
@@ -122,17 +145,12 @@ func handlePendingList() {
 		// (2) Parse the task
 		taskID := result[0].Messages[0].ID
 		taskData := result[0].Messages[0].Values
-		task := SeckillTask{
-			ReaderID:   utils.StrToUint(taskData["readerID"].(string)),
-			AuthorID:   utils.StrToUint(taskData["authorID"].(string)),
-			DiscountID: utils.StrToUint(taskData["discountID"].(string)),
-			Credits:    utils.StrToUint(taskData["credits"].(string)),
-		}
+		task := parseSeckillTask(taskData)
 
 		// (3) Process the task
 		if err := processSeckillTask(task); err != nil {
 			// If an error occurs, log it and process the task again in the pending-list
-			fmt.Printf("Error processing task %v: %v\n", task, err)
+			taskLogger(task).Error("Seckill pending task failed", "task_id", taskID, "task", task, "error", err.Error())
 		} else {
 			// If no error occurs, acknowledge the task to remove it from the pending-list
 			// XACK stream.orders g1 <taskID>
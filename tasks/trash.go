@@ -0,0 +1,182 @@
+package tasks
+
+import (
+	"auth/initializers"
+	"auth/models"
+	"auth/utils"
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// purgeBatchSize is the number of articles purged in a single transaction.
+const purgeBatchSize = 500
+
+// InitTrashPurger initializes the purger that deletes for good the articles and comments
+// that have been in the trash for longer than the retention window.
+func InitTrashPurger() {
+	go func() {
+		ticker := time.NewTicker(time.Hour)
+		defer ticker.Stop()
+		for range ticker.C {
+			purgeTrash()
+		}
+	}()
+	fmt.Println("TrashPurger running...")
+}
+
+// purgeTrash deletes for good the expired articles and comments, with every row and Redis entry referring to them.
+func purgeTrash() {
+	cutoff := time.Now().Add(-time.Duration(initializers.TrashRetentionDays) * 24 * time.Hour)
+
+	for {
+		var ids []uint
+		result := initializers.DB.Unscoped().Model(&models.Article{}).
+			Where("deleted_at < ?", cutoff).
+			Limit(purgeBatchSize).Pluck("id", &ids)
+		if result.Error != nil || len(ids) == 0 {
+			break
+		}
+
+		var readerIDs, commentIDs []uint
+		initializers.DB.Model(&models.Bookmark{}).Where("article_id IN (?)", ids).Distinct().Pluck("user_id", &readerIDs)
+		initializers.DB.Unscoped().Model(&models.Comment{}).Where("article_id IN (?)", ids).Pluck("id", &commentIDs)
+
+		err := initializers.DB.Transaction(func(tx *gorm.DB) error {
+			if err := deleteTargetRows(tx, models.ReportComment, commentIDs); err != nil {
+				return err
+			}
+			if err := tx.Unscoped().Where("article_id IN (?)", ids).Delete(&models.Comment{}).Error; err != nil {
+				return err
+			}
+			if err := deleteTargetRows(tx, models.ReportArticle, ids); err != nil {
+				return err
+			}
+			if err := tx.Unscoped().Where("article_id IN (?)", ids).Delete(&models.Notification{}).Error; err != nil {
+				return err
+			}
+			if err := tx.Where("article_id IN (?)", ids).Delete(&models.ArticleViewStat{}).Error; err != nil {
+				return err
+			}
+			if err := tx.Unscoped().Where("article_id IN (?)", ids).Delete(&models.ArticleRevision{}).Error; err != nil {
+				return err
+			}
+			if err := tx.Exec("DELETE FROM article_tags WHERE article_id IN (?)", ids).Error; err != nil {
+				return err
+			}
+			if err := tx.Where("article_id IN (?)", ids).Delete(&models.SeriesArticle{}).Error; err != nil {
+				return err
+			}
+			if err := refundInvitations(tx, ids); err != nil {
+				return err
+			}
+			if err := tx.Where("article_id IN (?)", ids).Delete(&models.ArticleAuthor{}).Error; err != nil {
+				return err
+			}
+			if err := tx.Where("article_id IN (?)", ids).Delete(&models.ArticleLike{}).Error; err != nil {
+				return err
+			}
+			if err := tx.Where("article_id IN (?)", ids).Delete(&models.Bookmark{}).Error; err != nil {
+				return err
+			}
+			if err := tx.Where("article_id IN (?)", ids).Delete(&models.ReadingListItem{}).Error; err != nil {
+				return err
+			}
+			return tx.Unscoped().Where("id IN (?)", ids).Delete(&models.Article{}).Error
+		})
+		if err != nil {
+			initializers.LOGGER.Error("Failed to purge the trash", "error", err.Error(), "ids", ids)
+			return
+		}
+
+		// Clean the like and dislike sets, the trending sets, the cached bookmarks and the search index
+		for _, readerID := range readerIDs {
+			utils.RemoveBookmarks(context.Background(), readerID, ids...)
+		}
+		keys := make([]string, 0, 2*len(ids))
+		members := make([]interface{}, 0, len(ids))
+		for _, id := range ids {
+			keys = append(keys,
+				utils.RedisConstants.ARTICLE_LIKED_KEY_PREFIX+strconv.Itoa(int(id)),
+				utils.RedisConstants.ARTICLE_DISLIKED_KEY_PREFIX+strconv.Itoa(int(id)))
+			members = append(members, strconv.Itoa(int(id)))
+			utils.SEARCH.DeleteArticle(id)
+		}
+		initializers.RDB.Del(initializers.RDB_CTX, keys...)
+		for _, window := range utils.TrendingWindows {
+			initializers.RDB.ZRem(initializers.RDB_CTX, window.Key(), members...)
+		}
+		initializers.LOGGER.Info("Trashed articles purged", "ids", ids)
+
+		if len(ids) < purgeBatchSize {
+			break
+		}
+	}
+
+	// Purge the comments removed on their own, with their reports, verdicts and notifications
+	for {
+		var ids []uint
+		result := initializers.DB.Unscoped().Model(&models.Comment{}).
+			Where("deleted_at < ?", cutoff).
+			Limit(purgeBatchSize).Pluck("id", &ids)
+		if result.Error != nil || len(ids) == 0 {
+			break
+		}
+
+		err := initializers.DB.Transaction(func(tx *gorm.DB) error {
+			if err := deleteTargetRows(tx, models.ReportComment, ids); err != nil {
+				return err
+			}
+			if err := tx.Unscoped().Where("comment_id IN (?)", ids).Delete(&models.Notification{}).Error; err != nil {
+				return err
+			}
+			return tx.Unscoped().Where("id IN (?)", ids).Delete(&models.Comment{}).Error
+		})
+		if err != nil {
+			initializers.LOGGER.Error("Failed to purge the trash", "error", err.Error(), "ids", ids)
+			return
+		}
+		initializers.LOGGER.Info("Trashed comments purged", "ids", ids)
+
+		if len(ids) < purgeBatchSize {
+			break
+		}
+	}
+}
+
+// deleteTargetRows deletes for good the reports and the moderation verdicts of the articles or comments.
+func deleteTargetRows(tx *gorm.DB, targetType string, targetIDs []uint) error {
+	if len(targetIDs) == 0 {
+		return nil
+	}
+	if err := tx.Unscoped().Where("target_type = ? AND target_id IN (?)", targetType, targetIDs).Delete(&models.Report{}).Error; err != nil {
+		return err
+	}
+	return tx.Unscoped().Where("target_type = ? AND target_id IN (?)", targetType, targetIDs).Delete(&models.ModerationVerdict{}).Error
+}
+
+// refundInvitations refunds the authors of the articles the shares of the reward held for the pending co-author invitations.
+func refundInvitations(tx *gorm.DB, articleIDs []uint) error {
+	var refunds []struct {
+		AuthorID uint
+		Reward   uint
+	}
+	result := tx.Model(&models.ArticleAuthor{}).
+		Joins("JOIN articles ON article_authors.article_id = articles.id").
+		Select("articles.author_id AS author_id", "SUM(article_authors.reward) AS reward").
+		Where("article_authors.article_id IN (?) AND article_authors.status = ? AND article_authors.reward > 0", articleIDs, models.CoauthorInvited).
+		Group("articles.author_id").
+		Scan(&refunds)
+	if result.Error != nil {
+		return result.Error
+	}
+	for _, refund := range refunds {
+		if err := tx.Model(&models.User{}).Where("id = ?", refund.AuthorID).Update("credits", gorm.Expr("credits + ?", refund.Reward)).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
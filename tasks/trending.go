@@ -0,0 +1,21 @@
+package tasks
+
+import (
+	"auth/utils"
+	"fmt"
+	"time"
+)
+
+// InitTrendingDecayer initializes the job that decays the hot scores of the trending sets and trims them.
+func InitTrendingDecayer() {
+	go func() {
+		ticker := time.NewTicker(10 * time.Minute)
+		defer ticker.Stop()
+		last := time.Now()
+		for now := range ticker.C {
+			utils.DecayTrending(now.Sub(last))
+			last = now
+		}
+	}()
+	fmt.Println("TrendingDecayer running...")
+}
@@ -0,0 +1,19 @@
+package tasks
+
+import (
+	"auth/utils"
+	"fmt"
+	"time"
+)
+
+// InitViewFlusher initializes the job that flushes the article view counters from Redis to MySQL.
+func InitViewFlusher() {
+	go func() {
+		ticker := time.NewTicker(5 * time.Minute)
+		defer ticker.Stop()
+		for range ticker.C {
+			utils.FlushArticleViews()
+		}
+	}()
+	fmt.Println("ViewFlusher running...")
+}
@@ -0,0 +1,135 @@
+package utils
+
+import (
+	"auth/initializers"
+	"auth/models"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// AnomalyRule describes a rule of the anomaly detector.
+// An alert is raised once the counter of a subject reaches the threshold within the window.
+// If auto denial is enabled, the subject is then denied access to DenyPath with DenyMethod for a while.
+type AnomalyRule struct {
+	Name       string
+	Window     time.Duration
+	Threshold  int64
+	DenyPath   string
+	DenyMethod string
+}
+
+// AnomalyRules contains the rules of the anomaly detector.
+var AnomalyRules = struct {
+	FORBIDDEN_BURST AnomalyRule
+	LOGIN_MANY_IPS  AnomalyRule
+	MASS_DELETION   AnomalyRule
+}{
+	FORBIDDEN_BURST: AnomalyRule{Name: "forbidden_burst", Window: 1 * time.Minute, Threshold: 20, DenyPath: "/api/ui/**", DenyMethod: "ANY"},
+	LOGIN_MANY_IPS:  AnomalyRule{Name: "login_many_ips", Window: 10 * time.Minute, Threshold: 5, DenyPath: "/api/ui/**", DenyMethod: "ANY"},
+	MASS_DELETION:   AnomalyRule{Name: "mass_deletion", Window: 5 * time.Minute, Threshold: 10, DenyPath: "/api/bg/**", DenyMethod: "DELETE"},
+}
+
+// RecordForbidden records a request of the user that failed authorization.
+func RecordForbidden(ctx context.Context, email string) {
+	rule := AnomalyRules.FORBIDDEN_BURST
+	count := incrAnomalyCounter(rule, email)
+	if count >= rule.Threshold {
+		raiseAlert(ctx, rule, email, count, fmt.Sprintf("%d forbidden requests within %s", count, rule.Window))
+	}
+}
+
+// RecordLogin records a successful login of the user from the given IP.
+func RecordLogin(ctx context.Context, email string, ip string) {
+	rule := AnomalyRules.LOGIN_MANY_IPS
+	key := RedisConstants.ANOMALY_COUNTER_KEY_PREFIX + rule.Name + ":" + email
+	// Using Redis Set to count the distinct IPs
+	pipe := initializers.RDB.TxPipeline()
+	pipe.SAdd(initializers.RDB_CTX, key, ip)
+	pipe.ExpireNX(initializers.RDB_CTX, key, rule.Window)
+	scard := pipe.SCard(initializers.RDB_CTX, key)
+	if _, err := pipe.Exec(initializers.RDB_CTX); err != nil {
+		return
+	}
+	count := scard.Val()
+	if count >= rule.Threshold {
+		ips, _ := initializers.RDB.SMembers(initializers.RDB_CTX, key).Result()
+		raiseAlert(ctx, rule, email, count, fmt.Sprintf("logins from %d IPs within %s: %s", count, rule.Window, strings.Join(ips, ", ")))
+	}
+}
+
+// RecordDeletion records a successful deletion made by the admin.
+func RecordDeletion(ctx context.Context, email string, path string) {
+	rule := AnomalyRules.MASS_DELETION
+	count := incrAnomalyCounter(rule, email)
+	if count >= rule.Threshold {
+		raiseAlert(ctx, rule, email, count, fmt.Sprintf("%d deletions within %s, the last one on %s", count, rule.Window, path))
+	}
+}
+
+// incrAnomalyCounter increments the counter of the subject in the current window of the rule.
+func incrAnomalyCounter(rule AnomalyRule, subject string) int64 {
+	key := RedisConstants.ANOMALY_COUNTER_KEY_PREFIX + rule.Name + ":" + subject
+	pipe := initializers.RDB.TxPipeline()
+	incr := pipe.Incr(initializers.RDB_CTX, key)
+	pipe.ExpireNX(initializers.RDB_CTX, key, rule.Window)
+	if _, err := pipe.Exec(initializers.RDB_CTX); err != nil {
+		return 0
+	}
+	return incr.Val()
+}
+
+// raiseAlert raises an alert for the subject, at most once per window of the rule.
+func raiseAlert(ctx context.Context, rule AnomalyRule, subject string, count int64, detail string) {
+	// Using SETNX to deduplicate the alerts within the window
+	key := RedisConstants.ANOMALY_ALERT_KEY_PREFIX + rule.Name + ":" + subject
+	if !initializers.RDB.SetNX(initializers.RDB_CTX, key, "1", rule.Window).Val() {
+		return
+	}
+
+	alert := models.Alert{
+		Rule:    rule.Name,
+		Subject: subject,
+		Count:   count,
+		Detail:  detail,
+		Status:  models.AlertOpen,
+	}
+
+	// Deny the subject temporarily if auto denial is enabled
+	if initializers.AnomalyAutoDeny {
+		deniedUntil := time.Now().Add(time.Duration(initializers.AnomalyDenyMinutes) * time.Minute)
+		if ok, _ := initializers.E.AddPolicy(subject, rule.DenyPath, rule.DenyMethod, "deny"); ok {
+			// Using Redis Sorted Set to schedule the removal of the denial
+			initializers.RDB.ZAdd(initializers.RDB_CTX, RedisConstants.ANOMALY_DENIAL_KEY, redis.Z{
+				Score:  float64(deniedUntil.Unix()),
+				Member: DenialMember(subject, rule.DenyPath, rule.DenyMethod),
+			})
+			alert.DeniedUntil = &deniedUntil
+		}
+	}
+
+	if err := initializers.DB.Create(&alert).Error; err != nil {
+		initializers.LOGGER.ErrorContext(ctx, "Failed to save security alert", "error", err.Error(), "rule", rule.Name, "subject", subject)
+	}
+	initializers.LOGGER.WarnContext(ctx, "Security alert raised", "rule", rule.Name, "subject", subject, "count", count, "detail", detail, "denied_until", alert.DeniedUntil)
+}
+
+// DenialMember returns the member of a temporary denial in the denial sorted set.
+func DenialMember(subject, path, method string) string {
+	return subject + " " + path + " " + method
+}
+
+// LiftDenial removes a temporary denial given its member in the denial sorted set.
+func LiftDenial(member string) bool {
+	parts := strings.Split(member, " ")
+	if len(parts) != 3 {
+		initializers.RDB.ZRem(initializers.RDB_CTX, RedisConstants.ANOMALY_DENIAL_KEY, member)
+		return false
+	}
+	ok, _ := initializers.E.RemovePolicy(parts[0], parts[1], parts[2], "deny")
+	initializers.RDB.ZRem(initializers.RDB_CTX, RedisConstants.ANOMALY_DENIAL_KEY, member)
+	return ok
+}
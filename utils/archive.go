@@ -0,0 +1,413 @@
+package utils
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html"
+	"gopkg.in/yaml.v3"
+)
+
+// MaxImportArticles is the maximum number of articles in an imported archive.
+const MaxImportArticles = 1000
+
+// maxImportFileSize is the maximum size of a Markdown file in an imported archive, once uncompressed.
+const maxImportFileSize = 1 << 20
+
+// frontMatterDelimiter opens and closes the YAML front matter of a Markdown file.
+const frontMatterDelimiter = "---"
+
+// ArticleFrontMatter is the YAML front matter of an article exported as Markdown.
+// The slug and status are informative, the imported articles get a new slug and wait for moderation.
+type ArticleFrontMatter struct {
+	Title     string     `yaml:"title"`
+	Slug      string     `yaml:"slug,omitempty"`
+	Status    string     `yaml:"status,omitempty"`
+	Tags      []string   `yaml:"tags,omitempty"`
+	Category  string     `yaml:"category,omitempty"`
+	Free      bool       `yaml:"free"`
+	Unlisted  bool       `yaml:"unlisted"`
+	PublishAt *time.Time `yaml:"publish_at,omitempty"`
+	CreatedAt time.Time  `yaml:"created_at"`
+	UpdatedAt time.Time  `yaml:"updated_at"`
+}
+
+// CommentFrontMatter is the YAML front matter of a comment exported as Markdown.
+type CommentFrontMatter struct {
+	ID        uint      `yaml:"id"`
+	ArticleID uint      `yaml:"article_id"`
+	Article   string    `yaml:"article"`
+	ParentID  *uint     `yaml:"parent_id,omitempty"`
+	Status    string    `yaml:"status"`
+	CreatedAt time.Time `yaml:"created_at"`
+}
+
+// ImportedArticle is an article read off an imported archive.
+type ImportedArticle struct {
+	Source    string // Name of the file or link of the item it was read off, for the error reports
+	Title     string
+	Body      string // Markdown source
+	Tags      []string
+	Category  string
+	Free      bool
+	Unlisted  bool
+	PublishAt *time.Time
+	Error     string // Why the article could not be read, reported instead of importing it
+}
+
+// MarshalMarkdown returns a Markdown file made of the YAML front matter and the body.
+func MarshalMarkdown(frontMatter interface{}, body string) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString(frontMatterDelimiter + "\n")
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(frontMatter); err != nil {
+		return nil, err
+	}
+	encoder.Close()
+	buf.WriteString(frontMatterDelimiter + "\n\n")
+	buf.WriteString(body)
+	if !strings.HasSuffix(body, "\n") {
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalMarkdown splits a Markdown file into its YAML front matter, decoded into frontMatter, and its body.
+// A file without front matter is all body.
+func UnmarshalMarkdown(data []byte, frontMatter interface{}) (string, error) {
+	content := strings.ReplaceAll(string(data), "\r\n", "\n")
+	content = strings.TrimPrefix(content, "\ufeff")
+	if !strings.HasPrefix(content, frontMatterDelimiter+"\n") {
+		return content, nil
+	}
+	rest := content[len(frontMatterDelimiter)+1:]
+	end := strings.Index(rest, "\n"+frontMatterDelimiter+"\n")
+	if end < 0 {
+		if !strings.HasSuffix(rest, "\n"+frontMatterDelimiter) {
+			return "", errors.New("unterminated front matter")
+		}
+		end = len(rest) - len(frontMatterDelimiter) - 1
+	}
+	if err := yaml.Unmarshal([]byte(rest[:end]), frontMatter); err != nil {
+		return "", errors.New("invalid front matter: " + err.Error())
+	}
+	body := ""
+	if start := end + len(frontMatterDelimiter) + 2; start < len(rest) {
+		body = rest[start:]
+	}
+	return strings.TrimLeft(body, "\n"), nil
+}
+
+// ReadMarkdownArchive reads the articles off a zip of Markdown files, in the format of the exports.
+// The comments of the exports are skipped, since they belong to the articles of the former site.
+func ReadMarkdownArchive(r io.ReaderAt, size int64) ([]ImportedArticle, error) {
+	archive, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, errors.New("invalid zip archive")
+	}
+
+	var articles []ImportedArticle
+	for _, file := range archive.File {
+		name := strings.TrimPrefix(path.Clean(file.Name), "/")
+		if file.FileInfo().IsDir() || !strings.EqualFold(path.Ext(name), ".md") || strings.HasPrefix(name, "comments/") || strings.HasPrefix(path.Base(name), ".") {
+			continue
+		}
+		if len(articles) == MaxImportArticles {
+			return nil, errors.New("too many articles in the archive")
+		}
+		if file.UncompressedSize64 > maxImportFileSize {
+			return nil, errors.New(name + ": file too large")
+		}
+
+		f, err := file.Open()
+		if err != nil {
+			return nil, errors.New(name + ": failed to read the file")
+		}
+		data, err := io.ReadAll(io.LimitReader(f, maxImportFileSize+1))
+		f.Close()
+		if err != nil || len(data) > maxImportFileSize {
+			return nil, errors.New(name + ": failed to read the file")
+		}
+
+		var frontMatter ArticleFrontMatter
+		body, err := UnmarshalMarkdown(data, &frontMatter)
+		article := ImportedArticle{
+			Source:    name,
+			Title:     strings.TrimSpace(frontMatter.Title),
+			Body:      body,
+			Tags:      frontMatter.Tags,
+			Category:  frontMatter.Category,
+			Free:      frontMatter.Free,
+			Unlisted:  frontMatter.Unlisted,
+			PublishAt: frontMatter.PublishAt,
+		}
+		if err != nil {
+			// Report the file rather than failing the whole archive
+			article.Error = err.Error()
+		}
+		if article.Title == "" {
+			article.Title = markdownTitle(body, strings.TrimSuffix(path.Base(name), path.Ext(name)))
+		}
+		articles = append(articles, article)
+	}
+	return articles, nil
+}
+
+// markdownTitle returns the first level 1 heading of a Markdown body, or the fallback.
+func markdownTitle(body string, fallback string) string {
+	for _, line := range strings.Split(body, "\n") {
+		if strings.HasPrefix(line, "# ") {
+			return strings.TrimSpace(line[2:])
+		}
+	}
+	return fallback
+}
+
+type wxrFeed struct {
+	Channel struct {
+		Items []wxrItem `xml:"item"`
+	} `xml:"channel"`
+}
+
+type wxrItem struct {
+	Title      string        `xml:"title"`
+	Link       string        `xml:"link"`
+	Content    string        `xml:"http://purl.org/rss/1.0/modules/content/ encoded"`
+	PostType   string        `xml:"post_type"`
+	Status     string        `xml:"status"`
+	PostDate   string        `xml:"post_date_gmt"`
+	Password   string        `xml:"post_password"`
+	Categories []wxrCategory `xml:"category"`
+}
+
+type wxrCategory struct {
+	Domain string `xml:"domain,attr"`
+	Name   string `xml:",chardata"`
+}
+
+// ReadWXR reads the posts off a WordPress eXtended RSS export, converting their HTML to Markdown.
+// The pages, attachments and trashed posts are skipped, and the password protected posts are imported unlisted.
+func ReadWXR(r io.Reader) ([]ImportedArticle, error) {
+	decoder := xml.NewDecoder(r)
+	decoder.Strict = false
+	decoder.Entity = xml.HTMLEntity
+	var feed wxrFeed
+	if err := decoder.Decode(&feed); err != nil {
+		return nil, errors.New("invalid WXR file")
+	}
+
+	var articles []ImportedArticle
+	for _, item := range feed.Channel.Items {
+		if item.PostType != "post" || item.Status == "trash" || item.Status == "auto-draft" {
+			continue
+		}
+		if len(articles) == MaxImportArticles {
+			return nil, errors.New("too many posts in the file")
+		}
+		article := ImportedArticle{
+			Source:   item.Link,
+			Title:    strings.TrimSpace(item.Title),
+			Body:     HTMLToMarkdown(item.Content),
+			Unlisted: item.Status == "private" || item.Password != "",
+			Free:     true,
+		}
+		if article.Source == "" {
+			article.Source = article.Title
+		}
+		for _, category := range item.Categories {
+			name := strings.TrimSpace(category.Name)
+			switch {
+			case name == "":
+			case category.Domain == "post_tag":
+				article.Tags = append(article.Tags, name)
+			case category.Domain == "category" && article.Category == "" && name != "Uncategorized":
+				article.Category = name
+			}
+		}
+		if item.Status == "future" {
+			if publishAt, err := time.Parse(time.DateTime, item.PostDate); err == nil {
+				article.PublishAt = &publishAt
+			}
+		}
+		articles = append(articles, article)
+	}
+	return articles, nil
+}
+
+// blankLines matches the runs of blank lines, collapsed into a paragraph break.
+var blankLines = regexp.MustCompile(`\n[ \t]*\n(?:[ \t]*\n)*`)
+
+// spaces matches the runs of whitespace, collapsed into a space.
+var spaces = regexp.MustCompile(`[ \t\r\n]+`)
+
+// HTMLToMarkdown converts the HTML of a post to Markdown. The blank lines of the text
+// are paragraph breaks, as WordPress stores the posts without their paragraph tags.
+func HTMLToMarkdown(source string) string {
+	doc, err := html.Parse(strings.NewReader(source))
+	if err != nil {
+		return source
+	}
+	var buf strings.Builder
+	convertHTMLChildren(&buf, doc, false)
+	return strings.TrimSpace(blankLines.ReplaceAllString(buf.String(), "\n\n")) + "\n"
+}
+
+// convertHTMLChildren writes the Markdown of the children of an HTML node.
+func convertHTMLChildren(buf *strings.Builder, n *html.Node, pre bool) {
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		convertHTML(buf, child, pre)
+	}
+}
+
+// convertHTML writes the Markdown of an HTML node.
+func convertHTML(buf *strings.Builder, n *html.Node, pre bool) {
+	switch n.Type {
+	case html.TextNode:
+		if pre {
+			buf.WriteString(n.Data)
+			return
+		}
+		paragraphs := blankLines.Split(strings.ReplaceAll(n.Data, "\r\n", "\n"), -1)
+		for i, paragraph := range paragraphs {
+			if i > 0 {
+				buf.WriteString("\n\n")
+			}
+			buf.WriteString(spaces.ReplaceAllString(paragraph, " "))
+		}
+		return
+	case html.ElementNode:
+	default:
+		convertHTMLChildren(buf, n, pre)
+		return
+	}
+
+	switch n.Data {
+	case "script", "style", "iframe":
+	case "p", "div", "section", "article", "figure", "table":
+		buf.WriteString("\n\n")
+		convertHTMLChildren(buf, n, pre)
+		buf.WriteString("\n\n")
+	case "h1", "h2", "h3", "h4", "h5", "h6":
+		buf.WriteString("\n\n" + strings.Repeat("#", int(n.Data[1]-'0')) + " ")
+		buf.WriteString(strings.TrimSpace(inlineMarkdown(n)))
+		buf.WriteString("\n\n")
+	case "br":
+		buf.WriteString("  \n")
+	case "hr":
+		buf.WriteString("\n\n---\n\n")
+	case "strong", "b":
+		writeEmphasis(buf, "**", inlineMarkdown(n))
+	case "em", "i":
+		writeEmphasis(buf, "*", inlineMarkdown(n))
+	case "del", "s", "strike":
+		writeEmphasis(buf, "~~", inlineMarkdown(n))
+	case "code":
+		if pre {
+			convertHTMLChildren(buf, n, pre)
+		} else {
+			buf.WriteString("`" + textContent(n) + "`")
+		}
+	case "pre":
+		var code strings.Builder
+		convertHTMLChildren(&code, n, true)
+		buf.WriteString("\n\n```\n" + strings.Trim(code.String(), "\n") + "\n```\n\n")
+	case "a":
+		text := strings.TrimSpace(inlineMarkdown(n))
+		if href := htmlAttr(n, "href"); href != "" {
+			buf.WriteString("[" + text + "](" + href + ")")
+		} else {
+			buf.WriteString(text)
+		}
+	case "img":
+		if src := htmlAttr(n, "src"); src != "" {
+			buf.WriteString("![" + htmlAttr(n, "alt") + "](" + src + ")")
+		}
+	case "ul", "ol":
+		buf.WriteString("\n\n")
+		index := 1
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			if child.Type != html.ElementNode || child.Data != "li" {
+				continue
+			}
+			marker := "- "
+			if n.Data == "ol" {
+				marker = strconv.Itoa(index) + ". "
+				index++
+			}
+			var item strings.Builder
+			convertHTMLChildren(&item, child, pre)
+			content := strings.TrimSpace(blankLines.ReplaceAllString(item.String(), "\n"))
+			buf.WriteString(marker + strings.ReplaceAll(content, "\n", "\n"+strings.Repeat(" ", len(marker))) + "\n")
+		}
+		buf.WriteString("\n")
+	case "blockquote":
+		var quote strings.Builder
+		convertHTMLChildren(&quote, n, pre)
+		content := strings.TrimSpace(blankLines.ReplaceAllString(quote.String(), "\n\n"))
+		quoted := strings.ReplaceAll(strings.ReplaceAll(content, "\n", "\n> "), "\n> \n", "\n>\n")
+		buf.WriteString("\n\n> " + quoted + "\n\n")
+	case "tr":
+		buf.WriteString("\n")
+		convertHTMLChildren(buf, n, pre)
+	case "td", "th":
+		buf.WriteString(" ")
+		convertHTMLChildren(buf, n, pre)
+		buf.WriteString(" ")
+	default:
+		convertHTMLChildren(buf, n, pre)
+	}
+}
+
+// inlineMarkdown returns the Markdown of the children of an inline HTML node, on a single line.
+func inlineMarkdown(n *html.Node) string {
+	var buf strings.Builder
+	convertHTMLChildren(&buf, n, false)
+	return spaces.ReplaceAllString(buf.String(), " ")
+}
+
+// writeEmphasis writes an emphasized text, keeping its surrounding spaces outside of the delimiters.
+func writeEmphasis(buf *strings.Builder, delimiter string, text string) {
+	trimmed := strings.TrimSpace(text)
+	if trimmed == "" {
+		buf.WriteString(text)
+		return
+	}
+	if strings.HasPrefix(text, " ") {
+		buf.WriteString(" ")
+	}
+	buf.WriteString(delimiter + trimmed + delimiter)
+	if strings.HasSuffix(text, " ") {
+		buf.WriteString(" ")
+	}
+}
+
+// textContent returns the text of an HTML node and its descendants.
+func textContent(n *html.Node) string {
+	if n.Type == html.TextNode {
+		return n.Data
+	}
+	var buf strings.Builder
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		buf.WriteString(textContent(child))
+	}
+	return buf.String()
+}
+
+// htmlAttr returns the value of an attribute of an HTML node.
+func htmlAttr(n *html.Node, key string) string {
+	for _, attr := range n.Attr {
+		if attr.Key == key {
+			return attr.Val
+		}
+	}
+	return ""
+}
@@ -0,0 +1,141 @@
+package utils
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestUnmarshalMarkdown(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		title   string
+		tags    []string
+		body    string
+		wantErr bool
+	}{
+		{"front matter", "---\ntitle: Hello\ntags: [a, b]\n---\n\n# Body\n", "Hello", []string{"a", "b"}, "# Body\n", false},
+		{"CRLF", "---\r\ntitle: Hello\r\n---\r\n\r\nBody\r\n", "Hello", nil, "Body\n", false},
+		{"BOM", "\ufeff---\ntitle: Hello\n---\nBody\n", "Hello", nil, "Body\n", false},
+		{"closed at the end of the file", "---\ntitle: Hello\n---", "Hello", nil, "", false},
+		{"no front matter", "Just a body\n---\n", "", nil, "Just a body\n---\n", false},
+		{"unterminated front matter", "---\ntitle: Hello\nBody\n", "", nil, "", true},
+		{"invalid front matter", "---\ntitle: [Hello\n---\nBody\n", "", nil, "", true},
+	}
+	for _, tt := range tests {
+		var frontMatter ArticleFrontMatter
+		body, err := UnmarshalMarkdown([]byte(tt.data), &frontMatter)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: error = %v, want error %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if frontMatter.Title != tt.title || strings.Join(frontMatter.Tags, ",") != strings.Join(tt.tags, ",") || body != tt.body {
+			t.Errorf("%s: got title %q, tags %v and body %q, want %q, %v and %q", tt.name, frontMatter.Title, frontMatter.Tags, body, tt.title, tt.tags, tt.body)
+		}
+	}
+}
+
+func TestMarshalMarkdown(t *testing.T) {
+	// An exported article is imported back as it was
+	exported := ArticleFrontMatter{Title: "Hello", Tags: []string{"go"}, Free: true, CreatedAt: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)}
+	data, err := MarshalMarkdown(exported, "# Hello\n\nBody")
+	if err != nil {
+		t.Fatalf("MarshalMarkdown: %v", err)
+	}
+	var imported ArticleFrontMatter
+	body, err := UnmarshalMarkdown(data, &imported)
+	if err != nil {
+		t.Fatalf("UnmarshalMarkdown: %v", err)
+	}
+	if !reflect.DeepEqual(imported, exported) || body != "# Hello\n\nBody\n" {
+		t.Fatalf("round trip = %+v and %q, want %+v and %q", imported, body, exported, "# Hello\n\nBody\n")
+	}
+}
+
+func TestHTMLToMarkdown(t *testing.T) {
+	tests := []struct {
+		name string
+		html string
+		want string
+	}{
+		{"paragraphs without tags", "First paragraph\n\nSecond <em>emphasis </em>here<br>next line", "First paragraph\n\nSecond *emphasis* here  \nnext line\n"},
+		{"headings, links and images", `<h2>Title <a href="/x">link</a></h2><p><img src="a.png" alt="A"></p>`, "## Title [link](/x)\n\n![A](a.png)\n"},
+		{"nested unordered lists", "<ul><li>One<ul><li>Nested <b>bold</b></li><li>Two</li></ul></li><li>Three</li></ul>", "- One\n  - Nested **bold**\n  - Two\n- Three\n"},
+		{"nested ordered lists", "<ol><li>First<ol><li>Sub</li></ol></li><li>Second</li></ol>", "1. First\n   1. Sub\n2. Second\n"},
+		{"blockquote of paragraphs", "<blockquote><p>Quote one</p><p>Quote two</p></blockquote>", "> Quote one\n>\n> Quote two\n"},
+		{"nested blockquotes", "<blockquote>Outer<blockquote>Inner</blockquote></blockquote>", "> Outer\n>\n> > Inner\n"},
+		{"list in a blockquote", "<blockquote><ul><li>a</li><li>b</li></ul></blockquote>", "> - a\n> - b\n"},
+		{"code block", "<pre><code>a := 1\n\nb := 2</code></pre>", "```\na := 1\n\nb := 2\n```\n"},
+		{"scripts", "<p>Text</p><script>alert(1)</script>", "Text\n"},
+	}
+	for _, tt := range tests {
+		if got := HTMLToMarkdown(tt.html); got != tt.want {
+			t.Errorf("%s: HTMLToMarkdown(%q) = %q, want %q", tt.name, tt.html, got, tt.want)
+		}
+	}
+}
+
+func TestReadWXR(t *testing.T) {
+	wxr := `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0" xmlns:content="http://purl.org/rss/1.0/modules/content/" xmlns:wp="http://wordpress.org/export/1.2/">
+<channel>
+	<item>
+		<title>Published</title>
+		<link>https://example.com/published</link>
+		<content:encoded><![CDATA[<p>Hello &amp; <b>bye</b></p>]]></content:encoded>
+		<wp:post_type>post</wp:post_type>
+		<wp:status>publish</wp:status>
+		<wp:post_date_gmt>2024-01-02 03:04:05</wp:post_date_gmt>
+		<category domain="category" nicename="uncategorized"><![CDATA[Uncategorized]]></category>
+		<category domain="category" nicename="go"><![CDATA[Go]]></category>
+		<category domain="post_tag" nicename="tips"><![CDATA[tips]]></category>
+	</item>
+	<item>
+		<title>Future</title>
+		<link>https://example.com/future</link>
+		<content:encoded>Soon</content:encoded>
+		<wp:post_type>post</wp:post_type>
+		<wp:status>future</wp:status>
+		<wp:post_date_gmt>2030-05-06 07:08:09</wp:post_date_gmt>
+	</item>
+	<item>
+		<title>Private</title>
+		<link>https://example.com/private</link>
+		<content:encoded>Secret</content:encoded>
+		<wp:post_type>post</wp:post_type>
+		<wp:status>private</wp:status>
+	</item>
+	<item>
+		<title>Protected</title>
+		<content:encoded>Password</content:encoded>
+		<wp:post_type>post</wp:post_type>
+		<wp:status>publish</wp:status>
+		<wp:post_password>secret</wp:post_password>
+	</item>
+	<item><title>Page</title><wp:post_type>page</wp:post_type><wp:status>publish</wp:status></item>
+	<item><title>Trashed</title><wp:post_type>post</wp:post_type><wp:status>trash</wp:status></item>
+	<item><title>Auto draft</title><wp:post_type>post</wp:post_type><wp:status>auto-draft</wp:status></item>
+</channel>
+</rss>`
+
+	articles, err := ReadWXR(strings.NewReader(wxr))
+	if err != nil {
+		t.Fatalf("ReadWXR: %v", err)
+	}
+	publishAt := time.Date(2030, 5, 6, 7, 8, 9, 0, time.UTC)
+	want := []ImportedArticle{
+		{Source: "https://example.com/published", Title: "Published", Body: "Hello & **bye**\n", Tags: []string{"tips"}, Category: "Go", Free: true},
+		{Source: "https://example.com/future", Title: "Future", Body: "Soon\n", Free: true, PublishAt: &publishAt},
+		{Source: "https://example.com/private", Title: "Private", Body: "Secret\n", Free: true, Unlisted: true},
+		{Source: "Protected", Title: "Protected", Body: "Password\n", Free: true, Unlisted: true},
+	}
+	if !reflect.DeepEqual(articles, want) {
+		t.Fatalf("ReadWXR = %+v, want %+v", articles, want)
+	}
+
+	if _, err := ReadWXR(strings.NewReader("not a WXR file")); err == nil {
+		t.Fatalf("ReadWXR(not a WXR file) succeeded, want an error")
+	}
+}
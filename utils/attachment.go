@@ -0,0 +1,84 @@
+package utils
+
+import (
+	"auth/initializers"
+	"auth/models"
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// sniffLength is the number of leading bytes http.DetectContentType looks at.
+const sniffLength = 512
+
+// AttachmentTypes are the allowed content types of the attachments, with the extensions of their stored files.
+// SVG and HTML are not allowed since they may run scripts when served from the site.
+var AttachmentTypes = map[string]string{
+	"image/png":       ".png",
+	"image/jpeg":      ".jpg",
+	"image/gif":       ".gif",
+	"image/webp":      ".webp",
+	"application/pdf": ".pdf",
+	"application/zip": ".zip",
+	"text/plain":      ".txt",
+}
+
+// attachmentLink matches the links to the attachments in a Markdown body.
+var attachmentLink = regexp.MustCompile(`/api/ui/attachments/(\d+)`)
+
+// SniffAttachment returns the content type and the extension of an attachment off its leading bytes,
+// regardless of the extension of its name. It returns false if the type is not allowed.
+func SniffAttachment(head []byte) (string, string, bool) {
+	if len(head) > sniffLength {
+		head = head[:sniffLength]
+	}
+	mime, _, _ := strings.Cut(http.DetectContentType(head), ";")
+	ext, ok := AttachmentTypes[mime]
+	return mime, ext, ok
+}
+
+// IsInlineAttachment reports whether an attachment of the content type is displayed in the article, rather than downloaded.
+func IsInlineAttachment(mime string) bool {
+	return strings.HasPrefix(mime, "image/")
+}
+
+// ParseAttachmentLinks returns the IDs of the attachments linked by a Markdown body.
+func ParseAttachmentLinks(body string) []uint {
+	var ids []uint
+	seen := map[uint]bool{}
+	for _, match := range attachmentLink.FindAllStringSubmatch(body, -1) {
+		id, err := strconv.ParseUint(match[1], 10, 64)
+		if err != nil || seen[uint(id)] {
+			continue
+		}
+		seen[uint(id)] = true
+		ids = append(ids, uint(id))
+	}
+	return ids
+}
+
+// RemoveAttachments deletes attachments from the database for good, along with their files.
+func RemoveAttachments(ctx context.Context, attachments []models.Attachment) error {
+	if len(attachments) == 0 {
+		return nil
+	}
+	ids := make([]uint, 0, len(attachments))
+	for _, attachment := range attachments {
+		ids = append(ids, attachment.ID)
+	}
+	if err := initializers.DB.Unscoped().Where("id IN (?)", ids).Delete(&models.Attachment{}).Error; err != nil {
+		return errors.New("failed to delete the attachments from the database")
+	}
+	for _, attachment := range attachments {
+		err := os.Remove(filepath.Join(initializers.AttachmentDir, attachment.FileName))
+		if err != nil && !os.IsNotExist(err) {
+			initializers.LOGGER.ErrorContext(ctx, "Failed to remove the attachment file", "error", err.Error(), "file_name", attachment.FileName)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package utils
+
+import (
+	"auth/initializers"
+	"auth/models"
+	"context"
+	"strconv"
+)
+
+// bookmarkSentinel is a member of every bookmark set, so that a reader without bookmarks has a cached set too.
+const bookmarkSentinel = "0"
+
+// bookmarkKey returns the key of the set of the articles bookmarked by a reader.
+func bookmarkKey(userID uint) string {
+	return RedisConstants.BOOKMARK_KEY_PREFIX + strconv.Itoa(int(userID))
+}
+
+// IsBookmarked reports whether a reader has bookmarked an article.
+// The bookmarks of the reader are cached in a Redis set, loaded off MySQL on a cache miss.
+func IsBookmarked(ctx context.Context, userID uint, articleID uint) bool {
+	key := bookmarkKey(userID)
+	if initializers.RDB.Exists(initializers.RDB_CTX, key).Val() == 0 {
+		loadBookmarks(ctx, userID)
+	}
+	return initializers.RDB.SIsMember(initializers.RDB_CTX, key, strconv.Itoa(int(articleID))).Val()
+}
+
+// AddBookmarks adds articles to the cached bookmarks of a reader, after they have been bookmarked in MySQL.
+func AddBookmarks(ctx context.Context, userID uint, articleIDs ...uint) {
+	updateBookmarks(ctx, "sadd", userID, articleIDs)
+}
+
+// RemoveBookmarks removes articles from the cached bookmarks of a reader, after their bookmarks have been deleted in MySQL.
+func RemoveBookmarks(ctx context.Context, userID uint, articleIDs ...uint) {
+	updateBookmarks(ctx, "srem", userID, articleIDs)
+}
+
+// updateBookmarks runs a set command on the cached bookmarks of a reader, if they are cached.
+// On failure the cache is dropped, so that it is loaded off MySQL again on the next read.
+func updateBookmarks(ctx context.Context, command string, userID uint, articleIDs []uint) {
+	if len(articleIDs) == 0 {
+		return
+	}
+	args := make([]interface{}, 0, len(articleIDs)+1)
+	args = append(args, command)
+	for _, articleID := range articleIDs {
+		args = append(args, strconv.Itoa(int(articleID)))
+	}
+	key := bookmarkKey(userID)
+	if err := BookmarkScript.Run(initializers.RDB_CTX, initializers.RDB, []string{key}, args...).Err(); err != nil {
+		initializers.LOGGER.ErrorContext(ctx, "Failed to update the cached bookmarks", "error", err.Error(), "user_id", userID)
+		initializers.RDB.Del(initializers.RDB_CTX, key)
+	}
+}
+
+// loadBookmarks caches the bookmarks of a reader in a Redis set.
+func loadBookmarks(ctx context.Context, userID uint) {
+	var articleIDs []uint
+	result := initializers.DB.Model(&models.Bookmark{}).Where("user_id = ?", userID).Pluck("article_id", &articleIDs)
+	if result.Error != nil {
+		return
+	}
+
+	members := make([]interface{}, 0, len(articleIDs)+1)
+	members = append(members, bookmarkSentinel)
+	for _, articleID := range articleIDs {
+		members = append(members, strconv.Itoa(int(articleID)))
+	}
+	key := bookmarkKey(userID)
+	pipe := initializers.RDB.TxPipeline()
+	pipe.Del(initializers.RDB_CTX, key)
+	pipe.SAdd(initializers.RDB_CTX, key, members...)
+	pipe.Expire(initializers.RDB_CTX, key, RedisConstants.BOOKMARK_EXPIRE_TIME)
+	if _, err := pipe.Exec(initializers.RDB_CTX); err != nil {
+		initializers.LOGGER.ErrorContext(ctx, "Failed to cache the bookmarks", "error", err.Error(), "user_id", userID)
+	}
+}
@@ -0,0 +1,95 @@
+package utils
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// DiffLine is a line of a line-based diff.
+type DiffLine struct {
+	Op   string `json:"op"`
+	Text string `json:"text"`
+}
+
+// DiffLine.Op is the operation on the line.
+// It can be one of the following values:
+const (
+	DiffEqual  = "="
+	DiffInsert = "+"
+	DiffDelete = "-"
+)
+
+// MaxDiffCells bounds the size of the table of DiffLines: the product of the numbers of lines that differ.
+const MaxDiffCells = 1 << 20
+
+// DiffLines returns the line-based diff that turns a into b.
+// It is computed from the longest common subsequence of the lines, after the common head and tail are set aside.
+// It fails if the lines that differ are too many to compare (see MaxDiffCells).
+func DiffLines(a, b string) ([]DiffLine, error) {
+	linesA := strings.Split(a, "\n")
+	linesB := strings.Split(b, "\n")
+
+	// Set aside the common head and tail
+	head := 0
+	for head < len(linesA) && head < len(linesB) && linesA[head] == linesB[head] {
+		head++
+	}
+	tail := 0
+	for tail < len(linesA)-head && tail < len(linesB)-head && linesA[len(linesA)-1-tail] == linesB[len(linesB)-1-tail] {
+		tail++
+	}
+	var diff []DiffLine
+	for _, line := range linesA[:head] {
+		diff = append(diff, DiffLine{Op: DiffEqual, Text: line})
+	}
+	common := linesA[len(linesA)-tail:]
+	linesA = linesA[head : len(linesA)-tail]
+	linesB = linesB[head : len(linesB)-tail]
+	n, m := len(linesA), len(linesB)
+	if n > 0 && m > 0 && n > MaxDiffCells/m {
+		return nil, errors.New("too many changed lines to compare: " + strconv.Itoa(n) + " and " + strconv.Itoa(m))
+	}
+
+	// lcs[i][j] is the length of the longest common subsequence of linesA[i:] and linesB[j:]
+	lcs := make([][]int, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if linesA[i] == linesB[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	// Walk along the table to build the diff
+	i, j := 0, 0
+	for i < n && j < m {
+		switch {
+		case linesA[i] == linesB[j]:
+			diff = append(diff, DiffLine{Op: DiffEqual, Text: linesA[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			diff = append(diff, DiffLine{Op: DiffDelete, Text: linesA[i]})
+			i++
+		default:
+			diff = append(diff, DiffLine{Op: DiffInsert, Text: linesB[j]})
+			j++
+		}
+	}
+	for ; i < n; i++ {
+		diff = append(diff, DiffLine{Op: DiffDelete, Text: linesA[i]})
+	}
+	for ; j < m; j++ {
+		diff = append(diff, DiffLine{Op: DiffInsert, Text: linesB[j]})
+	}
+	for _, line := range common {
+		diff = append(diff, DiffLine{Op: DiffEqual, Text: line})
+	}
+	return diff, nil
+}
@@ -4,6 +4,7 @@ import (
 	"auth/initializers"
 	"math/rand"
 	"regexp"
+	"strings"
 
 	"gopkg.in/gomail.v2"
 )
@@ -18,6 +19,27 @@ func IsValidEmail(email string) bool {
 	return re.MatchString(email)
 }
 
+// mentionPattern matches the "@email" mentions at the start of the text or after a whitespace.
+var mentionPattern = regexp.MustCompile(`(?:^|\s)@([\w.+-]+@(?:[\w-]+\.)+[\w-]{2,})`)
+
+// ParseMentions returns the distinct valid email addresses mentioned in the text, up to limit.
+func ParseMentions(text string, limit int) []string {
+	var emails []string
+	seen := make(map[string]bool)
+	for _, match := range mentionPattern.FindAllStringSubmatch(text, -1) {
+		email := strings.TrimRight(match[1], ".")
+		if seen[email] || !IsValidEmail(email) {
+			continue
+		}
+		seen[email] = true
+		emails = append(emails, email)
+		if len(emails) == limit {
+			break
+		}
+	}
+	return emails
+}
+
 // GenerateVerificationCode generates a random verification code of the specified size.
 func GenerateVerificationCode(size int) string {
 	const charset = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ"
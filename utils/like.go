@@ -0,0 +1,241 @@
+package utils
+
+import (
+	"auth/initializers"
+	"auth/models"
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm/clause"
+)
+
+// Results of LikeScript
+const (
+	LikeAdded     = 0
+	LikeCancelled = 1
+	LikeConflict  = 2
+)
+
+// Sources of truth of ReconcileLikes
+const (
+	// LikeSourceSets rebuilds the counters and the likes table from the Redis like sets
+	LikeSourceSets = "sets"
+	// LikeSourceTable rebuilds the counters and the Redis like sets from the likes table
+	LikeSourceTable = "table"
+)
+
+// reconcileBatchSize is the number of articles reconciled at once.
+const reconcileBatchSize = 200
+
+// LikeReconcileReport counts what a reconciliation has found out of sync and rebuilt.
+type LikeReconcileReport struct {
+	Source   string `json:"source"`
+	Total    int64  `json:"total"`
+	Articles int    `json:"articles"`
+	Counters int    `json:"counters"`
+	Sets     int    `json:"sets"`
+	Rows     int    `json:"rows"`
+}
+
+// reaction is the like or dislike of an article by a user, with its time (Unix seconds).
+type reaction struct {
+	code uint
+	at   int64
+}
+
+// ReconcileLikes brings the like and dislike counters of the articles (trashed ones included), their Redis sets
+// and the likes table back in agreement, taking either the sets or the table as the source of truth.
+// The likes racing with a reconciliation may leave it slightly off, which the next one repairs.
+// The progress, if any, is called with the report after each batch of articles.
+func ReconcileLikes(ctx context.Context, source string, progress func(report *LikeReconcileReport)) (*LikeReconcileReport, error) {
+	if !IsLikeSource(source) {
+		return nil, errors.New("invalid source: must be either sets or table")
+	}
+	if !SimpleTryLock(RedisConstants.MUTEX_LIKE_RECONCILE_KEY, RedisConstants.MUTEX_LIKE_RECONCILE_EXPIRE_TIME) {
+		return nil, errors.New("a reconciliation of the likes is already running")
+	}
+	defer SimpleUnlock(RedisConstants.MUTEX_LIKE_RECONCILE_KEY)
+
+	report := &LikeReconcileReport{Source: source}
+	initializers.DB.Unscoped().Model(&models.Article{}).Count(&report.Total)
+	var lastID uint
+	for {
+		var articles []models.Article
+		result := initializers.DB.Unscoped().Select("id", "likes", "dislikes").
+			Where("id > ?", lastID).Order("id").Limit(reconcileBatchSize).Find(&articles)
+		if result.Error != nil {
+			return report, errors.New("failed to get the articles from the database")
+		}
+		for _, article := range articles {
+			if err := reconcileArticleLikes(article, source, report); err != nil {
+				initializers.LOGGER.ErrorContext(ctx, "Failed to reconcile the likes", "error", err.Error(), "article_id", article.ID)
+			}
+			report.Articles++
+		}
+		if progress != nil {
+			progress(report)
+		}
+		if len(articles) < reconcileBatchSize {
+			break
+		}
+		lastID = articles[len(articles)-1].ID
+	}
+	initializers.LOGGER.InfoContext(ctx, "Likes reconciled", "source", report.Source, "articles", report.Articles,
+		"counters", report.Counters, "sets", report.Sets, "rows", report.Rows)
+	return report, nil
+}
+
+// IsLikeSource reports whether a source of truth of ReconcileLikes is valid.
+func IsLikeSource(source string) bool {
+	return source == LikeSourceSets || source == LikeSourceTable
+}
+
+// IsReconcilingLikes reports whether a reconciliation of the likes is running.
+func IsReconcilingLikes() bool {
+	return initializers.RDB.Exists(initializers.RDB_CTX, RedisConstants.MUTEX_LIKE_RECONCILE_KEY).Val() > 0
+}
+
+// CountArticleLikes returns the number of rows in the likes table.
+func CountArticleLikes() int64 {
+	var count int64
+	initializers.DB.Model(&models.ArticleLike{}).Count(&count)
+	return count
+}
+
+// reconcileArticleLikes reconciles the likes of an article.
+func reconcileArticleLikes(article models.Article, source string, report *LikeReconcileReport) error {
+	keys := map[uint]string{
+		models.Like:    RedisConstants.ARTICLE_LIKED_KEY_PREFIX + strconv.Itoa(int(article.ID)),
+		models.Dislike: RedisConstants.ARTICLE_DISLIKED_KEY_PREFIX + strconv.Itoa(int(article.ID)),
+	}
+
+	// Read the reactions off the sets
+	inSets := make(map[uint]reaction)
+	for _, code := range []uint{models.Dislike, models.Like} {
+		members, err := initializers.RDB.ZRangeWithScores(initializers.RDB_CTX, keys[code], 0, -1).Result()
+		if err != nil {
+			return errors.New("internal redis error: " + err.Error())
+		}
+		for _, member := range members {
+			if userID := StrToUint(member.Member.(string)); userID != 0 {
+				inSets[userID] = reaction{code: code, at: int64(member.Score)}
+			}
+		}
+	}
+
+	// Read the reactions off the table
+	var rows []models.ArticleLike
+	result := initializers.DB.Where("article_id = ?", article.ID).Find(&rows)
+	if result.Error != nil {
+		return errors.New("failed to get the likes from the database")
+	}
+	inTable := make(map[uint]reaction, len(rows))
+	for _, row := range rows {
+		inTable[row.UserID] = reaction{code: row.Code, at: row.CreatedAt.Unix()}
+	}
+
+	// Rebuild the copy out of the source of truth
+	truth := inSets
+	if source == LikeSourceSets {
+		report.Rows += syncLikeRows(article.ID, inSets, inTable)
+	} else {
+		truth = inTable
+		for _, code := range []uint{models.Like, models.Dislike} {
+			if !sameReactions(code, inTable, inSets) {
+				if err := rebuildLikeSet(keys[code], code, inTable); err != nil {
+					return err
+				}
+				report.Sets++
+			}
+		}
+	}
+
+	// Rebuild the counters out of the source of truth
+	var likes, dislikes uint
+	for _, r := range truth {
+		if r.code == models.Like {
+			likes++
+		} else if r.code == models.Dislike {
+			dislikes++
+		}
+	}
+	if article.Likes != likes || article.Dislikes != dislikes {
+		result = initializers.DB.Unscoped().Model(&models.Article{}).Where("id = ?", article.ID).
+			Updates(map[string]interface{}{"likes": likes, "dislikes": dislikes})
+		if result.Error != nil {
+			return errors.New("failed to update the counters of the article")
+		}
+		report.Counters++
+	}
+	return nil
+}
+
+// syncLikeRows makes the likes table of an article agree with its sets, and returns the number of rows changed.
+func syncLikeRows(articleID uint, inSets, inTable map[uint]reaction) int {
+	changed := 0
+	for userID, r := range inSets {
+		if old, ok := inTable[userID]; ok && old.code == r.code {
+			continue
+		}
+		row := models.ArticleLike{ArticleID: articleID, UserID: userID, Code: r.code, CreatedAt: time.Unix(r.at, 0)}
+		result := initializers.DB.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "article_id"}, {Name: "user_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"code", "created_at"}),
+		}).Create(&row)
+		if result.Error == nil {
+			changed++
+		}
+	}
+	for userID := range inTable {
+		if _, ok := inSets[userID]; ok {
+			continue
+		}
+		result := initializers.DB.Where("article_id = ? AND user_id = ?", articleID, userID).Delete(&models.ArticleLike{})
+		if result.Error == nil {
+			changed++
+		}
+	}
+	return changed
+}
+
+// rebuildLikeSet replaces the like or dislike set of an article with the reactions of the table.
+func rebuildLikeSet(key string, code uint, inTable map[uint]reaction) error {
+	var members []redis.Z
+	for userID, r := range inTable {
+		if r.code == code {
+			members = append(members, redis.Z{Score: float64(r.at), Member: strconv.Itoa(int(userID))})
+		}
+	}
+	pipe := initializers.RDB.TxPipeline()
+	pipe.Del(initializers.RDB_CTX, key)
+	if len(members) > 0 {
+		pipe.ZAdd(initializers.RDB_CTX, key, members...)
+	}
+	if _, err := pipe.Exec(initializers.RDB_CTX); err != nil {
+		return errors.New("internal redis error: " + err.Error())
+	}
+	return nil
+}
+
+// sameReactions reports whether two sources have the same users with the reaction "code".
+func sameReactions(code uint, a, b map[uint]reaction) bool {
+	count := 0
+	for userID, r := range a {
+		if r.code != code {
+			continue
+		}
+		if other, ok := b[userID]; !ok || other.code != code {
+			return false
+		}
+		count++
+	}
+	for _, r := range b {
+		if r.code == code {
+			count--
+		}
+	}
+	return count == 0
+}
@@ -1,10 +1,12 @@
 package utils
 
 import (
+	"auth/initializers"
 	"auth/models"
 	"bytes"
 	"encoding/json"
 	"io"
+	"log/slog"
 	"strings"
 
 	"github.com/gin-gonic/gin"
@@ -28,6 +30,12 @@ func GetSubInfo(c *gin.Context) *SubInfo {
 	}
 }
 
+// GetSubEmail returns the email of the user from the context.
+func GetSubEmail(c *gin.Context) string {
+	user, _ := c.Get("user")
+	return user.(models.User).Email
+}
+
 // GetRawBody returns the raw body string from the context.
 func GetRawBody(c *gin.Context) string {
 	bodyBytes, _ := io.ReadAll(c.Request.Body)
@@ -82,3 +90,13 @@ type DataInfo struct {
 	OldData map[string]interface{} `json:"old_data"`
 	NewData map[string]interface{} `json:"new_data"`
 }
+
+// AuditedKey marks a request whose audit record has already been written by the handler.
+const AuditedKey = "audited"
+
+// Audit records the subject, object and data of the request as an audit entry.
+// It also marks the request as audited, so that the Audit middleware does not record it again.
+func Audit(c *gin.Context, level slog.Level, message string, obj ObjInfo, data DataInfo) {
+	initializers.LOGGER.Log(c, level, message, "sub", GetSubInfo(c), "obj", obj, "data", data)
+	c.Set(AuditedKey, true)
+}
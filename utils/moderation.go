@@ -0,0 +1,248 @@
+package utils
+
+import (
+	"auth/initializers"
+	"auth/models"
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"hash/fnv"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	// shingleSize is the number of consecutive tokens of a shingle
+	shingleSize = 4
+	// signatureSize is the number of hash functions of the MinHash signatures
+	signatureSize = 32
+	// duplicateWindow and duplicateCandidates bound the recent posts compared for the duplicate detection
+	duplicateWindow     = 7 * 24 * time.Hour
+	duplicateCandidates = 1000
+)
+
+// linkPattern matches the links of a text, either bare or in Markdown.
+var linkPattern = regexp.MustCompile(`(?i)\bhttps?://`)
+
+// ModerationInput is the content checked by the moderation pipeline.
+type ModerationInput struct {
+	TargetType string // "article" or "comment"
+	AuthorID   uint
+	Text       string
+}
+
+// ModerationResult is the outcome of the moderation pipeline.
+type ModerationResult struct {
+	Verdict   uint
+	RuleID    *uint
+	Detail    string
+	Signature string
+}
+
+// Moderate runs the enabled moderation rules of the target type against the content.
+// The most severe verdict of the triggered rules wins, and the content is approved if none is triggered.
+func Moderate(input ModerationInput) ModerationResult {
+	result := ModerationResult{Verdict: models.VerdictApprove, Signature: MinHashSignature(input.Text)}
+
+	var rules []models.ModerationRule
+	initializers.DB.Where("enabled = ? AND target IN (?)", true, []string{input.TargetType, "all"}).Order("id").Find(&rules)
+	for _, rule := range rules {
+		if rule.Action <= result.Verdict {
+			continue
+		}
+		triggered, detail := checkModerationRule(rule, input, result.Signature)
+		if triggered {
+			result.Verdict = rule.Action
+			result.RuleID = &rule.ID
+			result.Detail = rule.Name + ": " + detail
+		}
+	}
+	return result
+}
+
+// RecordVerdict adds the verdict on a new article or comment to the verdict log.
+func RecordVerdict(ctx context.Context, input ModerationInput, targetID uint, result ModerationResult) {
+	verdict := models.ModerationVerdict{
+		TargetType: input.TargetType,
+		TargetID:   targetID,
+		AuthorID:   input.AuthorID,
+		Verdict:    result.Verdict,
+		RuleID:     result.RuleID,
+		Detail:     result.Detail,
+		Signature:  result.Signature,
+	}
+	if err := initializers.DB.Create(&verdict).Error; err != nil {
+		initializers.LOGGER.ErrorContext(ctx, "Failed to record the moderation verdict", "error", err.Error(), "target_type", input.TargetType, "target_id", targetID)
+	}
+}
+
+// ValidateModerationRule checks the kind, target, pattern, threshold and action of a moderation rule.
+func ValidateModerationRule(rule models.ModerationRule) error {
+	switch rule.Target {
+	case models.ReportArticle, models.ReportComment, "all":
+	default:
+		return fmt.Errorf("invalid target: must be one of article, comment, or all")
+	}
+	if rule.Action != models.VerdictHold && rule.Action != models.VerdictReject {
+		return fmt.Errorf("invalid action: must be Hold(1) or Reject(2)")
+	}
+	switch rule.Kind {
+	case models.RuleKeyword:
+		if len(parseKeywords(rule.Pattern)) == 0 {
+			return fmt.Errorf("pattern is required: at least one keyword")
+		}
+	case models.RuleRegex:
+		if _, err := regexp.Compile(rule.Pattern); err != nil || rule.Pattern == "" {
+			return fmt.Errorf("invalid pattern: must be a valid regular expression")
+		}
+	case models.RuleLinks, models.RuleNewAccount:
+		if rule.Threshold < 0 {
+			return fmt.Errorf("invalid threshold: must not be negative")
+		}
+	case models.RuleDuplicate:
+		if rule.Threshold <= 0 || rule.Threshold > 100 {
+			return fmt.Errorf("invalid threshold: must be a similarity between 1 and 100")
+		}
+	default:
+		return fmt.Errorf("invalid kind: must be one of keyword, regex, links, duplicate, or new_account")
+	}
+	return nil
+}
+
+// checkModerationRule reports whether the content triggers a rule, with the reason.
+func checkModerationRule(rule models.ModerationRule, input ModerationInput, signature string) (bool, string) {
+	switch rule.Kind {
+	case models.RuleKeyword:
+		text := strings.ToLower(input.Text)
+		for _, keyword := range parseKeywords(rule.Pattern) {
+			if strings.Contains(text, keyword) {
+				return true, fmt.Sprintf("contains the keyword %q", keyword)
+			}
+		}
+	case models.RuleRegex:
+		re, err := regexp.Compile(rule.Pattern)
+		if err != nil {
+			return false, ""
+		}
+		if match := re.FindString(input.Text); match != "" {
+			return true, fmt.Sprintf("matches %q", truncateMatch(match))
+		}
+	case models.RuleLinks:
+		if links := len(linkPattern.FindAllStringIndex(input.Text, -1)); links > rule.Threshold {
+			return true, fmt.Sprintf("contains %d links, more than %d", links, rule.Threshold)
+		}
+	case models.RuleDuplicate:
+		if similarity, verdictID := findDuplicate(signature); similarity >= rule.Threshold {
+			return true, fmt.Sprintf("%d%% similar to the content of verdict %d", similarity, verdictID)
+		}
+	case models.RuleNewAccount:
+		var user models.User
+		initializers.DB.Select("created_at").Where("id = ?", input.AuthorID).Find(&user)
+		if age := time.Since(user.CreatedAt); !user.CreatedAt.IsZero() && age < time.Duration(rule.Threshold)*time.Hour {
+			return true, fmt.Sprintf("account created %s ago", age.Round(time.Minute))
+		}
+	}
+	return false, ""
+}
+
+// parseKeywords splits the pattern of a keyword rule into lowercase keywords, one per line.
+func parseKeywords(pattern string) []string {
+	var keywords []string
+	for _, line := range strings.Split(pattern, "\n") {
+		if keyword := strings.ToLower(strings.TrimSpace(line)); keyword != "" {
+			keywords = append(keywords, keyword)
+		}
+	}
+	return keywords
+}
+
+func truncateMatch(match string) string {
+	if runes := []rune(match); len(runes) > 40 {
+		return string(runes[:40]) + "…"
+	}
+	return match
+}
+
+// findDuplicate compares a signature with the recent posts, and returns the highest similarity (%)
+// along with the ID of the verdict of the most similar post.
+// The contents too short to be signed are never found duplicates.
+func findDuplicate(signature string) (int, uint) {
+	if signature == "" {
+		return 0, 0
+	}
+	var verdicts []models.ModerationVerdict
+	initializers.DB.Select("id", "signature").
+		Where("created_at >= ? AND signature <> ''", time.Now().Add(-duplicateWindow)).
+		Order("id DESC").Limit(duplicateCandidates).Find(&verdicts)
+
+	best, bestID := 0, uint(0)
+	for _, verdict := range verdicts {
+		if similarity := SignatureSimilarity(signature, verdict.Signature); similarity > best {
+			best, bestID = similarity, verdict.ID
+		}
+	}
+	return best, bestID
+}
+
+// MinHashSignature returns the hex-encoded MinHash signature of the shingles of a text,
+// or "" if the text is shorter than a shingle, since such short texts would all look like duplicates.
+// The share of equal hashes of two signatures estimates the Jaccard similarity of their shingles.
+func MinHashSignature(text string) string {
+	tokens := tokenize(text)
+	if len(tokens) < shingleSize {
+		return ""
+	}
+
+	mins := make([]uint32, signatureSize)
+	for i := range mins {
+		mins[i] = ^uint32(0)
+	}
+	for i := 0; i+shingleSize <= len(tokens); i++ {
+		h := fnv.New64a()
+		h.Write([]byte(strings.Join(tokens[i:i+shingleSize], " ")))
+		x := h.Sum64()
+		for j := range mins {
+			if v := uint32(mix64(x ^ minHashSeeds[j])); v < mins[j] {
+				mins[j] = v
+			}
+		}
+	}
+
+	buf := make([]byte, 4*signatureSize)
+	for i, v := range mins {
+		binary.BigEndian.PutUint32(buf[4*i:], v)
+	}
+	return hex.EncodeToString(buf)
+}
+
+// SignatureSimilarity returns the estimated similarity (%) of the contents of two MinHash signatures.
+func SignatureSimilarity(a string, b string) int {
+	if len(a) != 8*signatureSize || len(a) != len(b) {
+		return 0
+	}
+	equal := 0
+	for i := 0; i < len(a); i += 8 {
+		if a[i:i+8] == b[i:i+8] {
+			equal++
+		}
+	}
+	return equal * 100 / signatureSize
+}
+
+// minHashSeeds derive the hash functions of the MinHash signatures from a single hash.
+var minHashSeeds = func() []uint64 {
+	seeds := make([]uint64, signatureSize)
+	for i := range seeds {
+		seeds[i] = mix64(uint64(i+1) * 0x9e3779b97f4a7c15)
+	}
+	return seeds
+}()
+
+// mix64 is the finalizer of SplitMix64.
+func mix64(x uint64) uint64 {
+	x = (x ^ (x >> 30)) * 0xbf58476d1ce4e5b9
+	x = (x ^ (x >> 27)) * 0x94d049bb133111eb
+	return x ^ (x >> 31)
+}
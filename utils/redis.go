@@ -2,8 +2,7 @@ package utils
 
 import (
 	"auth/initializers"
-	"os"
-	"path/filepath"
+	_ "embed"
 	"time"
 
 	"github.com/redis/go-redis/v9"
@@ -11,49 +10,100 @@ import (
 
 // RedisConstants contains constants for Redis keys and expiration times.
 var RedisConstants = struct {
-	LOGIN_CODE_KEY_PREFIX       string
-	LOGIN_CODE_EXPIRE_TIME      time.Duration
-	CACHE_USER_KEY_PREFIX       string
-	CACHE_USER_EXPIRE_TIME      time.Duration
-	CACHE_NULL_EXPIRE_TIME      time.Duration
-	MUTEX_USER_KEY_PREFIX       string
-	MUTEX_USER_EXPIRE_TIME      time.Duration
-	SECKILL_STOCK_KEY_PREFIX    string
-	SECKILL_ORDER_KEY_PREFIX    string
-	ARTICLE_LIKED_KEY_PREFIX    string
-	ARTICLE_DISLIKED_KEY_PREFIX string
-	SIGN_IN_KEY_PREFIX          string
+	LOGIN_CODE_KEY_PREFIX            string
+	LOGIN_CODE_EXPIRE_TIME           time.Duration
+	CACHE_USER_KEY_PREFIX            string
+	CACHE_USER_EXPIRE_TIME           time.Duration
+	CACHE_NULL_EXPIRE_TIME           time.Duration
+	MUTEX_USER_KEY_PREFIX            string
+	MUTEX_USER_EXPIRE_TIME           time.Duration
+	SECKILL_STOCK_KEY_PREFIX         string
+	SECKILL_ORDER_KEY_PREFIX         string
+	ARTICLE_LIKED_KEY_PREFIX         string
+	ARTICLE_DISLIKED_KEY_PREFIX      string
+	SIGN_IN_KEY_PREFIX               string
+	ANOMALY_COUNTER_KEY_PREFIX       string
+	ANOMALY_ALERT_KEY_PREFIX         string
+	ANOMALY_DENIAL_KEY               string
+	STATS_KEY_PREFIX                 string
+	STATS_EXPIRE_TIME                time.Duration
+	CACHE_STATS_KEY_PREFIX           string
+	CACHE_STATS_EXPIRE_TIME          time.Duration
+	TRENDING_KEY_PREFIX              string
+	VIEWS_PV_KEY_PREFIX              string
+	VIEWS_UV_KEY_PREFIX              string
+	VIEWS_DIRTY_KEY                  string
+	VIEWS_EXPIRE_TIME                time.Duration
+	MUTEX_ATTACHMENT_KEY_PREFIX      string
+	MUTEX_ATTACHMENT_EXPIRE_TIME     time.Duration
+	IMPORT_JOB_KEY_PREFIX            string
+	IMPORT_JOB_EXPIRE_TIME           time.Duration
+	MUTEX_IMPORT_KEY_PREFIX          string
+	MUTEX_IMPORT_EXPIRE_TIME         time.Duration
+	BOOKMARK_KEY_PREFIX              string
+	BOOKMARK_EXPIRE_TIME             time.Duration
+	MUTEX_LIKE_RECONCILE_KEY         string
+	MUTEX_LIKE_RECONCILE_EXPIRE_TIME time.Duration
+	LIKE_RECONCILE_JOB_KEY_PREFIX    string
+	LIKE_RECONCILE_JOB_EXPIRE_TIME   time.Duration
 }{
-	LOGIN_CODE_KEY_PREFIX:       "login:code:",
-	LOGIN_CODE_EXPIRE_TIME:      5 * time.Minute,
-	CACHE_USER_KEY_PREFIX:       "cache:user:",
-	CACHE_USER_EXPIRE_TIME:      30 * time.Minute,
-	CACHE_NULL_EXPIRE_TIME:      2 * time.Minute,
-	MUTEX_USER_KEY_PREFIX:       "mutex:user:",
-	MUTEX_USER_EXPIRE_TIME:      1 * time.Second,
-	SECKILL_STOCK_KEY_PREFIX:    "seckill:stock:",
-	SECKILL_ORDER_KEY_PREFIX:    "seckill:order:",
-	ARTICLE_LIKED_KEY_PREFIX:    "article:liked:",
-	ARTICLE_DISLIKED_KEY_PREFIX: "article:disliked:",
-	SIGN_IN_KEY_PREFIX:          "sign_in:",
+	LOGIN_CODE_KEY_PREFIX:            "login:code:",
+	LOGIN_CODE_EXPIRE_TIME:           5 * time.Minute,
+	CACHE_USER_KEY_PREFIX:            "cache:user:",
+	CACHE_USER_EXPIRE_TIME:           30 * time.Minute,
+	CACHE_NULL_EXPIRE_TIME:           2 * time.Minute,
+	MUTEX_USER_KEY_PREFIX:            "mutex:user:",
+	MUTEX_USER_EXPIRE_TIME:           1 * time.Second,
+	SECKILL_STOCK_KEY_PREFIX:         "seckill:stock:",
+	SECKILL_ORDER_KEY_PREFIX:         "seckill:order:",
+	ARTICLE_LIKED_KEY_PREFIX:         "article:liked:",
+	ARTICLE_DISLIKED_KEY_PREFIX:      "article:disliked:",
+	SIGN_IN_KEY_PREFIX:               "sign_in:",
+	ANOMALY_COUNTER_KEY_PREFIX:       "anomaly:counter:",
+	ANOMALY_ALERT_KEY_PREFIX:         "anomaly:alert:",
+	ANOMALY_DENIAL_KEY:               "anomaly:denials",
+	STATS_KEY_PREFIX:                 "stats:",
+	STATS_EXPIRE_TIME:                62 * 31 * 24 * time.Hour, // Longer than the longest range of the statistics (60 months)
+	CACHE_STATS_KEY_PREFIX:           "cache:stats:",
+	CACHE_STATS_EXPIRE_TIME:          1 * time.Minute,
+	TRENDING_KEY_PREFIX:              "trending:",
+	VIEWS_PV_KEY_PREFIX:              "views:pv:",
+	VIEWS_UV_KEY_PREFIX:              "views:uv:",
+	VIEWS_DIRTY_KEY:                  "views:dirty",
+	VIEWS_EXPIRE_TIME:                3 * 24 * time.Hour,
+	MUTEX_ATTACHMENT_KEY_PREFIX:      "mutex:attachment:",
+	MUTEX_ATTACHMENT_EXPIRE_TIME:     30 * time.Second,
+	IMPORT_JOB_KEY_PREFIX:            "import:job:",
+	IMPORT_JOB_EXPIRE_TIME:           24 * time.Hour,
+	MUTEX_IMPORT_KEY_PREFIX:          "mutex:import:",
+	MUTEX_IMPORT_EXPIRE_TIME:         1 * time.Hour,
+	BOOKMARK_KEY_PREFIX:              "bookmark:",
+	BOOKMARK_EXPIRE_TIME:             24 * time.Hour,
+	MUTEX_LIKE_RECONCILE_KEY:         "mutex:like:reconcile",
+	MUTEX_LIKE_RECONCILE_EXPIRE_TIME: 1 * time.Hour,
+	LIKE_RECONCILE_JOB_KEY_PREFIX:    "like:reconcile:job:",
+	LIKE_RECONCILE_JOB_EXPIRE_TIME:   24 * time.Hour,
 }
 
+// The contents of the scripts are embedded, so that the scripts load whatever the working directory
+//
+//go:embed scripts/seckill.lua
+var seckillScriptContent string
+
+//go:embed scripts/like.lua
+var likeScriptContent string
+
+//go:embed scripts/bookmark.lua
+var bookmarkScriptContent string
+
 // SeckillScript is a Lua script used for atomic seckill operations in Redis
-var SeckillScript *redis.Script
+var SeckillScript = redis.NewScript(seckillScriptContent)
 
-func init() {
-	// Initialize the SeckillScript from ./scripts/seckill.lua
-	workingDir, err := os.Getwd()
-	if err != nil {
-		panic("Failed to get working directory: " + err.Error())
-	}
-	seckillScriptPath := filepath.Join(workingDir, "utils", "scripts", "seckill.lua")
-	seckillScriptContent, err := os.ReadFile(seckillScriptPath)
-	if err != nil {
-		panic("Failed to read seckill script: " + err.Error())
-	}
-	SeckillScript = redis.NewScript(string(seckillScriptContent))
-}
+// LikeScript is a Lua script used for atomic like and dislike operations in Redis
+var LikeScript = redis.NewScript(likeScriptContent)
+
+// BookmarkScript is a Lua script used for updating the cached bookmarks of a reader in Redis, if they are cached
+var BookmarkScript = redis.NewScript(bookmarkScriptContent)
 
 func SimpleTryLock(key string, ttl time.Duration) bool {
 	return initializers.RDB.SetNX(initializers.RDB_CTX, key, "1", ttl).Val()
@@ -0,0 +1,13 @@
+-- keys: KEYS[1] = the cached bookmark set of a reader
+-- parameters: ARGV[1] = the command (sadd or srem), ARGV[2...] = articleIDs
+
+-- returns: the number of articles added or removed
+
+-- 1. Leave a set that is not cached to be loaded off MySQL on the next read,
+-- as a partial set would hide the other bookmarks
+if redis.call('exists', KEYS[1]) == 0 then
+    return 0
+end
+
+-- 2. Add or remove the articles
+return redis.call(ARGV[1], KEYS[1], unpack(ARGV, 2))
@@ -0,0 +1,22 @@
+-- keys: KEYS[1] = the set of the reaction, KEYS[2] = the set of the opposite reaction
+-- parameters: ARGV[1] = userID, ARGV[2] = timestamp
+local userID = ARGV[1]
+local timestamp = ARGV[2]
+
+-- returns: {result} or {1, the score of the cancelled reaction}
+
+-- 1. Cancel the reaction if the user has already reacted so
+local score = redis.call('zscore', KEYS[1], userID)
+if score then
+    redis.call('zrem', KEYS[1], userID)
+    return {1, score} -- Cancelled
+end
+
+-- 2. Check if the user has already reacted the opposite way
+if redis.call('zscore', KEYS[2], userID) then
+    return {2} -- Already reacted the opposite way
+end
+
+-- Add the user to the set of the reaction
+redis.call('zadd', KEYS[1], timestamp, userID)
+return {0} -- Success
@@ -1,8 +1,9 @@
--- parameters: ARGV[1] = readerID, ARGV[2] = authorID, ARGV[3] = discountID, ARGV[4] = credits
+-- parameters: ARGV[1] = readerID, ARGV[2] = authorID, ARGV[3] = discountID, ARGV[4] = credits, ARGV[5] = requestID
 local readerID = ARGV[1]
 local authorID = ARGV[2]
 local discountID = ARGV[3]
 local credits = ARGV[4]
+local requestID = ARGV[5]
 -- keys:
 local stockKey = 'seckill:stock:' .. discountID
 local orderKey = 'seckill:order:' .. discountID
@@ -31,6 +32,7 @@ redis.call('xadd', 'stream.orders', '*',
     'readerID', readerID,
     'authorID', authorID,
     'discountID', discountID,
-    'credits', credits
+    'credits', credits,
+    'requestID', requestID
 )
 return 0 -- Success
\ No newline at end of file
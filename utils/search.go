@@ -0,0 +1,298 @@
+package utils
+
+import (
+	"auth/initializers"
+	"auth/models"
+	"context"
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// SearchIndex is a pluggable full-text index of the approved articles and comments.
+type SearchIndex interface {
+	// Put adds the documents to the index, replacing the ones with the same kind and reference ID.
+	Put(docs ...models.SearchDocument) error
+	// DeleteArticle removes an article and all its comments from the index.
+	DeleteArticle(articleID uint) error
+	// DeleteComment removes a comment from the index.
+	DeleteComment(commentID uint) error
+	// Search returns a page of the documents matching the query, ranked by relevance, and the total number of matches.
+	Search(query SearchQuery) ([]SearchHit, int64, error)
+}
+
+// SearchQuery is a parsed search query with its filters and pagination.
+type SearchQuery struct {
+	Terms    []string // Every term must match
+	Phrases  []string // Every phrase must match exactly
+	Kind     string   // Optional kind of the documents (see models.SearchArticle)
+	AuthorID uint     // Optional author of the documents
+	From     *time.Time
+	To       *time.Time // Exclusive
+	Offset   int
+	Limit    int
+}
+
+// SearchHit is a document matching a search query.
+type SearchHit struct {
+	models.SearchDocument `gorm:"embedded"`
+	Score                 float64
+}
+
+var SEARCH SearchIndex
+
+// InitSearch initializes the search index with the backend configured in SEARCH_BACKEND.
+// The memory index is rebuilt from the database at startup, and the MySQL index is filled on its first run.
+func InitSearch() {
+	switch initializers.SearchBackend {
+	case "memory":
+		SEARCH = newMemoryIndex()
+	case "mysql":
+		SEARCH = mysqlIndex{}
+		var count int64
+		initializers.DB.Model(&models.SearchDocument{}).Count(&count)
+		if count > 0 {
+			return
+		}
+	default:
+		panic("Invalid search backend: " + initializers.SearchBackend)
+	}
+
+	docs, err := loadSearchDocuments()
+	if err != nil {
+		panic("Failed to build the search index: " + err.Error())
+	}
+	if err := SEARCH.Put(docs...); err != nil {
+		panic("Failed to build the search index: " + err.Error())
+	}
+}
+
+// IndexArticle synchronizes an article and its approved comments with the search index.
+// Only the approved and listed articles are searchable, the others are removed from the index.
+func IndexArticle(ctx context.Context, articleID uint) {
+	var article models.Article
+	result := initializers.DB.Where("id = ?", articleID).Limit(1).Find(&article)
+	if result.Error != nil {
+		initializers.LOGGER.ErrorContext(ctx, "Failed to update the search index", "error", result.Error.Error(), "article_id", articleID)
+		return
+	}
+
+	// Remove the article with its stale comments first
+	if err := SEARCH.DeleteArticle(articleID); err != nil {
+		initializers.LOGGER.ErrorContext(ctx, "Failed to update the search index", "error", err.Error(), "article_id", articleID)
+		return
+	}
+	if result.RowsAffected == 0 || !isSearchable(article) {
+		return
+	}
+
+	var comments []models.Comment
+	initializers.DB.Where("article_id = ? AND status = ?", articleID, models.Approved).Find(&comments)
+	docs := []models.SearchDocument{articleDocument(article)}
+	for _, comment := range comments {
+		docs = append(docs, commentDocument(comment))
+	}
+	if err := SEARCH.Put(docs...); err != nil {
+		initializers.LOGGER.ErrorContext(ctx, "Failed to update the search index", "error", err.Error(), "article_id", articleID)
+	}
+}
+
+// IndexComment synchronizes a comment with the search index.
+// Only the approved comments on searchable articles are searchable.
+func IndexComment(ctx context.Context, commentID uint) {
+	var comment models.Comment
+	initializers.DB.Where("id = ?", commentID).Limit(1).Find(&comment)
+	var article models.Article
+	if comment.ID != 0 {
+		initializers.DB.Where("id = ?", comment.ArticleID).Limit(1).Find(&article)
+	}
+
+	var err error
+	if comment.ID != 0 && comment.Status == models.Approved && article.ID != 0 && isSearchable(article) {
+		err = SEARCH.Put(commentDocument(comment))
+	} else {
+		err = SEARCH.DeleteComment(commentID)
+	}
+	if err != nil {
+		initializers.LOGGER.ErrorContext(ctx, "Failed to update the search index", "error", err.Error(), "comment_id", commentID)
+	}
+}
+
+// ParseSearchQuery splits a raw query into terms and "quoted phrases".
+func ParseSearchQuery(raw string) (terms []string, phrases []string) {
+	parts := strings.Split(raw, `"`)
+	for i, part := range parts {
+		// The odd parts are enclosed in quotes (an unclosed quote extends to the end)
+		if i%2 == 1 {
+			if phrase := strings.Join(tokenize(part), " "); phrase != "" {
+				phrases = append(phrases, phrase)
+			}
+			continue
+		}
+		for _, field := range strings.Fields(part) {
+			if term := strings.Join(tokenize(field), " "); term != "" {
+				terms = append(terms, term)
+			}
+		}
+	}
+	return terms, phrases
+}
+
+// Snippet returns an HTML excerpt of the text around the first match of the terms or phrases.
+// The text is escaped and the matches are wrapped in <mark> tags.
+func Snippet(text string, terms []string, phrases []string, width int) string {
+	var patterns []string
+	for _, word := range append(append([]string{}, phrases...), terms...) {
+		// A token sequence matches across any separators
+		var tokens []string
+		for _, token := range strings.Fields(word) {
+			tokens = append(tokens, regexp.QuoteMeta(token))
+		}
+		if len(tokens) == 0 {
+			continue
+		}
+		// Match whole words only, if the edges are ASCII (RE2 word boundaries are ASCII-only)
+		pattern := strings.Join(tokens, `[^\pL\pN]*`)
+		if isASCIIWord(word[0]) {
+			pattern = `\b` + pattern
+		}
+		if isASCIIWord(word[len(word)-1]) {
+			pattern += `\b`
+		}
+		patterns = append(patterns, pattern)
+	}
+	if len(patterns) == 0 {
+		window, _ := truncate(text, 0, width)
+		return html.EscapeString(window)
+	}
+	re := regexp.MustCompile(`(?i)` + strings.Join(patterns, "|"))
+
+	// Center the window on the first match
+	start := 0
+	if loc := re.FindStringIndex(text); loc != nil {
+		start = loc[0] - width/4
+		if start < 0 {
+			start = 0
+		}
+	}
+	window, start := truncate(text, start, width)
+
+	var b strings.Builder
+	if start > 0 {
+		b.WriteString("…")
+	}
+	last := 0
+	for _, loc := range re.FindAllStringIndex(window, -1) {
+		b.WriteString(html.EscapeString(window[last:loc[0]]))
+		b.WriteString("<mark>" + html.EscapeString(window[loc[0]:loc[1]]) + "</mark>")
+		last = loc[1]
+	}
+	b.WriteString(html.EscapeString(window[last:]))
+	if start+len(window) < len(text) {
+		b.WriteString("…")
+	}
+	return b.String()
+}
+
+// truncate returns at most width bytes of the text from about start, cut on rune boundaries, and its actual start.
+func truncate(text string, start int, width int) (string, int) {
+	for start > 0 && start < len(text) && !utf8.RuneStart(text[start]) {
+		start--
+	}
+	end := start + width
+	if end >= len(text) {
+		return text[start:], start
+	}
+	for end > start && !utf8.RuneStart(text[end]) {
+		end--
+	}
+	return text[start:end], start
+}
+
+// tokenize lowercases the text and splits it into words.
+// CJK characters, which are not separated by spaces, become single-character tokens.
+func tokenize(text string) []string {
+	var tokens []string
+	var word []rune
+	flush := func() {
+		if len(word) > 0 {
+			tokens = append(tokens, string(word))
+			word = word[:0]
+		}
+	}
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case isCJK(r):
+			flush()
+			tokens = append(tokens, string(r))
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			word = append(word, r)
+		default:
+			flush()
+		}
+	}
+	flush()
+	return tokens
+}
+
+func isASCIIWord(b byte) bool {
+	return b < utf8.RuneSelf && (unicode.IsLetter(rune(b)) || unicode.IsDigit(rune(b)))
+}
+
+// isSearchable reports whether an article is visible in the search results.
+func isSearchable(article models.Article) bool {
+	return article.Status == models.Approved && !article.Unlisted
+}
+
+func articleDocument(article models.Article) models.SearchDocument {
+	return models.SearchDocument{
+		Kind:      models.SearchArticle,
+		RefID:     article.ID,
+		ArticleID: article.ID,
+		AuthorID:  article.AuthorID,
+		Title:     article.Title,
+		Body:      article.Body,
+		PostedAt:  article.CreatedAt,
+	}
+}
+
+func commentDocument(comment models.Comment) models.SearchDocument {
+	return models.SearchDocument{
+		Kind:      models.SearchComment,
+		RefID:     comment.ID,
+		ArticleID: comment.ArticleID,
+		AuthorID:  comment.AuthorID,
+		Body:      comment.Content,
+		PostedAt:  comment.CreatedAt,
+	}
+}
+
+// loadSearchDocuments loads all the searchable articles and comments from the database.
+func loadSearchDocuments() ([]models.SearchDocument, error) {
+	var articles []models.Article
+	result := initializers.DB.Where("status = ? AND unlisted = ?", models.Approved, false).Find(&articles)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to load the articles: %w", result.Error)
+	}
+	var comments []models.Comment
+	result = initializers.DB.
+		Joins("JOIN articles ON articles.id = comments.article_id AND articles.deleted_at IS NULL").
+		Where("comments.status = ? AND articles.status = ? AND articles.unlisted = ?", models.Approved, models.Approved, false).
+		Find(&comments)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to load the comments: %w", result.Error)
+	}
+
+	docs := make([]models.SearchDocument, 0, len(articles)+len(comments))
+	for _, article := range articles {
+		docs = append(docs, articleDocument(article))
+	}
+	for _, comment := range comments {
+		docs = append(docs, commentDocument(comment))
+	}
+	return docs, nil
+}
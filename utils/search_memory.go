@@ -0,0 +1,179 @@
+package utils
+
+import (
+	"auth/models"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// titleWeight is the weight of a title token over a body token in the memory index.
+const titleWeight = 2
+
+// memoryIndex is an embedded inverted index, for the setups without MySQL FULLTEXT support.
+// It is not persisted, so it is rebuilt from the database at startup.
+type memoryIndex struct {
+	mu       sync.RWMutex
+	docs     map[string]*memoryDocument
+	postings map[string]map[string]int // token -> document key -> weighted term frequency
+}
+
+type memoryDocument struct {
+	models.SearchDocument
+	text   string // Tokenized title and body joined by spaces, for the phrase matching
+	tokens map[string]int
+}
+
+func newMemoryIndex() *memoryIndex {
+	return &memoryIndex{
+		docs:     make(map[string]*memoryDocument),
+		postings: make(map[string]map[string]int),
+	}
+}
+
+func memoryKey(kind string, refID uint) string {
+	return kind + ":" + strconv.Itoa(int(refID))
+}
+
+func (m *memoryIndex) Put(docs ...models.SearchDocument) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, doc := range docs {
+		key := memoryKey(doc.Kind, doc.RefID)
+		m.remove(key)
+
+		titleTokens, bodyTokens := tokenize(doc.Title), tokenize(doc.Body)
+		tokens := make(map[string]int)
+		for _, token := range titleTokens {
+			tokens[token] += titleWeight
+		}
+		for _, token := range bodyTokens {
+			tokens[token]++
+		}
+		for token, freq := range tokens {
+			if m.postings[token] == nil {
+				m.postings[token] = make(map[string]int)
+			}
+			m.postings[token][key] = freq
+		}
+		m.docs[key] = &memoryDocument{
+			SearchDocument: doc,
+			text:           " " + strings.Join(titleTokens, " ") + " | " + strings.Join(bodyTokens, " ") + " ",
+			tokens:         tokens,
+		}
+	}
+	return nil
+}
+
+func (m *memoryIndex) DeleteArticle(articleID uint) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for key, doc := range m.docs {
+		if doc.ArticleID == articleID {
+			m.remove(key)
+		}
+	}
+	return nil
+}
+
+func (m *memoryIndex) DeleteComment(commentID uint) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.remove(memoryKey(models.SearchComment, commentID))
+	return nil
+}
+
+// remove removes a document, the caller must hold the lock.
+func (m *memoryIndex) remove(key string) {
+	doc, ok := m.docs[key]
+	if !ok {
+		return
+	}
+	for token := range doc.tokens {
+		delete(m.postings[token], key)
+		if len(m.postings[token]) == 0 {
+			delete(m.postings, token)
+		}
+	}
+	delete(m.docs, key)
+}
+
+func (m *memoryIndex) Search(query SearchQuery) ([]SearchHit, int64, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	// Every token of the terms and phrases must match
+	var tokens []string
+	for _, word := range append(append([]string{}, query.Terms...), query.Phrases...) {
+		tokens = append(tokens, strings.Fields(word)...)
+	}
+	if len(tokens) == 0 {
+		return nil, 0, nil
+	}
+
+	// Start from the rarest token to keep the candidates few
+	sort.Slice(tokens, func(i, j int) bool { return len(m.postings[tokens[i]]) < len(m.postings[tokens[j]]) })
+	var hits []SearchHit
+	for key := range m.postings[tokens[0]] {
+		doc := m.docs[key]
+		if !m.matches(doc, tokens, query) {
+			continue
+		}
+
+		// Rank the document by TF-IDF
+		var score float64
+		for _, token := range tokens {
+			idf := math.Log(1 + float64(len(m.docs))/float64(len(m.postings[token])))
+			score += float64(doc.tokens[token]) * idf
+		}
+		hits = append(hits, SearchHit{SearchDocument: doc.SearchDocument, Score: score})
+	}
+
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].PostedAt.After(hits[j].PostedAt)
+	})
+
+	// Get the page of the hits
+	total := int64(len(hits))
+	if query.Offset >= len(hits) {
+		return []SearchHit{}, total, nil
+	}
+	end := query.Offset + query.Limit
+	if end > len(hits) {
+		end = len(hits)
+	}
+	return hits[query.Offset:end], total, nil
+}
+
+// matches reports whether a document contains all the tokens and phrases and passes the filters.
+func (m *memoryIndex) matches(doc *memoryDocument, tokens []string, query SearchQuery) bool {
+	for _, token := range tokens {
+		if doc.tokens[token] == 0 {
+			return false
+		}
+	}
+	// Terms made of several tokens (e.g. "e-mail") are matched as phrases too
+	for _, word := range append(append([]string{}, query.Terms...), query.Phrases...) {
+		if strings.Contains(word, " ") && !strings.Contains(doc.text, " "+word+" ") {
+			return false
+		}
+	}
+	if query.Kind != "" && doc.Kind != query.Kind {
+		return false
+	}
+	if query.AuthorID != 0 && doc.AuthorID != query.AuthorID {
+		return false
+	}
+	if query.From != nil && doc.PostedAt.Before(*query.From) {
+		return false
+	}
+	if query.To != nil && !doc.PostedAt.Before(*query.To) {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,95 @@
+package utils
+
+import (
+	"auth/initializers"
+	"auth/models"
+	"strings"
+	"unicode"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// mysqlIndex is the search index backed by the FULLTEXT index of the search_documents table.
+type mysqlIndex struct{}
+
+const mysqlMatch = "MATCH(title, body) AGAINST(? IN BOOLEAN MODE)"
+
+func (mysqlIndex) Put(docs ...models.SearchDocument) error {
+	if len(docs) == 0 {
+		return nil
+	}
+	return initializers.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "kind"}, {Name: "ref_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"article_id", "author_id", "title", "body", "posted_at"}),
+	}).CreateInBatches(&docs, 100).Error
+}
+
+func (mysqlIndex) DeleteArticle(articleID uint) error {
+	return initializers.DB.Where("article_id = ?", articleID).Delete(&models.SearchDocument{}).Error
+}
+
+func (mysqlIndex) DeleteComment(commentID uint) error {
+	return initializers.DB.Where("kind = ? AND ref_id = ?", models.SearchComment, commentID).Delete(&models.SearchDocument{}).Error
+}
+
+func (mysqlIndex) Search(query SearchQuery) ([]SearchHit, int64, error) {
+	// Build the boolean query, in which every term and phrase is required
+	var clauses []string
+	for _, term := range query.Terms {
+		if strings.Contains(term, " ") {
+			clauses = append(clauses, `+"`+joinCJK(term)+`"`)
+		} else {
+			clauses = append(clauses, "+"+term)
+		}
+	}
+	for _, phrase := range query.Phrases {
+		clauses = append(clauses, `+"`+joinCJK(phrase)+`"`)
+	}
+	against := strings.Join(clauses, " ")
+
+	// Apply the filters
+	tx := initializers.DB.Model(&models.SearchDocument{}).Where(mysqlMatch, against)
+	if query.Kind != "" {
+		tx = tx.Where("kind = ?", query.Kind)
+	}
+	if query.AuthorID != 0 {
+		tx = tx.Where("author_id = ?", query.AuthorID)
+	}
+	if query.From != nil {
+		tx = tx.Where("posted_at >= ?", *query.From)
+	}
+	if query.To != nil {
+		tx = tx.Where("posted_at < ?", *query.To)
+	}
+
+	var total int64
+	if err := tx.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var hits []SearchHit
+	err := tx.Session(&gorm.Session{}).
+		Select("*, "+mysqlMatch+" AS score", against).
+		Order("score DESC, posted_at DESC").
+		Offset(query.Offset).Limit(query.Limit).
+		Scan(&hits).Error
+	return hits, total, err
+}
+
+// joinCJK removes the spaces between CJK characters, which the ngram parser expects to be contiguous.
+func joinCJK(s string) string {
+	runes := []rune(s)
+	var b strings.Builder
+	for i, r := range runes {
+		if r == ' ' && i > 0 && i < len(runes)-1 && isCJK(runes[i-1]) && isCJK(runes[i+1]) {
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+func isCJK(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul)
+}
@@ -0,0 +1,69 @@
+package utils
+
+import (
+	"strings"
+	"unicode"
+)
+
+// maxSlugLength is the maximum number of characters of a slug, before its disambiguating suffix.
+const maxSlugLength = 80
+
+// latinFolding transliterates the common accented Latin letters and ligatures to ASCII.
+var latinFolding = map[rune]string{
+	'à': "a", 'á': "a", 'â': "a", 'ã': "a", 'ä': "a", 'å': "a", 'ā': "a", 'ą': "a", 'ă': "a",
+	'ç': "c", 'ć': "c", 'č': "c", 'ď': "d", 'đ': "d", 'ð': "d",
+	'è': "e", 'é': "e", 'ê': "e", 'ë': "e", 'ē': "e", 'ę': "e", 'ě': "e",
+	'ì': "i", 'í': "i", 'î': "i", 'ï': "i", 'ī': "i", 'ı': "i",
+	'ł': "l", 'ñ': "n", 'ń': "n", 'ň': "n",
+	'ò': "o", 'ó': "o", 'ô': "o", 'õ': "o", 'ö': "o", 'ø': "o", 'ō': "o", 'ő': "o",
+	'ř': "r", 'ś': "s", 'š': "s", 'ş': "s", 'ť': "t", 'ţ': "t",
+	'ù': "u", 'ú': "u", 'û': "u", 'ü': "u", 'ū': "u", 'ů': "u", 'ű': "u",
+	'ý': "y", 'ÿ': "y", 'ź': "z", 'ż': "z", 'ž': "z",
+	'ß': "ss", 'æ': "ae", 'œ': "oe", 'þ': "th",
+}
+
+// Slugify turns a title into the ASCII words of a URL slug, separated by hyphens.
+// Accented Latin letters are transliterated to ASCII, and the other Latin letters are dropped.
+// Titles with letters of other scripts (e.g. CJK) have no transliteration here, so Slugify returns ""
+// for them, as for the titles without letters or digits, and the caller has to fall back on another slug.
+func Slugify(title string) string {
+	var b strings.Builder
+	full := false
+	hyphen := false
+	for _, r := range strings.ToLower(title) {
+		var s string
+		switch {
+		case r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+			s = string(r)
+		case latinFolding[r] != "":
+			s = latinFolding[r]
+		case unicode.Is(unicode.Latin, r) || unicode.IsMark(r):
+			continue
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			return ""
+		default:
+			hyphen = b.Len() > 0
+			continue
+		}
+		// Keep going once the slug is full, for the letters of other scripts
+		n := len(s)
+		if hyphen {
+			n++
+		}
+		if full || b.Len()+n > maxSlugLength {
+			full = true
+			continue
+		}
+		if hyphen {
+			b.WriteByte('-')
+			hyphen = false
+		}
+		b.WriteString(s)
+	}
+	return b.String()
+}
+
+// IsNumericSlug reports whether a slug is only made of digits, which could be mistaken for an ID.
+func IsNumericSlug(slug string) bool {
+	return strings.Trim(slug, "0123456789") == ""
+}
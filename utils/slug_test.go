@@ -0,0 +1,60 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSlugify(t *testing.T) {
+	tests := []struct {
+		title string
+		want  string
+	}{
+		{"Hello, World!", "hello-world"},
+		{"  Leading and trailing  ", "leading-and-trailing"},
+		{"Hello, Wörld! Ça va?", "hello-world-ca-va"},
+		{"Straße & Œuvre", "strasse-oeuvre"},
+		{"C++ vs. C#", "c-vs-c"},
+		{"Ünïcödé 2.0", "unicode-2-0"},
+		{"2024", "2024"},
+		// The titles with letters of other scripts are left to the caller, even mixed with Latin words
+		{"Go 语言入门", ""},
+		{"Go 语言入门 Guide", ""},
+		{"Привет, мир", ""},
+		{"!!! ---", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := Slugify(tt.title); got != tt.want {
+			t.Errorf("Slugify(%q) = %q, want %q", tt.title, got, tt.want)
+		}
+	}
+
+	// The slugs are cut at maxSlugLength characters, without a trailing hyphen
+	for _, title := range []string{strings.Repeat("word ", 40), strings.Repeat("ß", 50), strings.Repeat("a ", 60)} {
+		if got := Slugify(title); len(got) > maxSlugLength || strings.HasSuffix(got, "-") {
+			t.Errorf("Slugify(%q) = %q, want at most %d characters without a trailing hyphen", title, got, maxSlugLength)
+		}
+	}
+	if got := Slugify(strings.Repeat("word ", 40) + "语言"); got != "" {
+		t.Errorf("Slugify(long title with CJK) = %q, want \"\"", got)
+	}
+}
+
+func TestIsNumericSlug(t *testing.T) {
+	tests := []struct {
+		slug string
+		want bool
+	}{
+		{"2024", true},
+		{"42", true},
+		{"2024-review", false},
+		{"article-42", false},
+		{"hello", false},
+	}
+	for _, tt := range tests {
+		if got := IsNumericSlug(tt.slug); got != tt.want {
+			t.Errorf("IsNumericSlug(%q) = %v, want %v", tt.slug, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package utils
+
+import (
+	"auth/initializers"
+	"time"
+)
+
+// Stat is the name of a daily counter kept in Redis for the admin statistics.
+const (
+	StatSignUps       = "signups"
+	StatLogins        = "logins"
+	StatSubscriptions = "subscriptions"
+	StatSeckillOrders = "seckill_orders"
+	StatCreditsMoved  = "credits_moved"
+)
+
+// statKey returns the key of the counter of the given day.
+func statKey(name string, day time.Time) string {
+	return RedisConstants.STATS_KEY_PREFIX + name + ":" + day.Format("20060102")
+}
+
+// IncrStat adds n to today's counter of the stat.
+func IncrStat(name string, n int64) {
+	key := statKey(name, time.Now())
+	pipe := initializers.RDB.Pipeline()
+	pipe.IncrBy(initializers.RDB_CTX, key, n)
+	pipe.ExpireNX(initializers.RDB_CTX, key, RedisConstants.STATS_EXPIRE_TIME)
+	pipe.Exec(initializers.RDB_CTX)
+}
+
+// GetStat returns the counters of the stat for the given days.
+func GetStat(name string, days []time.Time) []int64 {
+	counts := make([]int64, len(days))
+	if len(days) == 0 {
+		return counts
+	}
+	keys := make([]string, len(days))
+	for i, day := range days {
+		keys[i] = statKey(name, day)
+	}
+	values, err := initializers.RDB.MGet(initializers.RDB_CTX, keys...).Result()
+	if err != nil {
+		return counts
+	}
+	for i, value := range values {
+		if str, ok := value.(string); ok {
+			counts[i] = int64(StrToInt(str))
+		}
+	}
+	return counts
+}
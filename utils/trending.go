@@ -0,0 +1,89 @@
+package utils
+
+import (
+	"auth/initializers"
+	"context"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// HotWeight is the contribution of an event on an article to its hot score.
+const (
+	HotView    = 1.0
+	HotComment = 3.0
+	HotLike    = 5.0
+	HotDislike = -2.0
+)
+
+const (
+	// minHotScore is the score under which an article drops out of the trending sets
+	minHotScore = 0.1
+	// maxTrending is the number of articles kept in each trending set
+	maxTrending = 1000
+)
+
+// TrendingWindow is a trending set, whose scores lose half their value every half-life.
+type TrendingWindow struct {
+	Name     string
+	HalfLife time.Duration
+}
+
+// TrendingWindows contains the trending sets by name.
+var TrendingWindows = map[string]TrendingWindow{
+	"day":  {Name: "day", HalfLife: 6 * time.Hour},
+	"week": {Name: "week", HalfLife: 48 * time.Hour},
+}
+
+// Key returns the Redis key of the trending set.
+func (w TrendingWindow) Key() string {
+	return RedisConstants.TRENDING_KEY_PREFIX + w.Name
+}
+
+// RecordHotEvent adds the weight of an event on an article to its hot scores.
+func RecordHotEvent(ctx context.Context, articleID uint, weight float64) {
+	member := strconv.Itoa(int(articleID))
+	// Using Redis Sorted Set to rank the articles by hot score
+	pipe := initializers.RDB.TxPipeline()
+	for _, window := range TrendingWindows {
+		pipe.ZIncrBy(initializers.RDB_CTX, window.Key(), weight, member)
+	}
+	if _, err := pipe.Exec(initializers.RDB_CTX); err != nil {
+		initializers.LOGGER.ErrorContext(ctx, "Failed to record the hot event", "error", err.Error(), "article_id", articleID)
+	}
+}
+
+// DecayTrending decays the hot scores by the time elapsed since the last decay,
+// then drops the cold articles and trims each set to its maximum size.
+func DecayTrending(elapsed time.Duration) {
+	for _, window := range TrendingWindows {
+		key := window.Key()
+		factor := math.Pow(0.5, elapsed.Hours()/window.HalfLife.Hours())
+		pipe := initializers.RDB.TxPipeline()
+		// ZUNIONSTORE <key> 1 <key> WEIGHTS <factor>
+		pipe.ZUnionStore(initializers.RDB_CTX, key, &redis.ZStore{Keys: []string{key}, Weights: []float64{factor}})
+		pipe.ZRemRangeByScore(initializers.RDB_CTX, key, "-inf", "("+strconv.FormatFloat(minHotScore, 'f', -1, 64))
+		pipe.ZRemRangeByRank(initializers.RDB_CTX, key, 0, -maxTrending-1)
+		if _, err := pipe.Exec(initializers.RDB_CTX); err != nil {
+			initializers.LOGGER.Error("Failed to decay the trending set", "error", err.Error(), "window", window.Name)
+		}
+	}
+}
+
+// GetTrending returns the IDs of the hottest articles of a trending set with their scores, up to count.
+func GetTrending(window TrendingWindow, count int) ([]uint, map[uint]float64, error) {
+	members, err := initializers.RDB.ZRevRangeWithScores(initializers.RDB_CTX, window.Key(), 0, int64(count)-1).Result()
+	if err != nil {
+		return nil, nil, err
+	}
+	ids := make([]uint, 0, len(members))
+	scores := make(map[uint]float64, len(members))
+	for _, member := range members {
+		id := StrToUint(member.Member.(string))
+		ids = append(ids, id)
+		scores[id] = member.Score
+	}
+	return ids, scores, nil
+}
@@ -0,0 +1,108 @@
+package utils
+
+import (
+	"auth/initializers"
+	"auth/models"
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm/clause"
+)
+
+// ArticleViews is the readership of an article on a day.
+type ArticleViews struct {
+	Views   int64
+	Readers int64
+}
+
+// viewKey returns the key of a view counter of an article on a day.
+func viewKey(prefix string, articleID uint, day time.Time) string {
+	return prefix + strconv.Itoa(int(articleID)) + ":" + day.Format("20060102")
+}
+
+// RecordArticleView records a view of an article by a reader.
+// Only the first view of the reader on the day heats up the article (see RecordHotEvent).
+func RecordArticleView(ctx context.Context, articleID uint, readerID uint) {
+	now := time.Now()
+	pvKey := viewKey(RedisConstants.VIEWS_PV_KEY_PREFIX, articleID, now)
+	uvKey := viewKey(RedisConstants.VIEWS_UV_KEY_PREFIX, articleID, now)
+
+	// Using Redis HyperLogLog to count the unique readers, and a plain counter for the views
+	pipe := initializers.RDB.TxPipeline()
+	added := pipe.PFAdd(initializers.RDB_CTX, uvKey, strconv.Itoa(int(readerID)))
+	pipe.Incr(initializers.RDB_CTX, pvKey)
+	pipe.ExpireNX(initializers.RDB_CTX, uvKey, RedisConstants.VIEWS_EXPIRE_TIME)
+	pipe.ExpireNX(initializers.RDB_CTX, pvKey, RedisConstants.VIEWS_EXPIRE_TIME)
+	// Remember the counters to be flushed to MySQL
+	pipe.SAdd(initializers.RDB_CTX, RedisConstants.VIEWS_DIRTY_KEY, strconv.Itoa(int(articleID))+":"+now.Format("20060102"))
+	if _, err := pipe.Exec(initializers.RDB_CTX); err != nil {
+		initializers.LOGGER.ErrorContext(ctx, "Failed to record the article view", "error", err.Error(), "article_id", articleID)
+		return
+	}
+	if added.Val() == 1 {
+		RecordHotEvent(ctx, articleID, HotView)
+	}
+}
+
+// GetLiveArticleViews returns the readership of the articles on a day off the Redis counters, which may not be flushed yet.
+func GetLiveArticleViews(articleIDs []uint, day time.Time) map[uint]ArticleViews {
+	views := make(map[uint]ArticleViews, len(articleIDs))
+	if len(articleIDs) == 0 {
+		return views
+	}
+	pipe := initializers.RDB.Pipeline()
+	pvs := make([]*redis.StringCmd, len(articleIDs))
+	uvs := make([]*redis.IntCmd, len(articleIDs))
+	for i, articleID := range articleIDs {
+		pvs[i] = pipe.Get(initializers.RDB_CTX, viewKey(RedisConstants.VIEWS_PV_KEY_PREFIX, articleID, day))
+		uvs[i] = pipe.PFCount(initializers.RDB_CTX, viewKey(RedisConstants.VIEWS_UV_KEY_PREFIX, articleID, day))
+	}
+	pipe.Exec(initializers.RDB_CTX)
+	for i, articleID := range articleIDs {
+		if pv, err := pvs[i].Int64(); err == nil {
+			views[articleID] = ArticleViews{Views: pv, Readers: uvs[i].Val()}
+		}
+	}
+	return views
+}
+
+// FlushArticleViews writes the Redis view counters to MySQL.
+// The counters of the past days are final, so they are forgotten once flushed.
+func FlushArticleViews() {
+	members, err := initializers.RDB.SMembers(initializers.RDB_CTX, RedisConstants.VIEWS_DIRTY_KEY).Result()
+	if err != nil {
+		return
+	}
+
+	year, month, day := time.Now().Date()
+	today := time.Date(year, month, day, 0, 0, 0, 0, time.Local)
+	for _, member := range members {
+		id, date, ok := strings.Cut(member, ":")
+		articleID := StrToUint(id)
+		day, err := time.ParseInLocation("20060102", date, time.Local)
+		if !ok || articleID == 0 || err != nil {
+			initializers.RDB.SRem(initializers.RDB_CTX, RedisConstants.VIEWS_DIRTY_KEY, member)
+			continue
+		}
+
+		// The counters may have expired if they have not been flushed for too long
+		views, ok := GetLiveArticleViews([]uint{articleID}, day)[articleID]
+		if ok {
+			stat := models.ArticleViewStat{ArticleID: articleID, Day: day, Views: views.Views, Readers: views.Readers}
+			result := initializers.DB.Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "article_id"}, {Name: "day"}},
+				DoUpdates: clause.AssignmentColumns([]string{"views", "readers", "updated_at"}),
+			}).Create(&stat)
+			if result.Error != nil {
+				initializers.LOGGER.Error("Failed to flush the article views", "error", result.Error.Error(), "article_id", articleID, "day", date)
+				continue
+			}
+		}
+		if day.Before(today) {
+			initializers.RDB.SRem(initializers.RDB_CTX, RedisConstants.VIEWS_DIRTY_KEY, member)
+		}
+	}
+}